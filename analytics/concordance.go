@@ -0,0 +1,92 @@
+package analytics
+
+import (
+	"unicode/utf8"
+)
+
+// Concordance sort orders
+const (
+	SortPosition = "position"
+	SortLeft     = "left"
+	SortRight    = "right"
+)
+
+// Line is one occurrence of a term with the words around it. Start and End are the
+// offsets of the match in the sentence, in characters, End excluded.
+type Line struct {
+	Left  string
+	Match string
+	Right string
+	Start int
+	End   int
+	// leftWords and rightWords hold the lower case context, nearest word first, for sorting
+	leftWords  []string
+	rightWords []string
+}
+
+// Concordance finds every occurrence of the words of query, as a phrase, in a sentence
+// and keeps up to window words on each side
+func Concordance(sentence string, query []string, window int) []Line {
+	words := tokens(sentence)
+	lines := []Line{}
+	for i := 0; i+len(query) <= len(words) && len(query) > 0; i++ {
+		matched := true
+		for j, word := range query {
+			if words[i+j].word != word {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		first, last := words[i], words[i+len(query)-1]
+		line := Line{
+			Match: sentence[first.start:last.end],
+			Start: utf8.RuneCountInString(sentence[:first.start]),
+			End:   utf8.RuneCountInString(sentence[:last.end]),
+		}
+
+		from := i - window
+		if from < 0 {
+			from = 0
+		}
+		if from < i {
+			line.Left = sentence[words[from].start:words[i-1].end]
+		}
+		for k := i - 1; k >= from; k-- {
+			line.leftWords = append(line.leftWords, words[k].word)
+		}
+
+		to := i + len(query) + window
+		if to > len(words) {
+			to = len(words)
+		}
+		if i+len(query) < to {
+			line.Right = sentence[words[i+len(query)].start:words[to-1].end]
+		}
+		for k := i + len(query); k < to; k++ {
+			line.rightWords = append(line.rightWords, words[k].word)
+		}
+
+		lines = append(lines, line)
+		i += len(query) - 1
+	}
+	return lines
+}
+
+// LessContext reports whether line a sorts before line b by their left context, read from the
+// match outwards, or by their right context. Lines with equal contexts are not less than each other.
+func LessContext(a, b Line, order string) bool {
+	x, y := a.rightWords, b.rightWords
+	if order == SortLeft {
+		x, y = a.leftWords, b.leftWords
+	}
+	for k := 0; k < len(x) && k < len(y); k++ {
+		if x[k] != y[k] {
+			return x[k] < y[k]
+		}
+	}
+	return len(x) < len(y)
+}
@@ -0,0 +1,100 @@
+package analytics
+
+import (
+	"sort"
+	"testing"
+)
+
+// TestConcordance checks the windows around the matches and their offsets in characters
+func TestConcordance(t *testing.T) {
+	tests := []struct {
+		name     string
+		sentence string
+		query    []string
+		window   int
+		want     []Line
+	}{
+		{
+			"window",
+			"one two three four five six seven",
+			[]string{"four"},
+			2,
+			[]Line{{Left: "two three", Match: "four", Right: "five six", Start: 14, End: 18}},
+		},
+		{
+			"sentence edges",
+			"Alpha beta, gamma.",
+			[]string{"alpha"},
+			3,
+			[]Line{{Left: "", Match: "Alpha", Right: "beta, gamma", Start: 0, End: 5}},
+		},
+		{
+			"phrase",
+			"the big red dog saw the big cat",
+			[]string{"the", "big"},
+			1,
+			[]Line{
+				{Left: "", Match: "the big", Right: "red", Start: 0, End: 7},
+				{Left: "saw", Match: "the big", Right: "cat", Start: 20, End: 27},
+			},
+		},
+		{
+			"no window",
+			"a cat sat",
+			[]string{"cat"},
+			0,
+			[]Line{{Match: "cat", Start: 2, End: 5}},
+		},
+		{
+			"arabic offsets",
+			"قرأ الطالبُ الكتاب",
+			[]string{"الكتاب"},
+			1,
+			[]Line{{Left: "الطالبُ", Match: "الكتاب", Start: 12, End: 18}},
+		},
+		{"no match", "nothing here", []string{"cat"}, 2, []Line{}},
+		{"empty query", "a cat", nil, 2, []Line{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines := Concordance(test.sentence, test.query, test.window)
+			if len(lines) != len(test.want) {
+				t.Fatalf("got %d lines, want %d", len(lines), len(test.want))
+			}
+			for i, line := range lines {
+				want := test.want[i]
+				if line.Left != want.Left || line.Match != want.Match || line.Right != want.Right ||
+					line.Start != want.Start || line.End != want.End {
+					t.Errorf("line %d: got %+v, want %+v", i, line, want)
+				}
+			}
+		})
+	}
+}
+
+// TestLessContext sorts the lines by their left context read outwards and by their right context
+func TestLessContext(t *testing.T) {
+	var lines []Line
+	for _, sentence := range []string{"b a cat z", "a b cat y", "c cat x"} {
+		lines = append(lines, Concordance(sentence, []string{"cat"}, 2)...)
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{SortLeft, []string{"b a", "a b", "c"}},
+		{SortRight, []string{"c", "a b", "b a"}},
+	}
+	for _, test := range tests {
+		t.Run(test.order, func(t *testing.T) {
+			sorted := append([]Line{}, lines...)
+			sort.SliceStable(sorted, func(i, j int) bool { return LessContext(sorted[i], sorted[j], test.order) })
+			for i, line := range sorted {
+				if line.Left != test.want[i] {
+					t.Errorf("line %d: got %q, want %q", i, line.Left, test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package analytics
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// EmbeddingSize is the number of dimensions of the hashed embeddings
+const EmbeddingSize = 256
+
+// bigramWeight is the weight of two adjacent content words relative to a single word
+const bigramWeight = 0.5
+
+// Embedding is an L2 normalized vector, the dot product of two embeddings is their cosine similarity
+type Embedding []float32
+
+// hashFeature adds weight to the dimension a feature hashes to, with a sign taken from
+// the hash so that collisions cancel out on average
+func hashFeature(vector []float64, feature string, weight float64) {
+	h := fnv.New32a()
+	h.Write([]byte(feature))
+	sum := h.Sum32()
+	if sum&(1<<31) != 0 {
+		weight = -weight
+	}
+	vector[sum%EmbeddingSize] += weight
+}
+
+// Embed computes the hashed embedding of texts from their content words and adjacent word pairs.
+// Counts are dampened logarithmically so long texts are not dominated by their most repeated words.
+func Embed(texts []string, language string) Embedding {
+	counts := map[string]float64{}
+	for _, text := range texts {
+		words := contentWords(text, language)
+		for i, word := range words {
+			counts[word]++
+			if i > 0 {
+				counts[words[i-1]+" "+word] += bigramWeight
+			}
+		}
+	}
+
+	vector := make([]float64, EmbeddingSize)
+	for feature, count := range counts {
+		hashFeature(vector, feature, 1+math.Log(count))
+	}
+
+	norm := 0.0
+	for _, value := range vector {
+		norm += value * value
+	}
+	embedding := make(Embedding, EmbeddingSize)
+	if norm == 0 {
+		return embedding
+	}
+	norm = math.Sqrt(norm)
+	for i, value := range vector {
+		embedding[i] = float32(value / norm)
+	}
+	return embedding
+}
+
+// Similarity is the cosine similarity of two embeddings
+func (e Embedding) Similarity(other Embedding) float64 {
+	dot := float32(0)
+	for i := range e {
+		dot += e[i] * other[i]
+	}
+	return float64(dot)
+}
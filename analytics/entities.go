@@ -0,0 +1,41 @@
+package analytics
+
+import (
+	"sync"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// Entity is a named entity found by prose, such as a PERSON or a GPE (countries, cities, states)
+type Entity struct {
+	Text  string
+	Label string
+}
+
+var (
+	entityModel     *prose.Model
+	entityModelOnce sync.Once
+)
+
+// model loads the tagger and entity extractor of prose once, NewDocument would load them for every text
+func model() *prose.Model {
+	entityModelOnce.Do(func() {
+		doc, _ := prose.NewDocument("", prose.WithSegmentation(false))
+		entityModel = doc.Model
+	})
+	return entityModel
+}
+
+// Entities extracts the named entities of a sentence
+func Entities(sentence string) ([]Entity, error) {
+	doc, err := prose.NewDocument(sentence, prose.WithSegmentation(false), prose.UsingModel(model()))
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]Entity, 0, len(doc.Entities()))
+	for _, entity := range doc.Entities() {
+		entities = append(entities, Entity{Text: entity.Text, Label: entity.Label})
+	}
+	return entities, nil
+}
@@ -0,0 +1,110 @@
+package analytics
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// TermFrequency is the number of occurrences of a term and its share of all the counted terms
+type TermFrequency struct {
+	Term  string
+	Count int
+	Share float64
+}
+
+// FrequencyOptions selects which terms are counted
+type FrequencyOptions struct {
+	// N is the number of terms returned
+	N int
+	// NGram is the number of words per term, 1 to 3
+	NGram int
+	// Language is the ISO 639-1 code of the stopword list
+	Language string
+	// MinLength is the minimum number of letters of every word of a term
+	MinLength int
+}
+
+// CountTerms counts the n-grams of every text. N-grams never span two texts, contain a word
+// shorter than minLength, or start or end with a stopword.
+func CountTerms(texts []string, options FrequencyOptions) (map[string]int, int) {
+	counts := map[string]int{}
+	total := 0
+	for _, text := range texts {
+		words := Tokenize(text)
+		for i := 0; i+options.NGram <= len(words); i++ {
+			gram := words[i : i+options.NGram]
+			if IsStopword(gram[0], options.Language) || IsStopword(gram[len(gram)-1], options.Language) {
+				continue
+			}
+
+			short := false
+			for _, word := range gram {
+				if utf8.RuneCountInString(word) < options.MinLength {
+					short = true
+					break
+				}
+			}
+			if short {
+				continue
+			}
+
+			counts[strings.Join(gram, " ")]++
+			total++
+		}
+	}
+	return counts, total
+}
+
+// TopTerms returns the options.N most frequent n-grams of the texts, most frequent first
+func TopTerms(texts []string, options FrequencyOptions) []TermFrequency {
+	counts, total := CountTerms(texts, options)
+	return Top(counts, total, options.N)
+}
+
+// Top selects the n highest counts with a bounded min-heap, ties broken alphabetically
+func Top(counts map[string]int, total int, n int) []TermFrequency {
+	h := &termHeap{}
+	for term, count := range counts {
+		entry := TermFrequency{Term: term, Count: count}
+		if h.Len() < n {
+			heap.Push(h, entry)
+		} else if h.Len() > 0 && h.less(h.items[0], entry) {
+			h.items[0] = entry
+			heap.Fix(h, 0)
+		}
+	}
+
+	result := h.items
+	sort.Slice(result, func(i, j int) bool { return h.less(result[j], result[i]) })
+	for i := range result {
+		if total > 0 {
+			result[i].Share = float64(result[i].Count) / float64(total)
+		}
+	}
+	return result
+}
+
+// termHeap keeps the lowest ranked term at its root
+type termHeap struct {
+	items []TermFrequency
+}
+
+// less reports whether a ranks below b
+func (h *termHeap) less(a, b TermFrequency) bool {
+	if a.Count != b.Count {
+		return a.Count < b.Count
+	}
+	return a.Term > b.Term
+}
+
+func (h *termHeap) Len() int           { return len(h.items) }
+func (h *termHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *termHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *termHeap) Push(x interface{}) { h.items = append(h.items, x.(TermFrequency)) }
+func (h *termHeap) Pop() interface{} {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
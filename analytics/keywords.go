@@ -0,0 +1,145 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// Weighting names a way of scoring the terms of a document against the corpus
+type Weighting string
+
+const (
+	WeightingTFIDF Weighting = "tfidf"
+	WeightingBM25  Weighting = "bm25"
+)
+
+// BM25 parameters, the usual defaults
+const (
+	BM25K1 = 1.2
+	BM25B  = 0.75
+)
+
+// CorpusStats describes the indexed documents as a whole
+type CorpusStats struct {
+	Documents     int
+	AverageLength float64
+	// AverageSentenceLength is used to score single sentences with BM25
+	AverageSentenceLength float64
+}
+
+// TermStats is the number of occurrences of a term in a document and the number of documents containing it
+type TermStats struct {
+	Term string
+	// Surface is the most frequent spelling of the stemmed term in the document
+	Surface       string
+	Count         int
+	DocumentCount int
+}
+
+// Keyword is a term of a document with its score against the corpus
+type Keyword struct {
+	TermStats
+	Score float64
+}
+
+// KeywordOptions selects which terms are scored by Keywords
+type KeywordOptions struct {
+	N         int
+	Language  string
+	MinLength int
+	Weighting Weighting
+}
+
+// CountWords counts the stems of the words of the texts, stopwords included, and returns the
+// most frequent spelling of every stem and the total
+func CountWords(texts []string, language string) (map[string]int, map[string]string, int) {
+	counts := map[string]int{}
+	spellings := map[string]map[string]int{}
+	total := 0
+	for _, text := range texts {
+		for _, word := range Tokenize(text) {
+			stem := Stem(word, language)
+			counts[stem]++
+			if spellings[stem] == nil {
+				spellings[stem] = map[string]int{}
+			}
+			spellings[stem][word]++
+			total++
+		}
+	}
+
+	surfaces := make(map[string]string, len(spellings))
+	for stem, words := range spellings {
+		best := 0
+		for word, count := range words {
+			if count > best || (count == best && word < surfaces[stem]) {
+				surfaces[stem], best = word, count
+			}
+		}
+	}
+	return counts, surfaces, total
+}
+
+// IDF is the smoothed inverse document frequency used by TF-IDF
+func IDF(documents, documentCount int) float64 {
+	return math.Log(float64(documents+1)/float64(documentCount+1)) + 1
+}
+
+// TFIDF scores a term by its share of the document times its inverse document frequency
+func TFIDF(count, length int, corpus CorpusStats, documentCount int) float64 {
+	if length == 0 {
+		return 0
+	}
+	return float64(count) / float64(length) * IDF(corpus.Documents, documentCount)
+}
+
+// BM25IDF is the inverse document frequency of Okapi BM25, never negative
+func BM25IDF(documents, documentCount int) float64 {
+	return math.Log(1 + (float64(documents-documentCount)+0.5)/(float64(documentCount)+0.5))
+}
+
+// BM25 scores a term of a document of the given length with Okapi BM25
+func BM25(count, length int, corpus CorpusStats, documentCount int) float64 {
+	norm := 1.0
+	if corpus.AverageLength > 0 {
+		norm = 1 - BM25B + BM25B*float64(length)/corpus.AverageLength
+	}
+	tf := float64(count)
+	return BM25IDF(corpus.Documents, documentCount) * tf * (BM25K1 + 1) / (tf + BM25K1*norm)
+}
+
+// Keywords returns the options.N best scoring terms of a document of the given length,
+// skipping stopwords and words shorter than options.MinLength, judged on their surface form
+func Keywords(terms []TermStats, length int, corpus CorpusStats, options KeywordOptions) []Keyword {
+	score := TFIDF
+	if options.Weighting == WeightingBM25 {
+		score = BM25
+	}
+
+	keywords := []Keyword{}
+	for _, term := range terms {
+		surface := term.Surface
+		if surface == "" {
+			surface = term.Term
+		}
+		if utf8.RuneCountInString(surface) < options.MinLength || IsStopword(surface, options.Language) {
+			continue
+		}
+		keywords = append(keywords, Keyword{
+			TermStats: term,
+			Score:     score(term.Count, length, corpus, term.DocumentCount),
+		})
+	}
+
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		return keywords[i].Term < keywords[j].Term
+	})
+	if len(keywords) > options.N {
+		keywords = keywords[:options.N]
+	}
+	return keywords
+}
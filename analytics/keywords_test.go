@@ -0,0 +1,105 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+// TestTFIDF checks the scores against values computed by hand
+func TestTFIDF(t *testing.T) {
+	corpus := CorpusStats{Documents: 9}
+	tests := []struct {
+		name          string
+		count, length int
+		documentCount int
+		want          float64
+	}{
+		{"empty document", 3, 0, 1, 0},
+		{"in every document", 2, 10, 9, 0.2},
+		{"in one document", 2, 10, 1, 0.2 * (math.Log(5) + 1)},
+		{"in no document", 1, 4, 0, 0.25 * (math.Log(10) + 1)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := TFIDF(test.count, test.length, corpus, test.documentCount)
+			if math.Abs(got-test.want) > epsilon {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestBM25 checks the scores against values computed by hand and the saturation of the counts
+func TestBM25(t *testing.T) {
+	corpus := CorpusStats{Documents: 10, AverageLength: 100}
+	idf := math.Log(1 + 9.5/1.5)
+	tests := []struct {
+		name          string
+		corpus        CorpusStats
+		count, length int
+		documentCount int
+		want          float64
+	}{
+		{"average length", corpus, 1, 100, 1, idf * 2.2 / 2.2},
+		{"twice the average length", corpus, 1, 200, 1, idf * 2.2 / (1 + 1.2*1.75)},
+		{"repeated term", corpus, 4, 100, 1, idf * 4 * 2.2 / 5.2},
+		{"no average length", CorpusStats{Documents: 10}, 1, 50, 1, idf * 2.2 / 2.2},
+		{"in every document", corpus, 1, 100, 10, math.Log(1 + 0.5/10.5)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := BM25(test.count, test.length, test.corpus, test.documentCount)
+			if math.Abs(got-test.want) > epsilon {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	// the score grows with the count but never past (k1 + 1) times the idf
+	previous := 0.0
+	for count := 1; count <= 1000; count *= 10 {
+		score := BM25(count, 100, corpus, 1)
+		if score <= previous || score >= idf*(BM25K1+1) {
+			t.Errorf("count %d: score %v after %v, bound %v", count, score, previous, idf*(BM25K1+1))
+		}
+		previous = score
+	}
+}
+
+// TestBM25IDF checks that the inverse document frequency is never negative
+func TestBM25IDF(t *testing.T) {
+	for documentCount := 0; documentCount <= 10; documentCount++ {
+		if idf := BM25IDF(10, documentCount); idf < 0 {
+			t.Errorf("%d of 10 documents: idf %v", documentCount, idf)
+		}
+	}
+}
+
+// TestKeywords checks the ranking, the stopwords, the minimum length and N
+func TestKeywords(t *testing.T) {
+	corpus := CorpusStats{Documents: 10, AverageLength: 50}
+	terms := []TermStats{
+		{Term: "the", Count: 9, DocumentCount: 1},
+		{Term: "ox", Count: 9, DocumentCount: 1},
+		{Term: "common", Surface: "commons", Count: 5, DocumentCount: 10},
+		{Term: "rare", Surface: "rarely", Count: 2, DocumentCount: 1},
+		{Term: "tie", Count: 2, DocumentCount: 1},
+	}
+	for _, weighting := range []Weighting{WeightingTFIDF, WeightingBM25} {
+		t.Run(string(weighting), func(t *testing.T) {
+			keywords := Keywords(terms, 50, corpus, KeywordOptions{N: 2, Language: "en", MinLength: 3, Weighting: weighting})
+			if len(keywords) != 2 {
+				t.Fatalf("got %d keywords, want 2", len(keywords))
+			}
+			// rare and tie score the same, they are ordered by term
+			if keywords[0].Term != "rare" || keywords[1].Term != "tie" {
+				t.Errorf("got %s and %s, want rare and tie", keywords[0].Term, keywords[1].Term)
+			}
+			if keywords[0].Score != keywords[1].Score {
+				t.Errorf("scores %v and %v differ", keywords[0].Score, keywords[1].Score)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package analytics
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"strings"
+)
+
+// MinHash and LSH parameters. With 16 bands of 4 rows two sentences become candidates
+// about half of the time at a Jaccard similarity of 0.5, and almost always above 0.7.
+const (
+	ShingleSize = 3
+	NumHashes   = 64
+	Bands       = 16
+	bandRows    = NumHashes / Bands
+	// minShingles skips sentences too short to tell a copy from a common phrase
+	minShingles = 3
+)
+
+// seeds derives a fixed hash function per signature row so signatures stay comparable across restarts
+var seeds = func() [NumHashes]uint64 {
+	var result [NumHashes]uint64
+	state := uint64(0x9e3779b97f4a7c15)
+	for i := range result {
+		state += 0x9e3779b97f4a7c15
+		result[i] = mix(state)
+	}
+	return result
+}()
+
+// mix is the splitmix64 finalizer
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// Shingles hashes the distinct runs of ShingleSize words of a text. Texts with fewer
+// than minShingles shingles return nil.
+func Shingles(text string) []uint64 {
+	words := Tokenize(text)
+	seen := map[uint64]bool{}
+	shingles := []uint64{}
+	for i := 0; i+ShingleSize <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+ShingleSize], " ")))
+		shingle := h.Sum64()
+		if !seen[shingle] {
+			seen[shingle] = true
+			shingles = append(shingles, shingle)
+		}
+	}
+	if len(shingles) < minShingles {
+		return nil
+	}
+	return shingles
+}
+
+// Signature computes the MinHash signature of a set of shingles
+func Signature(shingles []uint64) [NumHashes]uint64 {
+	var signature [NumHashes]uint64
+	for i := range signature {
+		signature[i] = ^uint64(0)
+	}
+	for _, shingle := range shingles {
+		for i, seed := range seeds {
+			if h := mix(shingle ^ seed); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	return signature
+}
+
+// Buckets hashes every band of a signature, two texts sharing a bucket in the same band are candidates
+func Buckets(signature [NumHashes]uint64) [Bands]int64 {
+	var buckets [Bands]int64
+	buf := make([]byte, 8)
+	for band := range buckets {
+		h := fnv.New64a()
+		for _, value := range signature[band*bandRows : (band+1)*bandRows] {
+			binary.LittleEndian.PutUint64(buf, value)
+			h.Write(buf)
+		}
+		buckets[band] = int64(h.Sum64())
+	}
+	return buckets
+}
+
+// Jaccard is the exact similarity of two sets of shingles
+func Jaccard(a, b []uint64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := make(map[uint64]bool, len(a))
+	for _, shingle := range a {
+		set[shingle] = true
+	}
+	shared := 0
+	for _, shingle := range b {
+		if set[shingle] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
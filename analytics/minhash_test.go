@@ -0,0 +1,145 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+// sequence returns the shingles from start to end excluded
+func sequence(start, end uint64) []uint64 {
+	shingles := []uint64{}
+	for shingle := start; shingle < end; shingle++ {
+		shingles = append(shingles, shingle)
+	}
+	return shingles
+}
+
+// TestShingles checks the short texts, the repeated runs and the case of the words
+func TestShingles(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"too short", "one two three four", 0},
+		{"enough shingles", "one two three four five", 3},
+		{"repeated runs", "one two three one two three one two three", 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shingles := Shingles(test.text)
+			if len(shingles) != test.want {
+				t.Errorf("got %d shingles, want %d", len(shingles), test.want)
+			}
+			if test.want == 0 && shingles != nil {
+				t.Errorf("got %v, want nil", shingles)
+			}
+		})
+	}
+
+	lower, upper := Shingles("the quick brown fox jumps"), Shingles("The Quick Brown Fox Jumps")
+	if Jaccard(lower, upper) != 1 {
+		t.Errorf("the case of the words changes the shingles: %v and %v", lower, upper)
+	}
+}
+
+// TestJaccard checks the exact similarities of small sets
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []uint64
+		want float64
+	}{
+		{"identical", []uint64{1, 2, 3}, []uint64{3, 2, 1}, 1},
+		{"disjoint", []uint64{1, 2}, []uint64{3, 4}, 0},
+		{"half", []uint64{1, 2, 3}, []uint64{2, 3, 4}, 0.5},
+		{"subset", []uint64{1, 2, 3, 4}, []uint64{1}, 0.25},
+		{"empty", nil, []uint64{1}, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Jaccard(test.a, test.b); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// agreement is the share of the rows two signatures agree on, the MinHash estimate of their similarity
+func agreement(a, b [NumHashes]uint64) float64 {
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / NumHashes
+}
+
+// TestSignature checks that the signatures estimate the Jaccard similarity
+func TestSignature(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []uint64
+	}{
+		{"identical", sequence(0, 100), sequence(0, 100)},
+		{"disjoint", sequence(0, 100), sequence(100, 200)},
+		{"a third", sequence(0, 100), sequence(50, 150)},
+		{"most", sequence(0, 100), sequence(10, 110)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want := Jaccard(test.a, test.b)
+			got := agreement(Signature(test.a), Signature(test.b))
+			// 64 rows estimate the similarity within about 0.06, three standard deviations are allowed
+			if math.Abs(got-want) > 0.2 {
+				t.Errorf("estimated %v, want about %v", got, want)
+			}
+		})
+	}
+
+	// the order of the shingles does not matter
+	reversed := sequence(0, 100)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	if Signature(reversed) != Signature(sequence(0, 100)) {
+		t.Error("the signature depends on the order of the shingles")
+	}
+}
+
+// TestBuckets checks that near copies share a bucket and unrelated sets do not
+func TestBuckets(t *testing.T) {
+	shared := func(a, b []uint64) int {
+		count := 0
+		bucketsA, bucketsB := Buckets(Signature(a)), Buckets(Signature(b))
+		for band := range bucketsA {
+			if bucketsA[band] == bucketsB[band] {
+				count++
+			}
+		}
+		return count
+	}
+
+	tests := []struct {
+		name      string
+		a, b      []uint64
+		candidate bool
+	}{
+		{"identical", sequence(0, 100), sequence(0, 100), true},
+		{"near copy", sequence(0, 100), sequence(5, 105), true},
+		{"disjoint", sequence(0, 100), sequence(1000, 1100), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := shared(test.a, test.b) > 0; got != test.candidate {
+				t.Errorf("candidate %v, want %v", got, test.candidate)
+			}
+		})
+	}
+
+	if got := shared(sequence(0, 100), sequence(0, 100)); got != Bands {
+		t.Errorf("identical sets share %d bands, want %d", got, Bands)
+	}
+}
@@ -0,0 +1,142 @@
+package analytics
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// TextRank parameters
+const (
+	damping = 0.85
+	// maxTextRankSentences bounds the quadratic TextRank graph, longer documents are
+	// scored by their similarity to the document centroid instead
+	maxTextRankSentences = 2000
+	maxIterations        = 50
+	convergence          = 1e-4
+)
+
+// contentWords returns the stems of the words of a sentence that are neither stopwords nor single letters
+func contentWords(sentence, language string) []string {
+	words := []string{}
+	for _, word := range Tokenize(sentence) {
+		if utf8.RuneCountInString(word) > 1 && !IsStopword(word, language) {
+			words = append(words, Stem(word, language))
+		}
+	}
+	return words
+}
+
+// SentenceScores scores how central every sentence is to the text they make up, the higher the
+// more representative. Short documents are ranked with TextRank, long ones against their centroid.
+func SentenceScores(sentences []string, language string) []float64 {
+	words := make([][]string, len(sentences))
+	for i, sentence := range sentences {
+		words[i] = contentWords(sentence, language)
+	}
+
+	if len(sentences) > maxTextRankSentences {
+		return centroidScores(words)
+	}
+	return textRank(words)
+}
+
+// textRank ranks the sentences over a graph weighted by the word overlap of every pair,
+// normalized by the sentence lengths as in Mihalcea and Tarau (2004)
+func textRank(words [][]string) []float64 {
+	n := len(words)
+	sets := make([]map[string]bool, n)
+	postings := map[string][]int{}
+	for i, sentence := range words {
+		sets[i] = map[string]bool{}
+		for _, word := range sentence {
+			if !sets[i][word] {
+				sets[i][word] = true
+				postings[word] = append(postings[word], i)
+			}
+		}
+	}
+
+	// only the sentences sharing a word are linked, found through the postings
+	overlaps := make([]map[int]int, n)
+	for i := range overlaps {
+		overlaps[i] = map[int]int{}
+	}
+	for _, posting := range postings {
+		for a := 0; a < len(posting); a++ {
+			for b := a + 1; b < len(posting); b++ {
+				overlaps[posting[a]][posting[b]]++
+				overlaps[posting[b]][posting[a]]++
+			}
+		}
+	}
+
+	weights := make([]map[int]float64, n)
+	totals := make([]float64, n)
+	for i := range weights {
+		weights[i] = map[int]float64{}
+		for j, overlap := range overlaps[i] {
+			norm := math.Log(float64(len(sets[i]))) + math.Log(float64(len(sets[j])))
+			if norm <= 0 {
+				continue
+			}
+			weights[i][j] = float64(overlap) / norm
+			totals[i] += weights[i][j]
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		next := make([]float64, n)
+		delta := 0.0
+		for i := range next {
+			sum := 0.0
+			for j, weight := range weights[i] {
+				if totals[j] > 0 {
+					sum += weight / totals[j] * scores[j]
+				}
+			}
+			next[i] = 1 - damping + damping*sum
+			delta += math.Abs(next[i] - scores[i])
+		}
+		scores = next
+		if delta < convergence {
+			break
+		}
+	}
+	return scores
+}
+
+// centroidScores scores the sentences by the cosine similarity of their words to those of the whole text
+func centroidScores(words [][]string) []float64 {
+	centroid := map[string]float64{}
+	for _, sentence := range words {
+		for _, word := range sentence {
+			centroid[word]++
+		}
+	}
+	centroidNorm := 0.0
+	for _, count := range centroid {
+		centroidNorm += count * count
+	}
+	centroidNorm = math.Sqrt(centroidNorm)
+
+	scores := make([]float64, len(words))
+	for i, sentence := range words {
+		counts := map[string]float64{}
+		for _, word := range sentence {
+			counts[word]++
+		}
+		dot, norm := 0.0, 0.0
+		for word, count := range counts {
+			dot += count * centroid[word]
+			norm += count * count
+		}
+		if norm > 0 && centroidNorm > 0 {
+			scores[i] = dot / (math.Sqrt(norm) * centroidNorm)
+		}
+	}
+	return scores
+}
@@ -0,0 +1,119 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+// best returns the index of the highest score, the first one on ties
+func best(scores []float64) int {
+	index := 0
+	for i, score := range scores {
+		if score > scores[index] {
+			index = i
+		}
+	}
+	return index
+}
+
+// TestTextRank checks the ranks of small graphs whose central sentence is known
+func TestTextRank(t *testing.T) {
+	tests := []struct {
+		name  string
+		words [][]string
+		best  int
+	}{
+		{
+			"hub",
+			[][]string{
+				{"alpha", "beta"},
+				{"alpha", "beta", "gamma", "delta"},
+				{"gamma", "delta"},
+				{"beta", "gamma"},
+			},
+			1,
+		},
+		{
+			"chain",
+			[][]string{
+				{"alpha", "beta", "one"},
+				{"beta", "gamma", "two"},
+				{"gamma", "delta", "three"},
+			},
+			1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scores := textRank(test.words)
+			if len(scores) != len(test.words) {
+				t.Fatalf("got %d scores, want %d", len(scores), len(test.words))
+			}
+			if got := best(scores); got != test.best {
+				t.Errorf("got sentence %d, want %d, scores %v", got, test.best, scores)
+			}
+		})
+	}
+}
+
+// TestTextRankUnlinked checks that the sentences sharing no word keep the base score
+func TestTextRankUnlinked(t *testing.T) {
+	tests := []struct {
+		name  string
+		words [][]string
+	}{
+		{"no sentences", nil},
+		{"disjoint", [][]string{{"alpha", "beta"}, {"gamma", "delta"}}},
+		{"empty sentences", [][]string{{}, {}, {"alpha"}}},
+		// a single word sentence has no log length, it cannot be weighted
+		{"single words", [][]string{{"alpha"}, {"alpha"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i, score := range textRank(test.words) {
+				if math.Abs(score-(1-damping)) > 1e-9 {
+					t.Errorf("sentence %d: got %v, want %v", i, score, 1-damping)
+				}
+			}
+		})
+	}
+}
+
+// TestTextRankSymmetric checks that identical sentences rank the same
+func TestTextRankSymmetric(t *testing.T) {
+	scores := textRank([][]string{
+		{"alpha", "beta", "gamma"},
+		{"alpha", "beta", "gamma"},
+		{"delta", "epsilon"},
+	})
+	if scores[0] != scores[1] {
+		t.Errorf("identical sentences scored %v and %v", scores[0], scores[1])
+	}
+	if scores[2] >= scores[0] {
+		t.Errorf("the unlinked sentence scored %v, above %v", scores[2], scores[0])
+	}
+}
+
+// TestSentenceScores checks the stopwords are left out and the long documents scored by centroid
+func TestSentenceScores(t *testing.T) {
+	scores := SentenceScores([]string{
+		"The cat sat on the mat.",
+		"The dog and the cat played on the mat.",
+		"The cat chased the dog.",
+		"It was a sunny day.",
+	}, "en")
+	if got := best(scores); got != 1 {
+		t.Errorf("got sentence %d, want 1, scores %v", got, scores)
+	}
+
+	sentences := make([]string, maxTextRankSentences+1)
+	for i := range sentences {
+		sentences[i] = "reports on budgets"
+	}
+	sentences[0] = "unrelated words"
+	scores = SentenceScores(sentences, "en")
+	// the unrelated sentence is a negligible part of the centroid
+	if scores[0] > 1e-3 || math.Abs(scores[1]-1) > 1e-3 {
+		t.Errorf("centroid scores %v and %v, want about 0 and 1", scores[0], scores[1])
+	}
+}
@@ -0,0 +1,273 @@
+package analytics
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/abadojack/whatlanggo"
+	"github.com/bbalet/stopwords"
+	"github.com/kljensen/snowball"
+	"github.com/rivo/uniseg"
+)
+
+// DefaultLanguage is assumed when the language of a text cannot be detected
+const DefaultLanguage = "en"
+
+// detectionSample bounds the bytes read by DetectLanguage, enough for a reliable guess
+const detectionSample = 20000
+
+// StopwordLanguages lists the ISO 639-1 codes with a stopword list
+var StopwordLanguages = map[string]bool{
+	"ar": true, "bg": true, "cs": true, "da": true, "de": true, "el": true, "en": true,
+	"es": true, "fa": true, "fi": true, "fr": true, "hu": true, "id": true, "it": true,
+	"ja": true, "km": true, "lv": true, "nl": true, "no": true, "pl": true, "pt": true,
+	"ro": true, "ru": true, "sk": true, "sv": true, "th": true, "tr": true,
+}
+
+// snowballLanguages maps the ISO 639-1 codes to the stemmers of snowball
+var snowballLanguages = map[string]string{
+	"en": "english",
+	"es": "spanish",
+	"fr": "french",
+	"hu": "hungarian",
+	"no": "norwegian",
+	"ru": "russian",
+	"sv": "swedish",
+}
+
+// token is a word of a text and its position, in bytes, end excluded
+type token struct {
+	word       string
+	start, end int
+}
+
+// tokens splits a text on the word boundaries of Unicode (UAX #29) and keeps the
+// segments holding a letter, lower cased and normalized
+func tokens(text string) []token {
+	result := []token{}
+	state := -1
+	offset := 0
+	rest := text
+	for len(rest) > 0 {
+		var word string
+		word, rest, state = uniseg.FirstWordInString(rest, state)
+		if strings.IndexFunc(word, unicode.IsLetter) >= 0 {
+			result = append(result, token{word: Normalize(word), start: offset, end: offset + len(word)})
+		}
+		offset += len(word)
+	}
+	return result
+}
+
+// Tokenize splits a text into lower case, normalized words. Scripts without spaces, such as
+// Chinese, are split into single ideographs as Unicode word segmentation does.
+func Tokenize(text string) []string {
+	spans := tokens(text)
+	words := make([]string, len(spans))
+	for i, span := range spans {
+		words[i] = span.word
+	}
+	return words
+}
+
+// arabicFolds unifies the letters Arabic writers use interchangeably
+var arabicFolds = map[rune]rune{
+	'أ': 'ا', 'إ': 'ا', 'آ': 'ا', 'ٱ': 'ا',
+	'ى': 'ي', 'ئ': 'ي',
+	'ة': 'ه',
+	'ؤ': 'و',
+}
+
+// isArabicMark reports whether r is a diacritic (tashkeel) or the tatweel
+func isArabicMark(r rune) bool {
+	return (r >= '\u064b' && r <= '\u065f') || r == '\u0670' || r == '\u0640'
+}
+
+// Normalize lower cases a word and, for Arabic, removes the diacritics and unifies the
+// alef, ya, ta marbuta and hamza variants
+func Normalize(word string) string {
+	word = strings.ToLower(word)
+	if strings.IndexFunc(word, func(r rune) bool { return unicode.Is(unicode.Arabic, r) }) < 0 {
+		return word
+	}
+
+	var b strings.Builder
+	for _, r := range word {
+		if isArabicMark(r) {
+			continue
+		}
+		if folded, ok := arabicFolds[r]; ok {
+			r = folded
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var stopwordCache sync.Map
+
+// maxStopwordVariants bounds the spellings tried for a normalized Arabic word
+const maxStopwordVariants = 64
+
+// unfolded lists the spellings of the letters unified by Normalize
+var unfolded = map[rune][]rune{
+	'ا': {'ا', 'أ', 'إ', 'آ'},
+	'ي': {'ي', 'ى', 'ئ'},
+	'ه': {'ه', 'ة'},
+	'و': {'و', 'ؤ'},
+}
+
+// spellings returns the words Normalize folds into word, the stopword lists keeping the original spelling
+func spellings(word string) []string {
+	variants := []string{""}
+	for _, r := range word {
+		options, ok := unfolded[r]
+		if !ok || len(variants)*len(options) > maxStopwordVariants {
+			options = []rune{r}
+		}
+		next := make([]string, 0, len(variants)*len(options))
+		for _, variant := range variants {
+			for _, option := range options {
+				next = append(next, variant+string(option))
+			}
+		}
+		variants = next
+	}
+	return variants
+}
+
+// IsStopword reports whether a normalized word is in the stopword list of the language
+func IsStopword(word, language string) bool {
+	key := language + ":" + word
+	if cached, ok := stopwordCache.Load(key); ok {
+		return cached.(bool)
+	}
+
+	candidates := []string{word}
+	if language == "ar" {
+		candidates = spellings(word)
+	}
+	isStopword := false
+	for _, candidate := range candidates {
+		if strings.TrimSpace(stopwords.CleanString(candidate, language, false)) == "" {
+			isStopword = true
+			break
+		}
+	}
+	stopwordCache.Store(key, isStopword)
+	return isStopword
+}
+
+// DetectLanguage guesses the ISO 639-1 code of the language of texts, DefaultLanguage when unsure.
+// Only the first detectionSample bytes are read.
+func DetectLanguage(texts []string) string {
+	var b strings.Builder
+	for _, text := range texts {
+		if b.Len()+len(text) > detectionSample {
+			text = text[:detectionSample-b.Len()]
+			for !utf8.ValidString(text) {
+				text = text[:len(text)-1]
+			}
+		}
+		b.WriteString(text)
+		b.WriteString("\n")
+		if b.Len() >= detectionSample {
+			break
+		}
+	}
+
+	info := whatlanggo.Detect(b.String())
+	code := info.Lang.Iso6391()
+	if code == "" || !info.IsReliable() {
+		return DefaultLanguage
+	}
+	return code
+}
+
+// Arabic light stemming affixes (Larkey et al., light10), in their normalized spelling
+var (
+	arabicArticles = []string{"وال", "بال", "كال", "فال", "لل", "ال"}
+	arabicSuffixes = []string{"ها", "ان", "ات", "ون", "ين", "يه", "ه", "ي"}
+)
+
+// stemArabic removes the conjunction, the definite articles and the common suffixes of a normalized word
+func stemArabic(word string) string {
+	runes := []rune(word)
+	if len(runes) >= 4 && runes[0] == 'و' {
+		runes = runes[1:]
+	}
+	for _, article := range arabicArticles {
+		prefix := []rune(article)
+		if len(runes)-len(prefix) >= 2 && string(runes[:len(prefix)]) == article {
+			runes = runes[len(prefix):]
+			break
+		}
+	}
+	for _, suffix := range arabicSuffixes {
+		end := []rune(suffix)
+		if len(runes)-len(end) >= 2 && string(runes[len(runes)-len(end):]) == suffix {
+			runes = runes[:len(runes)-len(end)]
+		}
+	}
+	return string(runes)
+}
+
+// Stem reduces a normalized word to its stem. Languages without a stemmer keep their words unchanged.
+func Stem(word, language string) string {
+	if language == "ar" {
+		return stemArabic(word)
+	}
+	name, ok := snowballLanguages[language]
+	if !ok {
+		return word
+	}
+	stemmed, err := snowball.Stem(word, name, false)
+	if err != nil {
+		return word
+	}
+	return stemmed
+}
+
+// Terms tokenizes and stems a text, the terms of the corpus index
+func Terms(text, language string) []string {
+	words := Tokenize(text)
+	for i, word := range words {
+		words[i] = Stem(word, language)
+	}
+	return words
+}
+
+// arabicClasses matches every spelling of the letters unified by Normalize
+var arabicClasses = map[rune]string{
+	'ا': "[اأإآٱ]",
+	'ي': "[يىئ]",
+	'ه': "[هة]",
+	'و': "[وؤ]",
+}
+
+// Pattern returns a PostgreSQL regular expression matching the start of the words beginning with
+// prefix, whatever their case, diacritics or letter variants, to be used with the ~* operator
+func Pattern(prefix string) string {
+	var b strings.Builder
+	first, _ := utf8.DecodeRuneInString(prefix)
+	if first < utf8.RuneSelf {
+		// \m relies on the database locale for other scripts, they are matched anywhere instead
+		b.WriteString(`\m`)
+	}
+	for _, r := range prefix {
+		if !unicode.Is(unicode.Arabic, r) {
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			continue
+		}
+		if class, ok := arabicClasses[r]; ok {
+			b.WriteString(class)
+		} else {
+			b.WriteRune(r)
+		}
+		b.WriteString("[\u064b-\u065f\u0670\u0640]*")
+	}
+	return b.String()
+}
@@ -0,0 +1,127 @@
+package analytics
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// TestTokenize checks the word boundaries, the punctuation, the case and the scripts without spaces
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", []string{}},
+		{"punctuation", "Hello, World! (again)", []string{"hello", "world", "again"}},
+		{"numbers", "page 12 of 30", []string{"page", "of"}},
+		{"contraction", "don't stop", []string{"don't", "stop"}},
+		{"arabic", "مرحباً بالعالم", []string{"مرحبا", "بالعالم"}},
+		{"chinese", "中文分词", []string{"中", "文", "分", "词"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Tokenize(test.text); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestNormalize checks the case of latin words and the diacritics and letter variants of Arabic
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+		want string
+	}{
+		{"latin", "Report", "report"},
+		{"accents kept", "Café", "café"},
+		{"diacritics", "كَتَبَ", "كتب"},
+		{"tatweel", "كـتـاب", "كتاب"},
+		{"alef variants", "أحمد إسلام آمن", "احمد اسلام امن"},
+		{"ya and ta marbuta", "مدرسة على", "مدرسه علي"},
+		{"hamza on waw", "مؤمن", "مومن"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Normalize(test.word); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestStem checks the snowball stemmers, the Arabic light stemmer and the languages without a stemmer
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word     string
+		language string
+		want     string
+	}{
+		{"running", "en", "run"},
+		{"documents", "en", "document"},
+		{"maisons", "fr", "maison"},
+		{"running", "de", "running"},
+		{"والكتاب", "ar", "كتاب"},
+		{"المعلمون", "ar", "معلم"},
+		{"كتابات", "ar", "كتاب"},
+		// too short to lose an affix
+		{"وال", "ar", "وال"},
+	}
+	for _, test := range tests {
+		t.Run(test.language+" "+test.word, func(t *testing.T) {
+			if got := Stem(test.word, test.language); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestIsStopword checks the normalized Arabic stopwords against their original spelling
+func TestIsStopword(t *testing.T) {
+	tests := []struct {
+		word     string
+		language string
+		want     bool
+	}{
+		{"the", "en", true},
+		{"report", "en", false},
+		{Normalize("إلى"), "ar", true},
+		{Normalize("على"), "ar", true},
+		{"كتاب", "ar", false},
+	}
+	for _, test := range tests {
+		t.Run(test.language+" "+test.word, func(t *testing.T) {
+			if got := IsStopword(test.word, test.language); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestPattern checks the patterns against the spellings they must match, Go's regexp standing in
+// for the ~* operator of PostgreSQL
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		prefix string
+		text   string
+		want   bool
+	}{
+		{"rep", "the report", true},
+		{"rep", "a preprint", false},
+		{"a.b", "axb", false},
+		{"احمد", "قال أَحمد", true},
+		{"مدرسه", "في المدرسة", true},
+	}
+	for _, test := range tests {
+		t.Run(test.prefix+" "+test.text, func(t *testing.T) {
+			// \m is the start of a word in PostgreSQL, \b is the nearest in Go
+			pattern := regexp.MustCompile(`(?i)` + regexp.MustCompile(`\\m`).ReplaceAllString(Pattern(test.prefix), `\b`))
+			if got := pattern.MatchString(test.text); got != test.want {
+				t.Errorf("%s: got %v, want %v", pattern, got, test.want)
+			}
+		})
+	}
+}
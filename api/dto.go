@@ -0,0 +1,459 @@
+package api
+
+import (
+	"main/database"
+	"time"
+)
+
+// Document is an uploaded file
+type Document struct {
+	ID         int32     `json:"id"`
+	Name       string    `json:"name"`
+	UploadTime time.Time `json:"upload_time"`
+	Pages      int32     `json:"pages"`
+	Size       int64     `json:"size"`
+	// Language is the ISO 639-1 code detected from the text, empty until detected
+	Language string `json:"language"`
+	// Format is the MIME type sniffed from the content of the file
+	Format string `json:"format"`
+	// Extractor read the text of the file at ExtractorVersion, empty and 0 for the documents
+	// ingested before they were recorded
+	Extractor        string `json:"extractor"`
+	ExtractorVersion int32  `json:"extractor_version"`
+}
+
+type DocumentList struct {
+	Documents  []Document `json:"documents"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// Outcomes of the files of a batch upload
+const (
+	BatchCreated = "created"
+	BatchFailed  = "failed"
+	// BatchSkipped files were not ingested, the batch was aborted before them
+	BatchSkipped = "skipped"
+	// BatchRolledBack files were ingested then deleted, the batch was aborted after them
+	BatchRolledBack = "rolled_back"
+)
+
+// BatchItem is the outcome of a file of a batch upload, with its document once created or
+// why it failed
+type BatchItem struct {
+	// File is the name the file was sent with, its path within its archive for the files of an archive
+	File string `json:"file"`
+	// Archive is the name of the archive the file was sent in
+	Archive  string    `json:"archive,omitempty"`
+	Status   string    `json:"status"`
+	Document *Document `json:"document,omitempty"`
+	Error    *Error    `json:"error,omitempty"`
+}
+
+// BatchResult lists the outcome of every file of a batch upload in the order they were sent
+type BatchResult struct {
+	Created int         `json:"created"`
+	Failed  int         `json:"failed"`
+	Aborted bool        `json:"aborted"`
+	Results []BatchItem `json:"results"`
+}
+
+// ReextractItem is the outcome of the re-extraction of a document, with the document as
+// re-extracted or as it was kept and why it failed
+type ReextractItem struct {
+	Document Document `json:"document"`
+	Error    *Error   `json:"error,omitempty"`
+}
+
+// ReextractResult lists the outcome of every document re-extracted, in the order they completed
+type ReextractResult struct {
+	Reextracted int             `json:"reextracted"`
+	Failed      int             `json:"failed"`
+	Results     []ReextractItem `json:"results"`
+}
+
+// Upload is a resumable upload with the bytes received so far. Once received, the file is
+// ingested and its document or why it failed is set.
+type Upload struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Length int64  `json:"length"`
+	Offset int64  `json:"offset"`
+	// Status is receiving, ingesting, ingested or failed
+	Status     string    `json:"status"`
+	DocumentID *int32    `json:"document_id,omitempty"`
+	Error      *Error    `json:"error,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Sentence is a sentence extracted from a document
+type Sentence struct {
+	ID         int32  `json:"id"`
+	DocumentID int32  `json:"document_id"`
+	Page       int32  `json:"page"`
+	Text       string `json:"text"`
+}
+
+// Page tells whether the text of a page was recognized from its image and how confidently, from 0 to 1
+type Page struct {
+	Page       int      `json:"page"`
+	OCR        bool     `json:"ocr"`
+	Confidence *float64 `json:"confidence,omitempty"`
+	PageSize
+}
+
+// Width and Height are the size of the page in points, 0 when unknown
+type PageSize struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type PageList struct {
+	DocumentID int32  `json:"document_id"`
+	Pages      []Page `json:"pages"`
+}
+
+// Box is the bounding box of a paragraph in points, from the top left corner of its page
+type Box struct {
+	X0 float64 `json:"x0"`
+	Y0 float64 `json:"y0"`
+	X1 float64 `json:"x1"`
+	Y1 float64 `json:"y1"`
+}
+
+type Paragraph struct {
+	ID        int32      `json:"id"`
+	Page      int32      `json:"page"`
+	Box       Box        `json:"box"`
+	Sentences []Sentence `json:"sentences"`
+}
+
+// Section is a heading of a document with its paragraphs and subsections. The text before the
+// first heading is in an untitled section of level 0.
+type Section struct {
+	Title      string      `json:"title"`
+	Level      int32       `json:"level"`
+	Page       int32       `json:"page"`
+	Paragraphs []Paragraph `json:"paragraphs"`
+	Sections   []Section   `json:"sections"`
+}
+
+type Structure struct {
+	DocumentID int32     `json:"document_id"`
+	Sections   []Section `json:"sections"`
+}
+
+// Table is a table found in a document, with its bounding box in points
+type Table struct {
+	ID          int32 `json:"id"`
+	DocumentID  int32 `json:"document_id"`
+	Page        int32 `json:"page"`
+	Box         Box   `json:"box"`
+	RowCount    int32 `json:"row_count"`
+	ColumnCount int32 `json:"column_count"`
+}
+
+type TableList struct {
+	DocumentID int32   `json:"document_id"`
+	Tables     []Table `json:"tables"`
+}
+
+// TableData is a table with the text of its cells, row by row
+type TableData struct {
+	Table
+	Rows [][]string `json:"rows"`
+}
+
+// Metadata is the info dictionary of a document completed by its XMP metadata
+type Metadata struct {
+	DocumentID int32      `json:"document_id"`
+	Title      string     `json:"title"`
+	Author     string     `json:"author"`
+	Subject    string     `json:"subject"`
+	Keywords   string     `json:"keywords"`
+	Creator    string     `json:"creator"`
+	Producer   string     `json:"producer"`
+	Created    *time.Time `json:"created,omitempty"`
+	Modified   *time.Time `json:"modified,omitempty"`
+	PDFVersion string     `json:"pdf_version"`
+	Encrypted  bool       `json:"encrypted"`
+}
+
+// OutlineItem is a bookmark of a document, Image links to the image of its page
+type OutlineItem struct {
+	Title    string        `json:"title"`
+	Page     int           `json:"page"`
+	Image    string        `json:"image"`
+	Children []OutlineItem `json:"children"`
+}
+
+type Outline struct {
+	DocumentID int32         `json:"document_id"`
+	Items      []OutlineItem `json:"items"`
+}
+
+type SentenceList struct {
+	Sentences  []Sentence `json:"sentences"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// ScoredSentence is a matching sentence with its BM25 score
+type ScoredSentence struct {
+	Sentence
+	Score float64 `json:"score"`
+}
+
+// SearchHit groups the best matching sentences of one document. When the results are
+// grouped by sentence every hit holds a single sentence and shares its score.
+type SearchHit struct {
+	Document  Document         `json:"document"`
+	Score     float64          `json:"score"`
+	Sentences []ScoredSentence `json:"sentences"`
+}
+
+// SearchResults lists the hits, best first. Total counts every matching sentence.
+type SearchResults struct {
+	Query   string      `json:"query"`
+	Group   string      `json:"group"`
+	Total   int         `json:"total"`
+	Results []SearchHit `json:"results"`
+}
+
+// Occurrences is the number of times a term appears in a document
+type Occurrences struct {
+	DocumentID int32      `json:"document_id"`
+	Term       string     `json:"term"`
+	Count      int        `json:"count"`
+	Sentences  []Sentence `json:"sentences"`
+}
+
+// TermFrequency is the number of occurrences of a term and its share of all the counted terms
+type TermFrequency struct {
+	Term  string  `json:"term"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// Frequencies lists the most occurring terms of a document
+type Frequencies struct {
+	DocumentID int32           `json:"document_id"`
+	NGram      int             `json:"ngram"`
+	Language   string          `json:"language"`
+	PageFrom   *int32          `json:"page_from,omitempty"`
+	PageTo     *int32          `json:"page_to,omitempty"`
+	Terms      []TermFrequency `json:"terms"`
+}
+
+// Keyword is a term of a document scored against the whole corpus. Term is the most frequent
+// spelling of the words sharing the stem.
+type Keyword struct {
+	Term              string  `json:"term"`
+	Stem              string  `json:"stem"`
+	Count             int     `json:"count"`
+	DocumentFrequency int     `json:"document_frequency"`
+	Score             float64 `json:"score"`
+}
+
+// Keywords lists the most distinctive terms of a document
+type Keywords struct {
+	DocumentID int32     `json:"document_id"`
+	Weighting  string    `json:"weighting"`
+	Language   string    `json:"language"`
+	Documents  int       `json:"documents"`
+	Terms      []Keyword `json:"terms"`
+}
+
+// EntityCount is a named entity and the number of its mentions
+type EntityCount struct {
+	Text     string `json:"text"`
+	Label    string `json:"label"`
+	Mentions int    `json:"mentions"`
+}
+
+// DocumentEntities lists the named entities of a document, most mentioned first
+type DocumentEntities struct {
+	DocumentID int32         `json:"document_id"`
+	Entities   []EntityCount `json:"entities"`
+}
+
+// EntityDocument is a document mentioning an entity
+type EntityDocument struct {
+	Document Document `json:"document"`
+	Mentions int      `json:"mentions"`
+}
+
+// EntityDocuments lists the documents mentioning an entity
+type EntityDocuments struct {
+	Text      string           `json:"text"`
+	Label     string           `json:"label,omitempty"`
+	Documents []EntityDocument `json:"documents"`
+}
+
+// CoOccurrences lists the entities mentioned in the same sentences as an entity.
+// Their mentions are the number of shared sentences.
+type CoOccurrences struct {
+	Text       string        `json:"text"`
+	Label      string        `json:"label,omitempty"`
+	DocumentID *int32        `json:"document_id,omitempty"`
+	Entities   []EntityCount `json:"entities"`
+}
+
+// Summary lists the most central sentences of a document in their original order
+type Summary struct {
+	DocumentID int32            `json:"document_id"`
+	Total      int              `json:"total"`
+	Sentences  []ScoredSentence `json:"sentences"`
+}
+
+// ConcordanceLine is an occurrence of a term with the words around it.
+// Start and End are the character offsets of the match in the sentence, End excluded.
+type ConcordanceLine struct {
+	SentenceID int32  `json:"sentence_id"`
+	Page       int32  `json:"page"`
+	Left       string `json:"left"`
+	Match      string `json:"match"`
+	Right      string `json:"right"`
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+}
+
+// Concordance lists one page of the occurrences of a term in a document with their context,
+// Count being the number of occurrences in the whole document
+type Concordance struct {
+	DocumentID int32             `json:"document_id"`
+	Query      string            `json:"query"`
+	Window     int               `json:"window"`
+	Sort       string            `json:"sort"`
+	Count      int               `json:"count"`
+	Lines      []ConcordanceLine `json:"lines"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// SimilarDocument is a document and its similarity, from 0 to 1, to the one looked up
+type SimilarDocument struct {
+	Document Document `json:"document"`
+	Score    float64  `json:"score"`
+}
+
+// SimilarDocuments lists the documents most similar to a document, most similar first
+type SimilarDocuments struct {
+	DocumentID int32             `json:"document_id"`
+	Documents  []SimilarDocument `json:"documents"`
+}
+
+// SimilarSentences lists the sentences most similar to a sentence, most similar first
+type SimilarSentences struct {
+	SentenceID int32            `json:"sentence_id"`
+	Sentences  []ScoredSentence `json:"sentences"`
+}
+
+// DuplicatePair is a sentence of the document and a sentence of another document sharing
+// most of their words. Similarity is the Jaccard similarity of their word trigrams.
+type DuplicatePair struct {
+	Sentence   Sentence `json:"sentence"`
+	Other      Sentence `json:"other"`
+	Similarity float64  `json:"similarity"`
+}
+
+// DuplicateDocument is a document sharing passages with the one looked up. Overlap is the
+// percentage of the sentences of the looked up document found in this one.
+type DuplicateDocument struct {
+	Document Document        `json:"document"`
+	Overlap  float64         `json:"overlap"`
+	Pairs    []DuplicatePair `json:"pairs"`
+}
+
+// Duplicates lists the documents sharing passages with a document, most overlapping first
+type Duplicates struct {
+	DocumentID int32               `json:"document_id"`
+	Threshold  float64             `json:"threshold"`
+	Documents  []DuplicateDocument `json:"documents"`
+}
+
+func NewDocument(record database.Record) Document {
+	return Document{
+		ID:               record.ID,
+		Name:             record.Name,
+		UploadTime:       record.UploadTime,
+		Pages:            record.Numofpages,
+		Size:             record.Size,
+		Language:         record.Language,
+		Format:           record.Mimetype,
+		Extractor:        record.Extractor,
+		ExtractorVersion: record.Extractorversion,
+	}
+}
+
+func NewDocuments(records []database.Record) []Document {
+	documents := make([]Document, 0, len(records))
+	for _, record := range records {
+		documents = append(documents, NewDocument(record))
+	}
+	return documents
+}
+
+func NewUpload(upload database.Upload) Upload {
+	result := Upload{
+		ID:        upload.ID,
+		Name:      upload.Name,
+		Length:    upload.Length,
+		Offset:    upload.Received,
+		Status:    upload.Status,
+		ExpiresAt: upload.Expiresat,
+	}
+	if upload.Pdfid.Valid {
+		result.DocumentID = &upload.Pdfid.Int32
+	}
+	if upload.Errorcode != "" {
+		result.Error = &Error{Code: upload.Errorcode, Message: upload.Errormessage}
+	}
+	return result
+}
+
+func NewMetadata(metadata database.DocumentMetadatum) Metadata {
+	result := Metadata{
+		DocumentID: metadata.Pdfid,
+		Title:      metadata.Title,
+		Author:     metadata.Author,
+		Subject:    metadata.Subject,
+		Keywords:   metadata.Keywords,
+		Creator:    metadata.Creator,
+		Producer:   metadata.Producer,
+		PDFVersion: metadata.Pdfversion,
+		Encrypted:  metadata.Encrypted,
+	}
+	if metadata.Created.Valid {
+		result.Created = &metadata.Created.Time
+	}
+	if metadata.Modified.Valid {
+		result.Modified = &metadata.Modified.Time
+	}
+	return result
+}
+
+func NewTable(table database.DocumentTable) Table {
+	return Table{
+		ID:          table.ID,
+		DocumentID:  table.Pdfid,
+		Page:        table.Page,
+		Box:         Box{X0: table.X0, Y0: table.Y0, X1: table.X1, Y1: table.Y1},
+		RowCount:    table.Numofrows,
+		ColumnCount: table.Numofcolumns,
+	}
+}
+
+func NewSentence(sentence database.Sentence) Sentence {
+	return Sentence{
+		ID:         sentence.ID,
+		DocumentID: sentence.Pdfid,
+		Page:       sentence.Page,
+		Text:       sentence.Sentence,
+	}
+}
+
+func NewSentences(sentences []database.Sentence) []Sentence {
+	result := make([]Sentence, 0, len(sentences))
+	for _, sentence := range sentences {
+		result = append(result, NewSentence(sentence))
+	}
+	return result
+}
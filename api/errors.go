@@ -0,0 +1,83 @@
+package api
+
+// Machine-readable error codes returned in the error envelope
+const (
+	CodeBadRequest          = "bad_request"
+	CodeUnauthorized        = "unauthorized"
+	CodeNotFound            = "not_found"
+	CodeMethodNotAllowed    = "method_not_allowed"
+	CodeInternal            = "internal_error"
+	CodeInvalidID           = "invalid_id"
+	CodeInvalidPage         = "invalid_page"
+	CodeInvalidKeyword      = "invalid_keyword"
+	CodeInvalidInput        = "invalid_input"
+	CodeInvalidCursor       = "invalid_cursor"
+	CodeDocumentNotFound    = "document_not_found"
+	CodeDocumentNotIndexed  = "document_not_indexed"
+	CodePageNotFound        = "page_not_found"
+	CodeUploadMissingFile   = "upload_missing_file"
+	CodeUploadOpenFailed    = "upload_open_failed"
+	CodeUploadSaveFailed    = "upload_save_failed"
+	CodeStorageUpload       = "storage_upload_failed"
+	CodeStorageDelete       = "storage_delete_failed"
+	CodePDFCopyFailed       = "pdf_copy_failed"
+	CodePDFExtractFailed    = "pdf_extract_failed"
+	CodePDFEncrypted        = "pdf_encrypted"
+	CodePDFUnreadable       = "pdf_unreadable"
+	CodePageRenderFailed    = "page_render_failed"
+	CodeRecordCreate        = "record_create_failed"
+	CodeRecordGet           = "record_get_failed"
+	CodeRecordList          = "record_list_failed"
+	CodeRecordDelete        = "record_delete_failed"
+	CodeSentenceList        = "sentence_list_failed"
+	CodeSentenceStore       = "sentence_store_failed"
+	CodeCorpusStats         = "corpus_stats_failed"
+	CodeDuplicatesFailed    = "duplicates_failed"
+	CodeSentenceNotFound    = "sentence_not_found"
+	CodeSimilarityFailed    = "similarity_failed"
+	CodeSummaryFailed       = "summary_failed"
+	CodeEntityList          = "entity_list_failed"
+	CodeEntityLanguage      = "entity_language_unsupported"
+	CodePageList            = "page_list_failed"
+	CodeStructureNotFound   = "structure_not_found"
+	CodeStructureFailed     = "structure_failed"
+	CodeTableNotFound       = "table_not_found"
+	CodeTableList           = "table_list_failed"
+	CodeMetadataNotFound    = "metadata_not_found"
+	CodeMetadataFailed      = "metadata_failed"
+	CodeUnsupportedFormat   = "unsupported_format"
+	CodeDocumentNotPDF      = "document_not_pdf"
+	CodeUploadEmpty         = "upload_empty"
+	CodeUploadTooLarge      = "upload_too_large"
+	CodeUploadTooManyPages  = "upload_too_many_pages"
+	CodeUploadTypeMismatch  = "upload_type_mismatch"
+	CodePDFInvalid          = "pdf_invalid"
+	CodePDFJavaScript       = "pdf_javascript"
+	CodeBatchTooManyFiles   = "batch_too_many_files"
+	CodeBatchTooLarge       = "batch_too_large"
+	CodeBatchAborted        = "batch_aborted"
+	CodeArchiveInvalid      = "archive_invalid"
+	CodeUploadNotFound      = "upload_not_found"
+	CodeUploadGet           = "upload_get_failed"
+	CodeUploadCreate        = "upload_create_failed"
+	CodeUploadDelete        = "upload_delete_failed"
+	CodeUploadOffset        = "upload_offset_mismatch"
+	CodeUploadLocked        = "upload_locked"
+	CodeUploadLength        = "upload_length_exceeded"
+	CodeUploadChunkTooSmall = "upload_chunk_too_small"
+	CodeUploadChecksum      = "upload_checksum_mismatch"
+	CodeUploadInterrupted   = "upload_interrupted"
+	CodeTusVersion          = "tus_version_unsupported"
+)
+
+// Error describes why a request failed
+type Error struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ErrorEnvelope is the body of every failed /api/v1 response
+type ErrorEnvelope struct {
+	Error Error `json:"error"`
+}
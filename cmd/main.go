@@ -1,18 +1,135 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"main/extract"
+	"main/handlers"
+	"main/logging"
+	"main/metrics"
 	migrations "main/migration"
+	"main/ocr"
+	"main/storage"
+	"main/tracing"
+	"main/utils"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 func main() {
+	log := logging.Base
+	err := logging.Configure(os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"))
+	if err != nil {
+		log.WithError(err).Fatal("invalid logging configuration")
+	}
+
+	ctx := context.Background()
+	shutdown, err := tracing.Setup(ctx, os.Getenv("OTEL_TRACES_EXPORTER"), os.Getenv("OTEL_TRACES_FILE"))
+	if err != nil {
+		log.WithError(err).Fatal("failed to set up tracing")
+	}
+	defer func() {
+		if err := shutdown(ctx); err != nil {
+			log.WithError(err).Error("failed to flush traces")
+		}
+	}()
+
 	urlExample := fmt.Sprintf("postgres://%s:%s@db:5432", os.Getenv("DB_USER"), os.Getenv("DB_NAME"))
 
-	err := migrations.Migrate(urlExample, migrations.LogModeError)
+	err = migrations.Migrate(urlExample, migrations.LogModeError, log)
+	if err != nil {
+		log.WithError(err).Error("failed to run migrations")
+	}
+
+	pool, err := pgxpool.Connect(ctx, urlExample)
+	if err != nil {
+		log.WithError(err).Fatal("failed to connect to the database")
+	}
+	defer pool.Close()
+	metrics.RegisterPool(pool)
+	utils.SetDB(tracing.InstrumentDB(pool))
+
+	store, err := storage.NewMinio("minio:9000", "minioadmin", "minioadmin", false)
 	if err != nil {
-		fmt.Println(err)
+		log.WithError(err).Fatal("failed to create the minio client")
+	}
+	utils.SetStore(tracing.InstrumentStore(metrics.InstrumentStore(store)))
+
+	// PDF_EXTRACTORS lists the text extractors to try in order, such as fitz,native,pdftotext.
+	// layout,fitz,native also stores the sections and paragraphs of the pdfs.
+	var extractors []string
+	if names := os.Getenv("PDF_EXTRACTORS"); names != "" {
+		extractors = strings.Split(names, ",")
 	}
+	extractor, err := extract.New(extractors...)
+	if err != nil {
+		log.WithError(err).Fatal("invalid text extraction configuration")
+	}
+	utils.SetExtractor(extractor)
+
+	// UPLOAD_MAX_SIZE is in bytes, the policies of the encrypted pdfs and of those carrying
+	// JavaScript are allow, warn or reject
+	policy := utils.DefaultUploadPolicy
+	if size, err := strconv.ParseInt(os.Getenv("UPLOAD_MAX_SIZE"), 10, 64); err == nil {
+		policy.MaxSize = size
+	}
+	if pages, err := strconv.Atoi(os.Getenv("UPLOAD_MAX_PAGES")); err == nil {
+		policy.MaxPages = pages
+	}
+	if value := os.Getenv("UPLOAD_ENCRYPTED_POLICY"); value != "" {
+		policy.Encrypted = value
+	}
+	if value := os.Getenv("UPLOAD_JAVASCRIPT_POLICY"); value != "" {
+		policy.JavaScript = value
+	}
+	// a batch holds up to UPLOAD_MAX_BATCH_FILES files and UPLOAD_MAX_BATCH_SIZE bytes, BATCH_WORKERS
+	// of them ingested at once, as many as the resumable uploads received
+	if size, err := strconv.ParseInt(os.Getenv("UPLOAD_MAX_BATCH_SIZE"), 10, 64); err == nil {
+		policy.MaxBatchSize = size
+	}
+	if files, err := strconv.Atoi(os.Getenv("UPLOAD_MAX_BATCH_FILES")); err == nil {
+		policy.MaxBatchFiles = files
+	}
+	if workers, err := strconv.Atoi(os.Getenv("BATCH_WORKERS")); err == nil {
+		policy.BatchWorkers = workers
+	}
+	if err := utils.SetUploadPolicy(policy); err != nil {
+		log.WithError(err).Fatal("invalid upload configuration")
+	}
+
+	// OCR_ENGINE=none disables the OCR of scanned pages
+	if os.Getenv("OCR_ENGINE") != "none" {
+		minLetters, _ := strconv.Atoi(os.Getenv("OCR_MIN_PAGE_LETTERS"))
+		engine, err := ocr.NewTesseract(os.Getenv("OCR_LANGUAGES"))
+		if err != nil {
+			log.WithError(err).Warn("scanned pages will not be recognized")
+		} else {
+			utils.SetOCR(engine, minLetters)
+		}
+	}
+
+	// "reextract" extracts the stored documents again instead of serving, see reextract for its flags
+	if len(os.Args) > 1 && os.Args[1] == "reextract" {
+		if err := reextract(ctx, os.Args[2:]); err != nil {
+			log.WithError(err).Fatal("failed to re-extract the documents")
+		}
+		return
+	}
+
+	// the uploads ingested by the previous process lost their files with it, they are failed
+	// before any upload is received
+	handlers.FailInterruptedUploads(ctx)
+	go func() {
+		if err := utils.IndexPending(ctx); err != nil {
+			log.WithError(err).Error("failed to index the pending records")
+		}
+	}()
+	go utils.RunDuplicateIndexer(ctx, time.Minute)
+	go utils.RunUploadSweeper(ctx, time.Hour)
 
 	start()
 }
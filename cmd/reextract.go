@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"main/database"
+	"main/logging"
+	"main/utils"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// reextract runs the reextract command: the stored files of the documents are extracted again,
+// the document of -id alone, those matching the filters otherwise and the whole corpus with -all.
+// It fails when a document could not be re-extracted, the others are kept re-extracted.
+func reextract(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("reextract", flag.ContinueOnError)
+	id := flags.Int("id", 0, "id of the document to re-extract")
+	all := flags.Bool("all", false, "re-extract every document")
+	outdated := flags.Bool("outdated", false, "only the documents extracted with an older version")
+	namePrefix := flags.String("name-prefix", "", "only the documents whose name starts with this prefix")
+	uploadedAfter := flags.String("uploaded-after", "", "only the documents uploaded from this YYYY-MM-DD date")
+	uploadedBefore := flags.String("uploaded-before", "", "only the documents uploaded before this YYYY-MM-DD date")
+	workers := flags.Int("workers", utils.GetUploadPolicy().BatchWorkers, "number of documents re-extracted at once")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	log := logging.FromContext(ctx)
+
+	if *id > 0 {
+		record, err := utils.Reextract(logging.WithField(ctx, "record_id", *id), int32(*id))
+		if err != nil {
+			return err
+		}
+		log.WithField("record_id", record.ID).WithField("extractor", record.Extractor).Info("document re-extracted")
+		return nil
+	}
+
+	filter := utils.RecordFilter{Outdated: *outdated, NamePrefix: *namePrefix}
+	var err error
+	if filter.UploadedAfter, err = parseDate(*uploadedAfter); err != nil {
+		return err
+	}
+	if filter.UploadedBefore, err = parseDate(*uploadedBefore); err != nil {
+		return err
+	}
+	if !*all && !*outdated && filter.NamePrefix == "" && filter.UploadedAfter == nil && filter.UploadedBefore == nil {
+		return errors.New("name a document with -id, filter the documents or pass -all to re-extract every document")
+	}
+
+	started := time.Now()
+	reextracted, failed := 0, 0
+	var mu sync.Mutex
+	err = utils.ReextractRecords(ctx, filter, *workers, func(record database.Record, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		entry := log.WithField("record_id", record.ID)
+		if err != nil {
+			failed++
+			entry.WithError(err).Error("failed to re-extract the document")
+			return
+		}
+		reextracted++
+		entry.WithField("extractor", record.Extractor).Info("document re-extracted")
+	})
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(logrus.Fields{
+		"reextracted": reextracted,
+		"failed":      failed,
+		"duration_ms": time.Since(started).Milliseconds(),
+	}).Info("documents re-extracted")
+	if failed > 0 {
+		return fmt.Errorf("%d documents could not be re-extracted", failed)
+	}
+	return nil
+}
+
+// parseDate parses a YYYY-MM-DD date flag, nil when it is not set
+func parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, dates are YYYY-MM-DD", value)
+	}
+	return &date, nil
+}
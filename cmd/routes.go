@@ -2,34 +2,117 @@ package main
 
 import (
 	"main/handlers"
+	"main/logging"
+	"main/metrics"
+	"main/openapi"
+	"main/tracing"
+	"main/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// multipartOverhead is the room left in the body of the uploads for their form fields and headers
+const multipartOverhead = 1 << 20
+
 func start() {
-	app := fiber.New()
+	err := newApp().Listen(":3000")
+	if err != nil {
+		logging.Base.WithError(err).Fatal("server stopped")
+	}
+}
+
+// newApp registers the middlewares and routes, every route documented in openapi.Endpoints
+func newApp() *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: handlers.ErrorHandler,
+		// the size of the files is checked by the upload policy, the body also holds the multipart headers
+		BodyLimit: int(utils.GetUploadPolicy().MaxBodySize()) + multipartOverhead,
+	})
+
+	app.Use(tracing.Middleware())
+
+	app.Use(logging.Middleware())
+
+	app.Use(metrics.Middleware())
 
 	app.Get("/", handlers.Home)
 
+	app.Get("/openapi.json", openapi.Handler(openapi.Build(openapi.Endpoints)))
+
+	app.Get("/docs", openapi.DocsHandler())
+
 	app.Use(handlers.BasicAuthMiddleware("username", "password"))
 
-	app.Post("/uploadPDF", handlers.SaveFile)
+	// the metrics name the routes and the volume of the documents, they are scraped with the credentials
+	app.Get("/metrics", metrics.Handler())
+
+	v1 := app.Group("/api/v1")
+
+	v1.Get("/documents", handlers.ListDocuments)
+
+	v1.Post("/documents", handlers.CreateDocument)
+
+	v1.Post("/documents/batch", handlers.CreateDocuments)
+
+	v1.Post("/documents/reextract", handlers.ReextractDocuments)
+
+	v1.Get("/documents/:id", handlers.GetDocument)
+
+	v1.Delete("/documents/:id", handlers.DeleteDocument)
+
+	v1.Post("/documents/:id/reextract", handlers.ReextractDocument)
+
+	v1.Get("/documents/:id/file", handlers.DownloadDocument)
+
+	v1.Get("/documents/:id/sentences", handlers.ListDocumentSentences)
+
+	v1.Get("/documents/:id/pages", handlers.ListDocumentPages)
+	v1.Get("/documents/:id/structure", handlers.GetDocumentStructure)
+	v1.Get("/documents/:id/tables", handlers.ListDocumentTables)
+	v1.Get("/documents/:id/metadata", handlers.GetDocumentMetadata)
+	v1.Get("/documents/:id/outline", handlers.GetDocumentOutline)
+	v1.Get("/documents/:id/tables/:table", handlers.GetDocumentTable)
+	v1.Get("/documents/:id/pages/:n", handlers.GetDocumentPage)
+
+	v1.Get("/documents/:id/occurrences", handlers.GetDocumentOccurrences)
+
+	v1.Get("/documents/:id/concordance", handlers.GetDocumentConcordance)
+	v1.Get("/documents/:id/frequencies", handlers.GetDocumentFrequencies)
+	v1.Get("/documents/:id/keywords", handlers.GetDocumentKeywords)
+	v1.Get("/documents/:id/entities", handlers.GetDocumentEntities)
+	v1.Get("/documents/:id/summary", handlers.GetDocumentSummary)
+	v1.Get("/documents/:id/similar", handlers.GetSimilarDocuments)
+	v1.Get("/documents/:id/duplicates", handlers.GetDocumentDuplicates)
+	v1.Get("/sentences/:id/similar", handlers.GetSimilarSentences)
+	v1.Get("/entities/documents", handlers.ListEntityDocuments)
+	v1.Get("/entities/cooccurrences", handlers.GetEntityCoOccurrences)
+
+	v1.Get("/search", handlers.Search)
+
+	v1.Options("/uploads", handlers.UploadOptions)
+	v1.Post("/uploads", handlers.CreateUpload)
+	v1.Get("/uploads/:id", handlers.GetUpload)
+	v1.Patch("/uploads/:id", handlers.WriteUpload)
+	v1.Delete("/uploads/:id", handlers.DeleteUpload)
+
+	// Deprecated routes, kept for existing clients
+	app.Post("/uploadPDF", handlers.Deprecated("/api/v1/documents"), handlers.SaveFile)
 
-	app.Get("/listPDF", handlers.ListFiles)
+	app.Get("/listPDF", handlers.Deprecated("/api/v1/documents"), handlers.ListFiles)
 
-	app.Get("/searchKeyword/:key", handlers.SearchKeyword)
+	app.Get("/searchKeyword/:key", handlers.Deprecated("/api/v1/search"), handlers.SearchKeyword)
 
-	app.Get("/getPDF/:id", handlers.GetPDF)
+	app.Get("/getPDF/:id", handlers.Deprecated("/api/v1/documents/:id/file"), handlers.GetPDF)
 
-	app.Get("/listSentences/:id", handlers.ListSentences)
+	app.Get("/listSentences/:id", handlers.Deprecated("/api/v1/documents/:id/sentences"), handlers.ListSentences)
 
-	app.Get("/getOccurrence/:id/:key", handlers.GetOccurrence)
+	app.Get("/getOccurrence/:id/:key", handlers.Deprecated("/api/v1/documents/:id/occurrences"), handlers.GetOccurrence)
 
-	app.Get("/getMostOccurring/:id", handlers.GetMostOccurring)
+	app.Get("/getMostOccurring/:id", handlers.Deprecated("/api/v1/documents/:id/frequencies"), handlers.GetMostOccurring)
 
-	app.Get("/getPDF/:id/:page", handlers.GetPdfPage)
+	app.Get("/getPDF/:id/:page", handlers.Deprecated("/api/v1/documents/:id/pages/:n"), handlers.GetPdfPage)
 
-	app.Delete("/deletePDF", handlers.DeleteFile)
+	app.Delete("/deletePDF", handlers.Deprecated("/api/v1/documents/:id"), handlers.DeleteFile)
 
-	app.Listen(":3000")
+	return app
 }
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"main/openapi"
+	"testing"
+)
+
+// TestRoutesDocumented fails when a registered route is missing from the OpenAPI specification
+// or a documented route is not registered
+func TestRoutesDocumented(t *testing.T) {
+	if err := openapi.Validate(newApp().GetRoutes(true), openapi.Endpoints); err != nil {
+		t.Fatal(err)
+	}
+}
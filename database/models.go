@@ -5,19 +5,165 @@
 package database
 
 import (
+	"database/sql"
 	"time"
 )
 
+type DocumentMetadatum struct {
+	Pdfid      int32        `json:"pdfid"`
+	Title      string       `json:"title"`
+	Author     string       `json:"author"`
+	Subject    string       `json:"subject"`
+	Keywords   string       `json:"keywords"`
+	Creator    string       `json:"creator"`
+	Producer   string       `json:"producer"`
+	Created    sql.NullTime `json:"created"`
+	Modified   sql.NullTime `json:"modified"`
+	Pdfversion string       `json:"pdfversion"`
+	Encrypted  bool         `json:"encrypted"`
+}
+
+type DocumentStat struct {
+	Pdfid     int32 `json:"pdfid"`
+	TermCount int32 `json:"term_count"`
+}
+
+type DocumentTable struct {
+	ID           int32   `json:"id"`
+	Pdfid        int32   `json:"pdfid"`
+	Page         int32   `json:"page"`
+	X0           float64 `json:"x0"`
+	Y0           float64 `json:"y0"`
+	X1           float64 `json:"x1"`
+	Y1           float64 `json:"y1"`
+	Numofrows    int32   `json:"numofrows"`
+	Numofcolumns int32   `json:"numofcolumns"`
+}
+
+type DocumentTerm struct {
+	Pdfid   int32  `json:"pdfid"`
+	Term    string `json:"term"`
+	Count   int32  `json:"count"`
+	Surface string `json:"surface"`
+}
+
+type DuplicateIndex struct {
+	Pdfid     int32     `json:"pdfid"`
+	IndexedAt time.Time `json:"indexed_at"`
+}
+
+type Entity struct {
+	ID         int32  `json:"id"`
+	Sentenceid int32  `json:"sentenceid"`
+	Pdfid      int32  `json:"pdfid"`
+	Text       string `json:"text"`
+	Label      string `json:"label"`
+}
+
+type OutlineItem struct {
+	ID       int32         `json:"id"`
+	Pdfid    int32         `json:"pdfid"`
+	Parentid sql.NullInt32 `json:"parentid"`
+	Title    string        `json:"title"`
+	Page     int32         `json:"page"`
+}
+
+type Page struct {
+	Pdfid      int32   `json:"pdfid"`
+	Page       int32   `json:"page"`
+	Ocr        bool    `json:"ocr"`
+	Confidence float64 `json:"confidence"`
+	Width      float64 `json:"width"`
+	Height     float64 `json:"height"`
+}
+
+type Paragraph struct {
+	ID        int32   `json:"id"`
+	Pdfid     int32   `json:"pdfid"`
+	Sectionid int32   `json:"sectionid"`
+	Page      int32   `json:"page"`
+	X0        float64 `json:"x0"`
+	Y0        float64 `json:"y0"`
+	X1        float64 `json:"x1"`
+	Y1        float64 `json:"y1"`
+}
+
 type Record struct {
-	ID         int32     `json:"id"`
-	Name       string    `json:"name"`
-	UploadTime time.Time `json:"upload_time"`
-	Numofpages int32     `json:"numofpages"`
-	Size       int32     `json:"size"`
+	ID               int32     `json:"id"`
+	Name             string    `json:"name"`
+	UploadTime       time.Time `json:"upload_time"`
+	Numofpages       int32     `json:"numofpages"`
+	Size             int64     `json:"size"`
+	Language         string    `json:"language"`
+	Mimetype         string    `json:"mimetype"`
+	Objectkey        string    `json:"objectkey"`
+	Extractor        string    `json:"extractor"`
+	Extractorversion int32     `json:"extractorversion"`
+}
+
+type Section struct {
+	ID       int32         `json:"id"`
+	Pdfid    int32         `json:"pdfid"`
+	Parentid sql.NullInt32 `json:"parentid"`
+	Level    int32         `json:"level"`
+	Title    string        `json:"title"`
+	Page     int32         `json:"page"`
 }
 
 type Sentence struct {
-	ID       int32  `json:"id"`
-	Sentence string `json:"sentence"`
-	Pdfid    int32  `json:"pdfid"`
+	ID          int32         `json:"id"`
+	Sentence    string        `json:"sentence"`
+	Pdfid       int32         `json:"pdfid"`
+	Page        int32         `json:"page"`
+	Paragraphid sql.NullInt32 `json:"paragraphid"`
+}
+
+type SentenceBand struct {
+	Band       int32 `json:"band"`
+	Bucket     int64 `json:"bucket"`
+	Sentenceid int32 `json:"sentenceid"`
+	Pdfid      int32 `json:"pdfid"`
+}
+
+type SummaryScore struct {
+	Sentenceid int32   `json:"sentenceid"`
+	Pdfid      int32   `json:"pdfid"`
+	Score      float64 `json:"score"`
+}
+
+type TableCell struct {
+	Tableid     int32  `json:"tableid"`
+	Rowindex    int32  `json:"rowindex"`
+	Columnindex int32  `json:"columnindex"`
+	Text        string `json:"text"`
+}
+
+type TermStat struct {
+	Term          string `json:"term"`
+	DocumentCount int32  `json:"document_count"`
+}
+
+type Upload struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Contenttype  string        `json:"contenttype"`
+	Length       int64         `json:"length"`
+	Received     int64         `json:"received"`
+	Parts        int32         `json:"parts"`
+	Multipartid  string        `json:"multipartid"`
+	Checksum     string        `json:"checksum"`
+	Status       string        `json:"status"`
+	Pdfid        sql.NullInt32 `json:"pdfid"`
+	Errorcode    string        `json:"errorcode"`
+	Errormessage string        `json:"errormessage"`
+	Createdat    time.Time     `json:"createdat"`
+	Expiresat    time.Time     `json:"expiresat"`
+	Hashstate    []byte        `json:"hashstate"`
+}
+
+type UploadPart struct {
+	Uploadid string `json:"uploadid"`
+	Number   int32  `json:"number"`
+	Etag     string `json:"etag"`
+	Size     int64  `json:"size"`
 }
@@ -7,29 +7,272 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const addUploadPart = `-- name: AddUploadPart :execrows
+WITH advanced AS (
+  UPDATE uploads
+    set received = received + $1::bigint, parts = parts + 1, hashState = $2
+  WHERE id = $3 AND received = $4::bigint AND status = 'receiving'
+  RETURNING id, parts
+)
+INSERT INTO upload_parts (uploadId, number, etag, size)
+SELECT id, parts, $5, $1::bigint FROM advanced
+`
+
+type AddUploadPartParams struct {
+	Size      int64  `json:"size"`
+	Hashstate []byte `json:"hashstate"`
+	ID        string `json:"id"`
+	Received  int64  `json:"received"`
+	Etag      string `json:"etag"`
+}
+
+func (q *Queries) AddUploadPart(ctx context.Context, arg AddUploadPartParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addUploadPart,
+		arg.Size,
+		arg.Hashstate,
+		arg.ID,
+		arg.Received,
+		arg.Etag,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countObjectRecords = `-- name: CountObjectRecords :one
+SELECT count(*) FROM records
+WHERE objectKey = $1
+`
+
+func (q *Queries) CountObjectRecords(ctx context.Context, objectkey string) (int64, error) {
+	row := q.db.QueryRow(ctx, countObjectRecords, objectkey)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSummaryScores = `-- name: CountSummaryScores :one
+SELECT count(*) FROM summary_scores
+WHERE pdfId = $1
+`
+
+func (q *Queries) CountSummaryScores(ctx context.Context, pdfid int32) (int64, error) {
+	row := q.db.QueryRow(ctx, countSummaryScores, pdfid)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createEntities = `-- name: CreateEntities :exec
+INSERT INTO entities (sentenceId, pdfId, text, label)
+SELECT unnest($1::int[]), $2, unnest($3::text[]), unnest($4::text[])
+`
+
+type CreateEntitiesParams struct {
+	SentenceIds []int32  `json:"sentence_ids"`
+	Pdfid       int32    `json:"pdfid"`
+	Texts       []string `json:"texts"`
+	Labels      []string `json:"labels"`
+}
+
+func (q *Queries) CreateEntities(ctx context.Context, arg CreateEntitiesParams) error {
+	_, err := q.db.Exec(ctx, createEntities,
+		arg.SentenceIds,
+		arg.Pdfid,
+		arg.Texts,
+		arg.Labels,
+	)
+	return err
+}
+
+const createMetadata = `-- name: CreateMetadata :exec
+INSERT INTO document_metadata (
+  pdfId, title, author, subject, keywords, creator, producer, created, modified, pdfVersion, encrypted
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7,
+  $8, $9, $10, $11
+)
+`
+
+type CreateMetadataParams struct {
+	Pdfid      int32        `json:"pdfid"`
+	Title      string       `json:"title"`
+	Author     string       `json:"author"`
+	Subject    string       `json:"subject"`
+	Keywords   string       `json:"keywords"`
+	Creator    string       `json:"creator"`
+	Producer   string       `json:"producer"`
+	Created    sql.NullTime `json:"created"`
+	Modified   sql.NullTime `json:"modified"`
+	Pdfversion string       `json:"pdfversion"`
+	Encrypted  bool         `json:"encrypted"`
+}
+
+func (q *Queries) CreateMetadata(ctx context.Context, arg CreateMetadataParams) error {
+	_, err := q.db.Exec(ctx, createMetadata,
+		arg.Pdfid,
+		arg.Title,
+		arg.Author,
+		arg.Subject,
+		arg.Keywords,
+		arg.Creator,
+		arg.Producer,
+		arg.Created,
+		arg.Modified,
+		arg.Pdfversion,
+		arg.Encrypted,
+	)
+	return err
+}
+
+const createOutlineItem = `-- name: CreateOutlineItem :one
+INSERT INTO outline_items (pdfId, parentId, title, page)
+VALUES ($1, $2, $3, $4)
+RETURNING id, pdfid, parentid, title, page
+`
+
+type CreateOutlineItemParams struct {
+	Pdfid    int32         `json:"pdfid"`
+	Parentid sql.NullInt32 `json:"parentid"`
+	Title    string        `json:"title"`
+	Page     int32         `json:"page"`
+}
+
+func (q *Queries) CreateOutlineItem(ctx context.Context, arg CreateOutlineItemParams) (OutlineItem, error) {
+	row := q.db.QueryRow(ctx, createOutlineItem,
+		arg.Pdfid,
+		arg.Parentid,
+		arg.Title,
+		arg.Page,
+	)
+	var i OutlineItem
+	err := row.Scan(
+		&i.ID,
+		&i.Pdfid,
+		&i.Parentid,
+		&i.Title,
+		&i.Page,
+	)
+	return i, err
+}
+
+const createPages = `-- name: CreatePages :exec
+INSERT INTO pages (pdfId, page, ocr, confidence, width, height)
+SELECT $1, unnest($2::int[]), unnest($3::bool[]), unnest($4::float8[]),
+  unnest($5::float8[]), unnest($6::float8[])
+`
+
+type CreatePagesParams struct {
+	Pdfid       int32     `json:"pdfid"`
+	Pages       []int32   `json:"pages"`
+	Ocr         []bool    `json:"ocr"`
+	Confidences []float64 `json:"confidences"`
+	Widths      []float64 `json:"widths"`
+	Heights     []float64 `json:"heights"`
+}
+
+func (q *Queries) CreatePages(ctx context.Context, arg CreatePagesParams) error {
+	_, err := q.db.Exec(ctx, createPages,
+		arg.Pdfid,
+		arg.Pages,
+		arg.Ocr,
+		arg.Confidences,
+		arg.Widths,
+		arg.Heights,
+	)
+	return err
+}
+
+const createParagraph = `-- name: CreateParagraph :one
+INSERT INTO paragraphs (pdfId, sectionId, page, x0, y0, x1, y1)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, pdfid, sectionid, page, x0, y0, x1, y1
+`
+
+type CreateParagraphParams struct {
+	Pdfid     int32   `json:"pdfid"`
+	Sectionid int32   `json:"sectionid"`
+	Page      int32   `json:"page"`
+	X0        float64 `json:"x0"`
+	Y0        float64 `json:"y0"`
+	X1        float64 `json:"x1"`
+	Y1        float64 `json:"y1"`
+}
+
+func (q *Queries) CreateParagraph(ctx context.Context, arg CreateParagraphParams) (Paragraph, error) {
+	row := q.db.QueryRow(ctx, createParagraph,
+		arg.Pdfid,
+		arg.Sectionid,
+		arg.Page,
+		arg.X0,
+		arg.Y0,
+		arg.X1,
+		arg.Y1,
+	)
+	var i Paragraph
+	err := row.Scan(
+		&i.ID,
+		&i.Pdfid,
+		&i.Sectionid,
+		&i.Page,
+		&i.X0,
+		&i.Y0,
+		&i.X1,
+		&i.Y1,
+	)
+	return i, err
+}
+
 const createRecord = `-- name: CreateRecord :one
 INSERT INTO records (
   name,
   numOfPages,
-  size
+  size,
+  language,
+  mimeType,
+  objectKey,
+  extractor,
+  extractorVersion
 ) VALUES (
   $1,
   $2,
-  $3
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8
 )
-RETURNING id, name, upload_time, numofpages, size
+RETURNING id, name, upload_time, numofpages, size, language, mimetype, objectkey, extractor, extractorversion
 `
 
 type CreateRecordParams struct {
-	Name       string `json:"name"`
-	Numofpages int32  `json:"numofpages"`
-	Size       int32  `json:"size"`
+	Name             string `json:"name"`
+	Numofpages       int32  `json:"numofpages"`
+	Size             int64  `json:"size"`
+	Language         string `json:"language"`
+	Mimetype         string `json:"mimetype"`
+	Objectkey        string `json:"objectkey"`
+	Extractor        string `json:"extractor"`
+	Extractorversion int32  `json:"extractorversion"`
 }
 
 func (q *Queries) CreateRecord(ctx context.Context, arg CreateRecordParams) (Record, error) {
-	row := q.db.QueryRow(ctx, createRecord, arg.Name, arg.Numofpages, arg.Size)
+	row := q.db.QueryRow(ctx, createRecord,
+		arg.Name,
+		arg.Numofpages,
+		arg.Size,
+		arg.Language,
+		arg.Mimetype,
+		arg.Objectkey,
+		arg.Extractor,
+		arg.Extractorversion,
+	)
 	var i Record
 	err := row.Scan(
 		&i.ID,
@@ -37,6 +280,45 @@ func (q *Queries) CreateRecord(ctx context.Context, arg CreateRecordParams) (Rec
 		&i.UploadTime,
 		&i.Numofpages,
 		&i.Size,
+		&i.Language,
+		&i.Mimetype,
+		&i.Objectkey,
+		&i.Extractor,
+		&i.Extractorversion,
+	)
+	return i, err
+}
+
+const createSection = `-- name: CreateSection :one
+INSERT INTO sections (pdfId, parentId, level, title, page)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, pdfid, parentid, level, title, page
+`
+
+type CreateSectionParams struct {
+	Pdfid    int32         `json:"pdfid"`
+	Parentid sql.NullInt32 `json:"parentid"`
+	Level    int32         `json:"level"`
+	Title    string        `json:"title"`
+	Page     int32         `json:"page"`
+}
+
+func (q *Queries) CreateSection(ctx context.Context, arg CreateSectionParams) (Section, error) {
+	row := q.db.QueryRow(ctx, createSection,
+		arg.Pdfid,
+		arg.Parentid,
+		arg.Level,
+		arg.Title,
+		arg.Page,
+	)
+	var i Section
+	err := row.Scan(
+		&i.ID,
+		&i.Pdfid,
+		&i.Parentid,
+		&i.Level,
+		&i.Title,
+		&i.Page,
 	)
 	return i, err
 }
@@ -44,23 +326,197 @@ func (q *Queries) CreateRecord(ctx context.Context, arg CreateRecordParams) (Rec
 const createSentence = `-- name: CreateSentence :one
 INSERT INTO sentences (
   sentence,
-  pdfId
+  pdfId,
+  page,
+  paragraphId
 ) VALUES (
   $1,
-  $2
+  $2,
+  $3,
+  $4
   )
-  RETURNING id, sentence, pdfid
+  RETURNING id, sentence, pdfid, page, paragraphid
 `
 
 type CreateSentenceParams struct {
-	Sentence string `json:"sentence"`
-	Pdfid    int32  `json:"pdfid"`
+	Sentence    string        `json:"sentence"`
+	Pdfid       int32         `json:"pdfid"`
+	Page        int32         `json:"page"`
+	Paragraphid sql.NullInt32 `json:"paragraphid"`
 }
 
 func (q *Queries) CreateSentence(ctx context.Context, arg CreateSentenceParams) (Sentence, error) {
-	row := q.db.QueryRow(ctx, createSentence, arg.Sentence, arg.Pdfid)
+	row := q.db.QueryRow(ctx, createSentence,
+		arg.Sentence,
+		arg.Pdfid,
+		arg.Page,
+		arg.Paragraphid,
+	)
 	var i Sentence
-	err := row.Scan(&i.ID, &i.Sentence, &i.Pdfid)
+	err := row.Scan(
+		&i.ID,
+		&i.Sentence,
+		&i.Pdfid,
+		&i.Page,
+		&i.Paragraphid,
+	)
+	return i, err
+}
+
+const createSentenceBands = `-- name: CreateSentenceBands :exec
+WITH marked AS (
+  INSERT INTO duplicate_index (pdfId)
+  VALUES ($1)
+  ON CONFLICT (pdfId) DO NOTHING
+)
+INSERT INTO sentence_bands (band, bucket, sentenceId, pdfId)
+SELECT unnest($2::int[]), unnest($3::bigint[]), unnest($4::int[]), $1
+ON CONFLICT DO NOTHING
+`
+
+type CreateSentenceBandsParams struct {
+	Pdfid       int32   `json:"pdfid"`
+	Bands       []int32 `json:"bands"`
+	Buckets     []int64 `json:"buckets"`
+	SentenceIds []int32 `json:"sentence_ids"`
+}
+
+func (q *Queries) CreateSentenceBands(ctx context.Context, arg CreateSentenceBandsParams) error {
+	_, err := q.db.Exec(ctx, createSentenceBands,
+		arg.Pdfid,
+		arg.Bands,
+		arg.Buckets,
+		arg.SentenceIds,
+	)
+	return err
+}
+
+const createSummaryScores = `-- name: CreateSummaryScores :exec
+INSERT INTO summary_scores (sentenceId, pdfId, score)
+SELECT unnest($1::int[]), $2, unnest($3::float8[])
+ON CONFLICT (sentenceId) DO NOTHING
+`
+
+type CreateSummaryScoresParams struct {
+	SentenceIds []int32   `json:"sentence_ids"`
+	Pdfid       int32     `json:"pdfid"`
+	Scores      []float64 `json:"scores"`
+}
+
+func (q *Queries) CreateSummaryScores(ctx context.Context, arg CreateSummaryScoresParams) error {
+	_, err := q.db.Exec(ctx, createSummaryScores, arg.SentenceIds, arg.Pdfid, arg.Scores)
+	return err
+}
+
+const createTable = `-- name: CreateTable :one
+INSERT INTO document_tables (pdfId, page, x0, y0, x1, y1, numOfRows, numOfColumns)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, pdfid, page, x0, y0, x1, y1, numofrows, numofcolumns
+`
+
+type CreateTableParams struct {
+	Pdfid        int32   `json:"pdfid"`
+	Page         int32   `json:"page"`
+	X0           float64 `json:"x0"`
+	Y0           float64 `json:"y0"`
+	X1           float64 `json:"x1"`
+	Y1           float64 `json:"y1"`
+	Numofrows    int32   `json:"numofrows"`
+	Numofcolumns int32   `json:"numofcolumns"`
+}
+
+func (q *Queries) CreateTable(ctx context.Context, arg CreateTableParams) (DocumentTable, error) {
+	row := q.db.QueryRow(ctx, createTable,
+		arg.Pdfid,
+		arg.Page,
+		arg.X0,
+		arg.Y0,
+		arg.X1,
+		arg.Y1,
+		arg.Numofrows,
+		arg.Numofcolumns,
+	)
+	var i DocumentTable
+	err := row.Scan(
+		&i.ID,
+		&i.Pdfid,
+		&i.Page,
+		&i.X0,
+		&i.Y0,
+		&i.X1,
+		&i.Y1,
+		&i.Numofrows,
+		&i.Numofcolumns,
+	)
+	return i, err
+}
+
+const createTableCells = `-- name: CreateTableCells :exec
+INSERT INTO table_cells (tableId, rowIndex, columnIndex, text)
+SELECT $1, unnest($2::int[]), unnest($3::int[]), unnest($4::text[])
+`
+
+type CreateTableCellsParams struct {
+	Tableid int32    `json:"tableid"`
+	Rows    []int32  `json:"rows"`
+	Columns []int32  `json:"columns"`
+	Texts   []string `json:"texts"`
+}
+
+func (q *Queries) CreateTableCells(ctx context.Context, arg CreateTableCellsParams) error {
+	_, err := q.db.Exec(ctx, createTableCells,
+		arg.Tableid,
+		arg.Rows,
+		arg.Columns,
+		arg.Texts,
+	)
+	return err
+}
+
+const createUpload = `-- name: CreateUpload :one
+INSERT INTO uploads (id, name, contentType, length, multipartId, checksum, expiresAt)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, name, contenttype, length, received, parts, multipartid, checksum, status, pdfid, errorcode, errormessage, createdat, expiresat, hashstate
+`
+
+type CreateUploadParams struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Contenttype string    `json:"contenttype"`
+	Length      int64     `json:"length"`
+	Multipartid string    `json:"multipartid"`
+	Checksum    string    `json:"checksum"`
+	Expiresat   time.Time `json:"expiresat"`
+}
+
+func (q *Queries) CreateUpload(ctx context.Context, arg CreateUploadParams) (Upload, error) {
+	row := q.db.QueryRow(ctx, createUpload,
+		arg.ID,
+		arg.Name,
+		arg.Contenttype,
+		arg.Length,
+		arg.Multipartid,
+		arg.Checksum,
+		arg.Expiresat,
+	)
+	var i Upload
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Contenttype,
+		&i.Length,
+		&i.Received,
+		&i.Parts,
+		&i.Multipartid,
+		&i.Checksum,
+		&i.Status,
+		&i.Pdfid,
+		&i.Errorcode,
+		&i.Errormessage,
+		&i.Createdat,
+		&i.Expiresat,
+		&i.Hashstate,
+	)
 	return i, err
 }
 
@@ -74,8 +530,136 @@ func (q *Queries) DeleteRecord(ctx context.Context, name string) error {
 	return err
 }
 
+const deleteRecordByID = `-- name: DeleteRecordByID :exec
+DELETE FROM records
+WHERE id = $1
+`
+
+func (q *Queries) DeleteRecordByID(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, deleteRecordByID, id)
+	return err
+}
+
+const deleteRecordExtraction = `-- name: DeleteRecordExtraction :exec
+WITH deleted_sentences AS (
+  DELETE FROM sentences WHERE pdfId = $1
+), deleted_sections AS (
+  DELETE FROM sections WHERE pdfId = $1
+), deleted_pages AS (
+  DELETE FROM pages WHERE pdfId = $1
+), deleted_tables AS (
+  DELETE FROM document_tables WHERE pdfId = $1
+), deleted_metadata AS (
+  DELETE FROM document_metadata WHERE pdfId = $1
+), deleted_outline AS (
+  DELETE FROM outline_items WHERE pdfId = $1
+), deleted_terms AS (
+  DELETE FROM document_terms WHERE pdfId = $1
+), deleted_stats AS (
+  DELETE FROM document_stats WHERE pdfId = $1
+)
+DELETE FROM duplicate_index WHERE pdfId = $1
+`
+
+func (q *Queries) DeleteRecordExtraction(ctx context.Context, pdfid int32) error {
+	_, err := q.db.Exec(ctx, deleteRecordExtraction, pdfid)
+	return err
+}
+
+const deleteUpload = `-- name: DeleteUpload :exec
+DELETE FROM uploads
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUpload(ctx context.Context, id string) error {
+	_, err := q.db.Exec(ctx, deleteUpload, id)
+	return err
+}
+
+const failIngestingUploads = `-- name: FailIngestingUploads :execrows
+UPDATE uploads
+  set status = 'failed', errorCode = $1, errorMessage = $2
+WHERE status = 'ingesting'
+`
+
+type FailIngestingUploadsParams struct {
+	Errorcode    string `json:"errorcode"`
+	Errormessage string `json:"errormessage"`
+}
+
+func (q *Queries) FailIngestingUploads(ctx context.Context, arg FailIngestingUploadsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, failIngestingUploads, arg.Errorcode, arg.Errormessage)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getCorpusStats = `-- name: GetCorpusStats :one
+SELECT count(*)::int AS documents,
+  COALESCE(avg(term_count), 0)::float8 AS average_length,
+  COALESCE(sum(term_count), 0)::bigint AS terms,
+  (SELECT count(*) FROM sentences WHERE pdfId IN (SELECT pdfId FROM document_stats))::bigint AS sentences
+FROM document_stats
+`
+
+type GetCorpusStatsRow struct {
+	Documents     int32   `json:"documents"`
+	AverageLength float64 `json:"average_length"`
+	Terms         int64   `json:"terms"`
+	Sentences     int64   `json:"sentences"`
+}
+
+func (q *Queries) GetCorpusStats(ctx context.Context) (GetCorpusStatsRow, error) {
+	row := q.db.QueryRow(ctx, getCorpusStats)
+	var i GetCorpusStatsRow
+	err := row.Scan(
+		&i.Documents,
+		&i.AverageLength,
+		&i.Terms,
+		&i.Sentences,
+	)
+	return i, err
+}
+
+const getDocumentStats = `-- name: GetDocumentStats :one
+SELECT pdfid, term_count FROM document_stats
+WHERE pdfId = $1
+`
+
+func (q *Queries) GetDocumentStats(ctx context.Context, pdfid int32) (DocumentStat, error) {
+	row := q.db.QueryRow(ctx, getDocumentStats, pdfid)
+	var i DocumentStat
+	err := row.Scan(&i.Pdfid, &i.TermCount)
+	return i, err
+}
+
+const getMetadata = `-- name: GetMetadata :one
+SELECT pdfid, title, author, subject, keywords, creator, producer, created, modified, pdfversion, encrypted FROM document_metadata
+WHERE pdfId = $1
+`
+
+func (q *Queries) GetMetadata(ctx context.Context, pdfid int32) (DocumentMetadatum, error) {
+	row := q.db.QueryRow(ctx, getMetadata, pdfid)
+	var i DocumentMetadatum
+	err := row.Scan(
+		&i.Pdfid,
+		&i.Title,
+		&i.Author,
+		&i.Subject,
+		&i.Keywords,
+		&i.Creator,
+		&i.Producer,
+		&i.Created,
+		&i.Modified,
+		&i.Pdfversion,
+		&i.Encrypted,
+	)
+	return i, err
+}
+
 const getRecord = `-- name: GetRecord :one
-SELECT id, name, upload_time, numofpages, size FROM records
+SELECT id, name, upload_time, numofpages, size, language, mimetype, objectkey, extractor, extractorversion FROM records
 WHERE id = $1 LIMIT 1
 `
 
@@ -88,24 +672,120 @@ func (q *Queries) GetRecord(ctx context.Context, id int32) (Record, error) {
 		&i.UploadTime,
 		&i.Numofpages,
 		&i.Size,
+		&i.Language,
+		&i.Mimetype,
+		&i.Objectkey,
+		&i.Extractor,
+		&i.Extractorversion,
 	)
 	return i, err
 }
 
-const listRecordSentences = `-- name: ListRecordSentences :many
-SELECT id, sentence, pdfid FROM sentences where pdfId = $1
+const getTable = `-- name: GetTable :one
+SELECT id, pdfid, page, x0, y0, x1, y1, numofrows, numofcolumns FROM document_tables
+WHERE id = $1 AND pdfId = $2
 `
 
-func (q *Queries) ListRecordSentences(ctx context.Context, pdfid int32) ([]Sentence, error) {
-	rows, err := q.db.Query(ctx, listRecordSentences, pdfid)
+type GetTableParams struct {
+	ID    int32 `json:"id"`
+	Pdfid int32 `json:"pdfid"`
+}
+
+func (q *Queries) GetTable(ctx context.Context, arg GetTableParams) (DocumentTable, error) {
+	row := q.db.QueryRow(ctx, getTable, arg.ID, arg.Pdfid)
+	var i DocumentTable
+	err := row.Scan(
+		&i.ID,
+		&i.Pdfid,
+		&i.Page,
+		&i.X0,
+		&i.Y0,
+		&i.X1,
+		&i.Y1,
+		&i.Numofrows,
+		&i.Numofcolumns,
+	)
+	return i, err
+}
+
+const getUpload = `-- name: GetUpload :one
+SELECT id, name, contenttype, length, received, parts, multipartid, checksum, status, pdfid, errorcode, errormessage, createdat, expiresat, hashstate FROM uploads
+WHERE id = $1
+`
+
+func (q *Queries) GetUpload(ctx context.Context, id string) (Upload, error) {
+	row := q.db.QueryRow(ctx, getUpload, id)
+	var i Upload
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Contenttype,
+		&i.Length,
+		&i.Received,
+		&i.Parts,
+		&i.Multipartid,
+		&i.Checksum,
+		&i.Status,
+		&i.Pdfid,
+		&i.Errorcode,
+		&i.Errormessage,
+		&i.Createdat,
+		&i.Expiresat,
+		&i.Hashstate,
+	)
+	return i, err
+}
+
+const indexDocument = `-- name: IndexDocument :exec
+WITH stats AS (
+  INSERT INTO document_stats (pdfId, term_count)
+  VALUES ($1, $2)
+)
+INSERT INTO document_terms (pdfId, term, count, surface)
+SELECT $1, unnest($3::text[]), unnest($4::int[]), unnest($5::text[])
+`
+
+type IndexDocumentParams struct {
+	Pdfid     int32    `json:"pdfid"`
+	TermCount int32    `json:"term_count"`
+	Terms     []string `json:"terms"`
+	Counts    []int32  `json:"counts"`
+	Surfaces  []string `json:"surfaces"`
+}
+
+func (q *Queries) IndexDocument(ctx context.Context, arg IndexDocumentParams) error {
+	_, err := q.db.Exec(ctx, indexDocument,
+		arg.Pdfid,
+		arg.TermCount,
+		arg.Terms,
+		arg.Counts,
+		arg.Surfaces,
+	)
+	return err
+}
+
+const listCandidatePairs = `-- name: ListCandidatePairs :many
+SELECT DISTINCT a.sentenceId AS sentence_id, b.sentenceId AS other_sentence_id
+FROM sentence_bands a
+JOIN sentence_bands b ON b.band = a.band AND b.bucket = a.bucket AND b.pdfId <> a.pdfId
+WHERE a.pdfId = $1
+`
+
+type ListCandidatePairsRow struct {
+	SentenceID      int32 `json:"sentence_id"`
+	OtherSentenceID int32 `json:"other_sentence_id"`
+}
+
+func (q *Queries) ListCandidatePairs(ctx context.Context, pdfid int32) ([]ListCandidatePairsRow, error) {
+	rows, err := q.db.Query(ctx, listCandidatePairs, pdfid)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Sentence
+	var items []ListCandidatePairsRow
 	for rows.Next() {
-		var i Sentence
-		if err := rows.Scan(&i.ID, &i.Sentence, &i.Pdfid); err != nil {
+		var i ListCandidatePairsRow
+		if err := rows.Scan(&i.SentenceID, &i.OtherSentenceID); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -116,27 +796,46 @@ func (q *Queries) ListRecordSentences(ctx context.Context, pdfid int32) ([]Sente
 	return items, nil
 }
 
-const listRecords = `-- name: ListRecords :many
-SELECT id, name, upload_time, numofpages, size FROM records
-ORDER BY name
+const listCoOccurringEntities = `-- name: ListCoOccurringEntities :many
+SELECT o.text, o.label, count(DISTINCT o.sentenceId) AS mentions
+FROM entities e
+JOIN entities o ON o.sentenceId = e.sentenceId AND lower(o.text) <> lower(e.text)
+WHERE lower(e.text) = lower($1::text)
+  AND ($2::text IS NULL OR e.label = $2::text)
+  AND ($3::int IS NULL OR e.pdfId = $3::int)
+GROUP BY o.text, o.label
+ORDER BY mentions DESC, o.text, o.label
+LIMIT $4::int
 `
 
-func (q *Queries) ListRecords(ctx context.Context) ([]Record, error) {
-	rows, err := q.db.Query(ctx, listRecords)
+type ListCoOccurringEntitiesParams struct {
+	Text     string         `json:"text"`
+	Label    sql.NullString `json:"label"`
+	Pdfid    sql.NullInt32  `json:"pdfid"`
+	PageSize int32          `json:"page_size"`
+}
+
+type ListCoOccurringEntitiesRow struct {
+	Text     string `json:"text"`
+	Label    string `json:"label"`
+	Mentions int64  `json:"mentions"`
+}
+
+func (q *Queries) ListCoOccurringEntities(ctx context.Context, arg ListCoOccurringEntitiesParams) ([]ListCoOccurringEntitiesRow, error) {
+	rows, err := q.db.Query(ctx, listCoOccurringEntities,
+		arg.Text,
+		arg.Label,
+		arg.Pdfid,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Record
+	var items []ListCoOccurringEntitiesRow
 	for rows.Next() {
-		var i Record
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.UploadTime,
-			&i.Numofpages,
-			&i.Size,
-		); err != nil {
+		var i ListCoOccurringEntitiesRow
+		if err := rows.Scan(&i.Text, &i.Label, &i.Mentions); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -147,20 +846,38 @@ func (q *Queries) ListRecords(ctx context.Context) ([]Record, error) {
 	return items, nil
 }
 
-const listSentences = `-- name: ListSentences :many
-SELECT id, sentence, pdfid FROM sentences
+const listDocumentEntities = `-- name: ListDocumentEntities :many
+SELECT text, label, count(*) AS mentions
+FROM entities
+WHERE pdfId = $1
+  AND ($2::text IS NULL OR label = $2::text)
+GROUP BY text, label
+ORDER BY mentions DESC, text, label
+LIMIT $3::int
 `
 
-func (q *Queries) ListSentences(ctx context.Context) ([]Sentence, error) {
-	rows, err := q.db.Query(ctx, listSentences)
+type ListDocumentEntitiesParams struct {
+	Pdfid    int32          `json:"pdfid"`
+	Label    sql.NullString `json:"label"`
+	PageSize int32          `json:"page_size"`
+}
+
+type ListDocumentEntitiesRow struct {
+	Text     string `json:"text"`
+	Label    string `json:"label"`
+	Mentions int64  `json:"mentions"`
+}
+
+func (q *Queries) ListDocumentEntities(ctx context.Context, arg ListDocumentEntitiesParams) ([]ListDocumentEntitiesRow, error) {
+	rows, err := q.db.Query(ctx, listDocumentEntities, arg.Pdfid, arg.Label, arg.PageSize)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Sentence
+	var items []ListDocumentEntitiesRow
 	for rows.Next() {
-		var i Sentence
-		if err := rows.Scan(&i.ID, &i.Sentence, &i.Pdfid); err != nil {
+		var i ListDocumentEntitiesRow
+		if err := rows.Scan(&i.Text, &i.Label, &i.Mentions); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -171,18 +888,1753 @@ func (q *Queries) ListSentences(ctx context.Context) ([]Sentence, error) {
 	return items, nil
 }
 
-const updateRecord = `-- name: UpdateRecord :exec
-UPDATE records
-  set name = $2
-WHERE id = $1
+const listDocumentTermStats = `-- name: ListDocumentTermStats :many
+SELECT d.term, d.surface, d.count, COALESCE(t.document_count, 0)::int AS document_count
+FROM document_terms d
+LEFT JOIN term_stats t ON t.term = d.term
+WHERE d.pdfId = $1
 `
 
-type UpdateRecordParams struct {
-	ID   int32  `json:"id"`
-	Name string `json:"name"`
+type ListDocumentTermStatsRow struct {
+	Term          string `json:"term"`
+	Surface       string `json:"surface"`
+	Count         int32  `json:"count"`
+	DocumentCount int32  `json:"document_count"`
 }
 
-func (q *Queries) UpdateRecord(ctx context.Context, arg UpdateRecordParams) error {
-	_, err := q.db.Exec(ctx, updateRecord, arg.ID, arg.Name)
+func (q *Queries) ListDocumentTermStats(ctx context.Context, pdfid int32) ([]ListDocumentTermStatsRow, error) {
+	rows, err := q.db.Query(ctx, listDocumentTermStats, pdfid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDocumentTermStatsRow
+	for rows.Next() {
+		var i ListDocumentTermStatsRow
+		if err := rows.Scan(
+			&i.Term,
+			&i.Surface,
+			&i.Count,
+			&i.DocumentCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDuplicatePending = `-- name: ListDuplicatePending :many
+SELECT id FROM records
+WHERE NOT EXISTS (SELECT 1 FROM duplicate_index WHERE duplicate_index.pdfId = records.id)
+ORDER BY id
+`
+
+func (q *Queries) ListDuplicatePending(ctx context.Context) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listDuplicatePending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntityDocuments = `-- name: ListEntityDocuments :many
+SELECT r.id, r.name, r.upload_time, r.numofpages, r.size, r.language, r.mimetype, r.objectkey, r.extractor, r.extractorversion, count(*) AS mentions
+FROM entities e
+JOIN records r ON r.id = e.pdfId
+WHERE lower(e.text) = lower($1::text)
+  AND ($2::text IS NULL OR e.label = $2::text)
+GROUP BY r.id
+ORDER BY mentions DESC, r.id
+LIMIT $3::int
+`
+
+type ListEntityDocumentsParams struct {
+	Text     string         `json:"text"`
+	Label    sql.NullString `json:"label"`
+	PageSize int32          `json:"page_size"`
+}
+
+type ListEntityDocumentsRow struct {
+	ID               int32     `json:"id"`
+	Name             string    `json:"name"`
+	UploadTime       time.Time `json:"upload_time"`
+	Numofpages       int32     `json:"numofpages"`
+	Size             int64     `json:"size"`
+	Language         string    `json:"language"`
+	Mimetype         string    `json:"mimetype"`
+	Objectkey        string    `json:"objectkey"`
+	Extractor        string    `json:"extractor"`
+	Extractorversion int32     `json:"extractorversion"`
+	Mentions         int64     `json:"mentions"`
+}
+
+func (q *Queries) ListEntityDocuments(ctx context.Context, arg ListEntityDocumentsParams) ([]ListEntityDocumentsRow, error) {
+	rows, err := q.db.Query(ctx, listEntityDocuments, arg.Text, arg.Label, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEntityDocumentsRow
+	for rows.Next() {
+		var i ListEntityDocumentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+			&i.Mentions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredUploads = `-- name: ListExpiredUploads :many
+SELECT id, name, contenttype, length, received, parts, multipartid, checksum, status, pdfid, errorcode, errormessage, createdat, expiresat, hashstate FROM uploads
+WHERE expiresAt < NOW()
+ORDER BY expiresAt
+`
+
+func (q *Queries) ListExpiredUploads(ctx context.Context) ([]Upload, error) {
+	rows, err := q.db.Query(ctx, listExpiredUploads)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Upload
+	for rows.Next() {
+		var i Upload
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Contenttype,
+			&i.Length,
+			&i.Received,
+			&i.Parts,
+			&i.Multipartid,
+			&i.Checksum,
+			&i.Status,
+			&i.Pdfid,
+			&i.Errorcode,
+			&i.Errormessage,
+			&i.Createdat,
+			&i.Expiresat,
+			&i.Hashstate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordLanguages = `-- name: ListRecordLanguages :many
+SELECT DISTINCT language FROM records
+ORDER BY language
+`
+
+func (q *Queries) ListRecordLanguages(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, listRecordLanguages)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var language string
+		if err := rows.Scan(&language); err != nil {
+			return nil, err
+		}
+		items = append(items, language)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordOutline = `-- name: ListRecordOutline :many
+SELECT id, pdfid, parentid, title, page FROM outline_items
+WHERE pdfId = $1
+ORDER BY id
+`
+
+func (q *Queries) ListRecordOutline(ctx context.Context, pdfid int32) ([]OutlineItem, error) {
+	rows, err := q.db.Query(ctx, listRecordOutline, pdfid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutlineItem
+	for rows.Next() {
+		var i OutlineItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.Pdfid,
+			&i.Parentid,
+			&i.Title,
+			&i.Page,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordPages = `-- name: ListRecordPages :many
+SELECT pdfid, page, ocr, confidence, width, height FROM pages
+WHERE pdfId = $1
+ORDER BY page
+`
+
+func (q *Queries) ListRecordPages(ctx context.Context, pdfid int32) ([]Page, error) {
+	rows, err := q.db.Query(ctx, listRecordPages, pdfid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Page
+	for rows.Next() {
+		var i Page
+		if err := rows.Scan(
+			&i.Pdfid,
+			&i.Page,
+			&i.Ocr,
+			&i.Confidence,
+			&i.Width,
+			&i.Height,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordParagraphs = `-- name: ListRecordParagraphs :many
+SELECT id, pdfid, sectionid, page, x0, y0, x1, y1 FROM paragraphs
+WHERE pdfId = $1
+ORDER BY id
+`
+
+func (q *Queries) ListRecordParagraphs(ctx context.Context, pdfid int32) ([]Paragraph, error) {
+	rows, err := q.db.Query(ctx, listRecordParagraphs, pdfid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Paragraph
+	for rows.Next() {
+		var i Paragraph
+		if err := rows.Scan(
+			&i.ID,
+			&i.Pdfid,
+			&i.Sectionid,
+			&i.Page,
+			&i.X0,
+			&i.Y0,
+			&i.X1,
+			&i.Y1,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordSections = `-- name: ListRecordSections :many
+SELECT id, pdfid, parentid, level, title, page FROM sections
+WHERE pdfId = $1
+ORDER BY id
+`
+
+func (q *Queries) ListRecordSections(ctx context.Context, pdfid int32) ([]Section, error) {
+	rows, err := q.db.Query(ctx, listRecordSections, pdfid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Section
+	for rows.Next() {
+		var i Section
+		if err := rows.Scan(
+			&i.ID,
+			&i.Pdfid,
+			&i.Parentid,
+			&i.Level,
+			&i.Title,
+			&i.Page,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordSentences = `-- name: ListRecordSentences :many
+SELECT id, sentence, pdfid, page, paragraphid FROM sentences where pdfId = $1
+`
+
+func (q *Queries) ListRecordSentences(ctx context.Context, pdfid int32) ([]Sentence, error) {
+	rows, err := q.db.Query(ctx, listRecordSentences, pdfid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Sentence
+	for rows.Next() {
+		var i Sentence
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sentence,
+			&i.Pdfid,
+			&i.Page,
+			&i.Paragraphid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordSentencesInPages = `-- name: ListRecordSentencesInPages :many
+SELECT id, sentence, pdfid, page, paragraphid FROM sentences
+WHERE pdfId = $1
+  AND ($2::int IS NULL OR page >= $2::int)
+  AND ($3::int IS NULL OR page <= $3::int)
+ORDER BY id
+`
+
+type ListRecordSentencesInPagesParams struct {
+	Pdfid    int32         `json:"pdfid"`
+	PageFrom sql.NullInt32 `json:"page_from"`
+	PageTo   sql.NullInt32 `json:"page_to"`
+}
+
+func (q *Queries) ListRecordSentencesInPages(ctx context.Context, arg ListRecordSentencesInPagesParams) ([]Sentence, error) {
+	rows, err := q.db.Query(ctx, listRecordSentencesInPages, arg.Pdfid, arg.PageFrom, arg.PageTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Sentence
+	for rows.Next() {
+		var i Sentence
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sentence,
+			&i.Pdfid,
+			&i.Page,
+			&i.Paragraphid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordSentencesPage = `-- name: ListRecordSentencesPage :many
+SELECT id, sentence, pdfid, page, paragraphid FROM sentences
+WHERE pdfId = $1 AND id > $2
+ORDER BY id
+LIMIT $3::int
+`
+
+type ListRecordSentencesPageParams struct {
+	Pdfid    int32 `json:"pdfid"`
+	AfterID  int32 `json:"after_id"`
+	PageSize int32 `json:"page_size"`
+}
+
+func (q *Queries) ListRecordSentencesPage(ctx context.Context, arg ListRecordSentencesPageParams) ([]Sentence, error) {
+	rows, err := q.db.Query(ctx, listRecordSentencesPage, arg.Pdfid, arg.AfterID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Sentence
+	for rows.Next() {
+		var i Sentence
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sentence,
+			&i.Pdfid,
+			&i.Page,
+			&i.Paragraphid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordTables = `-- name: ListRecordTables :many
+SELECT id, pdfid, page, x0, y0, x1, y1, numofrows, numofcolumns FROM document_tables
+WHERE pdfId = $1
+ORDER BY id
+`
+
+func (q *Queries) ListRecordTables(ctx context.Context, pdfid int32) ([]DocumentTable, error) {
+	rows, err := q.db.Query(ctx, listRecordTables, pdfid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DocumentTable
+	for rows.Next() {
+		var i DocumentTable
+		if err := rows.Scan(
+			&i.ID,
+			&i.Pdfid,
+			&i.Page,
+			&i.X0,
+			&i.Y0,
+			&i.X1,
+			&i.Y1,
+			&i.Numofrows,
+			&i.Numofcolumns,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecords = `-- name: ListRecords :many
+SELECT id, name, upload_time, numofpages, size, language, mimetype, objectkey, extractor, extractorversion FROM records
+ORDER BY name
+`
+
+func (q *Queries) ListRecords(ctx context.Context) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listRecords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsByID = `-- name: ListRecordsByID :many
+SELECT id, name, upload_time, numofpages, size, language, mimetype, objectkey, extractor, extractorversion FROM records
+WHERE id = ANY($1::int[])
+ORDER BY id
+`
+
+func (q *Queries) ListRecordsByID(ctx context.Context, ids []int32) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listRecordsByID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsByName = `-- name: ListRecordsByName :many
+SELECT records.id, records.name, records.upload_time, records.numofpages, records.size, records.language, records.mimetype, records.objectkey, records.extractor, records.extractorversion FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE ($1::text IS NULL OR name LIKE $1::text || '%')
+  AND ($2::timestamp IS NULL OR upload_time >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR upload_time < $3::timestamp)
+  AND ($4::bigint IS NULL OR size >= $4::bigint)
+  AND ($5::bigint IS NULL OR size <= $5::bigint)
+  AND ($6::int IS NULL OR numOfPages >= $6::int)
+  AND ($7::int IS NULL OR numOfPages <= $7::int)
+  AND ($8::text IS NULL OR metadata.title ILIKE '%' || $8::text || '%')
+  AND ($9::text IS NULL OR metadata.author ILIKE '%' || $9::text || '%')
+  AND ($10::text IS NULL OR metadata.keywords ILIKE '%' || $10::text || '%')
+  AND ($11::text IS NULL OR metadata.producer ILIKE '%' || $11::text || '%')
+  AND ($12::text IS NULL OR metadata.pdfVersion = $12::text)
+  AND ($13::bool IS NULL OR metadata.encrypted = $13::bool)
+  AND ($14::timestamp IS NULL OR metadata.created >= $14::timestamp)
+  AND ($15::timestamp IS NULL OR metadata.created < $15::timestamp)
+  AND ($16::int IS NULL OR extractorVersion < $16::int)
+  AND ($17::text IS NULL OR (name, records.id) > ($17::text, $18::int))
+ORDER BY name, records.id
+LIMIT $19::int
+`
+
+type ListRecordsByNameParams struct {
+	NamePrefix            sql.NullString `json:"name_prefix"`
+	UploadedAfter         sql.NullTime   `json:"uploaded_after"`
+	UploadedBefore        sql.NullTime   `json:"uploaded_before"`
+	MinSize               sql.NullInt64  `json:"min_size"`
+	MaxSize               sql.NullInt64  `json:"max_size"`
+	MinPages              sql.NullInt32  `json:"min_pages"`
+	MaxPages              sql.NullInt32  `json:"max_pages"`
+	Title                 sql.NullString `json:"title"`
+	Author                sql.NullString `json:"author"`
+	Keywords              sql.NullString `json:"keywords"`
+	Producer              sql.NullString `json:"producer"`
+	PdfVersion            sql.NullString `json:"pdf_version"`
+	Encrypted             sql.NullBool   `json:"encrypted"`
+	CreatedAfter          sql.NullTime   `json:"created_after"`
+	CreatedBefore         sql.NullTime   `json:"created_before"`
+	ExtractorVersionBelow sql.NullInt32  `json:"extractor_version_below"`
+	AfterKey              sql.NullString `json:"after_key"`
+	AfterID               int32          `json:"after_id"`
+	PageSize              int32          `json:"page_size"`
+}
+
+func (q *Queries) ListRecordsByName(ctx context.Context, arg ListRecordsByNameParams) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listRecordsByName,
+		arg.NamePrefix,
+		arg.UploadedAfter,
+		arg.UploadedBefore,
+		arg.MinSize,
+		arg.MaxSize,
+		arg.MinPages,
+		arg.MaxPages,
+		arg.Title,
+		arg.Author,
+		arg.Keywords,
+		arg.Producer,
+		arg.PdfVersion,
+		arg.Encrypted,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ExtractorVersionBelow,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsByNameDesc = `-- name: ListRecordsByNameDesc :many
+SELECT records.id, records.name, records.upload_time, records.numofpages, records.size, records.language, records.mimetype, records.objectkey, records.extractor, records.extractorversion FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE ($1::text IS NULL OR name LIKE $1::text || '%')
+  AND ($2::timestamp IS NULL OR upload_time >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR upload_time < $3::timestamp)
+  AND ($4::bigint IS NULL OR size >= $4::bigint)
+  AND ($5::bigint IS NULL OR size <= $5::bigint)
+  AND ($6::int IS NULL OR numOfPages >= $6::int)
+  AND ($7::int IS NULL OR numOfPages <= $7::int)
+  AND ($8::text IS NULL OR metadata.title ILIKE '%' || $8::text || '%')
+  AND ($9::text IS NULL OR metadata.author ILIKE '%' || $9::text || '%')
+  AND ($10::text IS NULL OR metadata.keywords ILIKE '%' || $10::text || '%')
+  AND ($11::text IS NULL OR metadata.producer ILIKE '%' || $11::text || '%')
+  AND ($12::text IS NULL OR metadata.pdfVersion = $12::text)
+  AND ($13::bool IS NULL OR metadata.encrypted = $13::bool)
+  AND ($14::timestamp IS NULL OR metadata.created >= $14::timestamp)
+  AND ($15::timestamp IS NULL OR metadata.created < $15::timestamp)
+  AND ($16::int IS NULL OR extractorVersion < $16::int)
+  AND ($17::text IS NULL OR (name, records.id) < ($17::text, $18::int))
+ORDER BY name DESC, records.id DESC
+LIMIT $19::int
+`
+
+type ListRecordsByNameDescParams struct {
+	NamePrefix            sql.NullString `json:"name_prefix"`
+	UploadedAfter         sql.NullTime   `json:"uploaded_after"`
+	UploadedBefore        sql.NullTime   `json:"uploaded_before"`
+	MinSize               sql.NullInt64  `json:"min_size"`
+	MaxSize               sql.NullInt64  `json:"max_size"`
+	MinPages              sql.NullInt32  `json:"min_pages"`
+	MaxPages              sql.NullInt32  `json:"max_pages"`
+	Title                 sql.NullString `json:"title"`
+	Author                sql.NullString `json:"author"`
+	Keywords              sql.NullString `json:"keywords"`
+	Producer              sql.NullString `json:"producer"`
+	PdfVersion            sql.NullString `json:"pdf_version"`
+	Encrypted             sql.NullBool   `json:"encrypted"`
+	CreatedAfter          sql.NullTime   `json:"created_after"`
+	CreatedBefore         sql.NullTime   `json:"created_before"`
+	ExtractorVersionBelow sql.NullInt32  `json:"extractor_version_below"`
+	AfterKey              sql.NullString `json:"after_key"`
+	AfterID               int32          `json:"after_id"`
+	PageSize              int32          `json:"page_size"`
+}
+
+func (q *Queries) ListRecordsByNameDesc(ctx context.Context, arg ListRecordsByNameDescParams) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listRecordsByNameDesc,
+		arg.NamePrefix,
+		arg.UploadedAfter,
+		arg.UploadedBefore,
+		arg.MinSize,
+		arg.MaxSize,
+		arg.MinPages,
+		arg.MaxPages,
+		arg.Title,
+		arg.Author,
+		arg.Keywords,
+		arg.Producer,
+		arg.PdfVersion,
+		arg.Encrypted,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ExtractorVersionBelow,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsByPages = `-- name: ListRecordsByPages :many
+SELECT records.id, records.name, records.upload_time, records.numofpages, records.size, records.language, records.mimetype, records.objectkey, records.extractor, records.extractorversion FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE ($1::text IS NULL OR name LIKE $1::text || '%')
+  AND ($2::timestamp IS NULL OR upload_time >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR upload_time < $3::timestamp)
+  AND ($4::bigint IS NULL OR size >= $4::bigint)
+  AND ($5::bigint IS NULL OR size <= $5::bigint)
+  AND ($6::int IS NULL OR numOfPages >= $6::int)
+  AND ($7::int IS NULL OR numOfPages <= $7::int)
+  AND ($8::text IS NULL OR metadata.title ILIKE '%' || $8::text || '%')
+  AND ($9::text IS NULL OR metadata.author ILIKE '%' || $9::text || '%')
+  AND ($10::text IS NULL OR metadata.keywords ILIKE '%' || $10::text || '%')
+  AND ($11::text IS NULL OR metadata.producer ILIKE '%' || $11::text || '%')
+  AND ($12::text IS NULL OR metadata.pdfVersion = $12::text)
+  AND ($13::bool IS NULL OR metadata.encrypted = $13::bool)
+  AND ($14::timestamp IS NULL OR metadata.created >= $14::timestamp)
+  AND ($15::timestamp IS NULL OR metadata.created < $15::timestamp)
+  AND ($16::int IS NULL OR extractorVersion < $16::int)
+  AND ($17::text IS NULL OR (numOfPages, records.id) > (CAST($17::text AS int), $18::int))
+ORDER BY numOfPages, records.id
+LIMIT $19::int
+`
+
+type ListRecordsByPagesParams struct {
+	NamePrefix            sql.NullString `json:"name_prefix"`
+	UploadedAfter         sql.NullTime   `json:"uploaded_after"`
+	UploadedBefore        sql.NullTime   `json:"uploaded_before"`
+	MinSize               sql.NullInt64  `json:"min_size"`
+	MaxSize               sql.NullInt64  `json:"max_size"`
+	MinPages              sql.NullInt32  `json:"min_pages"`
+	MaxPages              sql.NullInt32  `json:"max_pages"`
+	Title                 sql.NullString `json:"title"`
+	Author                sql.NullString `json:"author"`
+	Keywords              sql.NullString `json:"keywords"`
+	Producer              sql.NullString `json:"producer"`
+	PdfVersion            sql.NullString `json:"pdf_version"`
+	Encrypted             sql.NullBool   `json:"encrypted"`
+	CreatedAfter          sql.NullTime   `json:"created_after"`
+	CreatedBefore         sql.NullTime   `json:"created_before"`
+	ExtractorVersionBelow sql.NullInt32  `json:"extractor_version_below"`
+	AfterKey              sql.NullString `json:"after_key"`
+	AfterID               int32          `json:"after_id"`
+	PageSize              int32          `json:"page_size"`
+}
+
+func (q *Queries) ListRecordsByPages(ctx context.Context, arg ListRecordsByPagesParams) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listRecordsByPages,
+		arg.NamePrefix,
+		arg.UploadedAfter,
+		arg.UploadedBefore,
+		arg.MinSize,
+		arg.MaxSize,
+		arg.MinPages,
+		arg.MaxPages,
+		arg.Title,
+		arg.Author,
+		arg.Keywords,
+		arg.Producer,
+		arg.PdfVersion,
+		arg.Encrypted,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ExtractorVersionBelow,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsByPagesDesc = `-- name: ListRecordsByPagesDesc :many
+SELECT records.id, records.name, records.upload_time, records.numofpages, records.size, records.language, records.mimetype, records.objectkey, records.extractor, records.extractorversion FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE ($1::text IS NULL OR name LIKE $1::text || '%')
+  AND ($2::timestamp IS NULL OR upload_time >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR upload_time < $3::timestamp)
+  AND ($4::bigint IS NULL OR size >= $4::bigint)
+  AND ($5::bigint IS NULL OR size <= $5::bigint)
+  AND ($6::int IS NULL OR numOfPages >= $6::int)
+  AND ($7::int IS NULL OR numOfPages <= $7::int)
+  AND ($8::text IS NULL OR metadata.title ILIKE '%' || $8::text || '%')
+  AND ($9::text IS NULL OR metadata.author ILIKE '%' || $9::text || '%')
+  AND ($10::text IS NULL OR metadata.keywords ILIKE '%' || $10::text || '%')
+  AND ($11::text IS NULL OR metadata.producer ILIKE '%' || $11::text || '%')
+  AND ($12::text IS NULL OR metadata.pdfVersion = $12::text)
+  AND ($13::bool IS NULL OR metadata.encrypted = $13::bool)
+  AND ($14::timestamp IS NULL OR metadata.created >= $14::timestamp)
+  AND ($15::timestamp IS NULL OR metadata.created < $15::timestamp)
+  AND ($16::int IS NULL OR extractorVersion < $16::int)
+  AND ($17::text IS NULL OR (numOfPages, records.id) < (CAST($17::text AS int), $18::int))
+ORDER BY numOfPages DESC, records.id DESC
+LIMIT $19::int
+`
+
+type ListRecordsByPagesDescParams struct {
+	NamePrefix            sql.NullString `json:"name_prefix"`
+	UploadedAfter         sql.NullTime   `json:"uploaded_after"`
+	UploadedBefore        sql.NullTime   `json:"uploaded_before"`
+	MinSize               sql.NullInt64  `json:"min_size"`
+	MaxSize               sql.NullInt64  `json:"max_size"`
+	MinPages              sql.NullInt32  `json:"min_pages"`
+	MaxPages              sql.NullInt32  `json:"max_pages"`
+	Title                 sql.NullString `json:"title"`
+	Author                sql.NullString `json:"author"`
+	Keywords              sql.NullString `json:"keywords"`
+	Producer              sql.NullString `json:"producer"`
+	PdfVersion            sql.NullString `json:"pdf_version"`
+	Encrypted             sql.NullBool   `json:"encrypted"`
+	CreatedAfter          sql.NullTime   `json:"created_after"`
+	CreatedBefore         sql.NullTime   `json:"created_before"`
+	ExtractorVersionBelow sql.NullInt32  `json:"extractor_version_below"`
+	AfterKey              sql.NullString `json:"after_key"`
+	AfterID               int32          `json:"after_id"`
+	PageSize              int32          `json:"page_size"`
+}
+
+func (q *Queries) ListRecordsByPagesDesc(ctx context.Context, arg ListRecordsByPagesDescParams) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listRecordsByPagesDesc,
+		arg.NamePrefix,
+		arg.UploadedAfter,
+		arg.UploadedBefore,
+		arg.MinSize,
+		arg.MaxSize,
+		arg.MinPages,
+		arg.MaxPages,
+		arg.Title,
+		arg.Author,
+		arg.Keywords,
+		arg.Producer,
+		arg.PdfVersion,
+		arg.Encrypted,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ExtractorVersionBelow,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsBySize = `-- name: ListRecordsBySize :many
+SELECT records.id, records.name, records.upload_time, records.numofpages, records.size, records.language, records.mimetype, records.objectkey, records.extractor, records.extractorversion FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE ($1::text IS NULL OR name LIKE $1::text || '%')
+  AND ($2::timestamp IS NULL OR upload_time >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR upload_time < $3::timestamp)
+  AND ($4::bigint IS NULL OR size >= $4::bigint)
+  AND ($5::bigint IS NULL OR size <= $5::bigint)
+  AND ($6::int IS NULL OR numOfPages >= $6::int)
+  AND ($7::int IS NULL OR numOfPages <= $7::int)
+  AND ($8::text IS NULL OR metadata.title ILIKE '%' || $8::text || '%')
+  AND ($9::text IS NULL OR metadata.author ILIKE '%' || $9::text || '%')
+  AND ($10::text IS NULL OR metadata.keywords ILIKE '%' || $10::text || '%')
+  AND ($11::text IS NULL OR metadata.producer ILIKE '%' || $11::text || '%')
+  AND ($12::text IS NULL OR metadata.pdfVersion = $12::text)
+  AND ($13::bool IS NULL OR metadata.encrypted = $13::bool)
+  AND ($14::timestamp IS NULL OR metadata.created >= $14::timestamp)
+  AND ($15::timestamp IS NULL OR metadata.created < $15::timestamp)
+  AND ($16::int IS NULL OR extractorVersion < $16::int)
+  AND ($17::text IS NULL OR (size, records.id) > (CAST($17::text AS bigint), $18::int))
+ORDER BY size, records.id
+LIMIT $19::int
+`
+
+type ListRecordsBySizeParams struct {
+	NamePrefix            sql.NullString `json:"name_prefix"`
+	UploadedAfter         sql.NullTime   `json:"uploaded_after"`
+	UploadedBefore        sql.NullTime   `json:"uploaded_before"`
+	MinSize               sql.NullInt64  `json:"min_size"`
+	MaxSize               sql.NullInt64  `json:"max_size"`
+	MinPages              sql.NullInt32  `json:"min_pages"`
+	MaxPages              sql.NullInt32  `json:"max_pages"`
+	Title                 sql.NullString `json:"title"`
+	Author                sql.NullString `json:"author"`
+	Keywords              sql.NullString `json:"keywords"`
+	Producer              sql.NullString `json:"producer"`
+	PdfVersion            sql.NullString `json:"pdf_version"`
+	Encrypted             sql.NullBool   `json:"encrypted"`
+	CreatedAfter          sql.NullTime   `json:"created_after"`
+	CreatedBefore         sql.NullTime   `json:"created_before"`
+	ExtractorVersionBelow sql.NullInt32  `json:"extractor_version_below"`
+	AfterKey              sql.NullString `json:"after_key"`
+	AfterID               int32          `json:"after_id"`
+	PageSize              int32          `json:"page_size"`
+}
+
+func (q *Queries) ListRecordsBySize(ctx context.Context, arg ListRecordsBySizeParams) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listRecordsBySize,
+		arg.NamePrefix,
+		arg.UploadedAfter,
+		arg.UploadedBefore,
+		arg.MinSize,
+		arg.MaxSize,
+		arg.MinPages,
+		arg.MaxPages,
+		arg.Title,
+		arg.Author,
+		arg.Keywords,
+		arg.Producer,
+		arg.PdfVersion,
+		arg.Encrypted,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ExtractorVersionBelow,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsBySizeDesc = `-- name: ListRecordsBySizeDesc :many
+SELECT records.id, records.name, records.upload_time, records.numofpages, records.size, records.language, records.mimetype, records.objectkey, records.extractor, records.extractorversion FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE ($1::text IS NULL OR name LIKE $1::text || '%')
+  AND ($2::timestamp IS NULL OR upload_time >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR upload_time < $3::timestamp)
+  AND ($4::bigint IS NULL OR size >= $4::bigint)
+  AND ($5::bigint IS NULL OR size <= $5::bigint)
+  AND ($6::int IS NULL OR numOfPages >= $6::int)
+  AND ($7::int IS NULL OR numOfPages <= $7::int)
+  AND ($8::text IS NULL OR metadata.title ILIKE '%' || $8::text || '%')
+  AND ($9::text IS NULL OR metadata.author ILIKE '%' || $9::text || '%')
+  AND ($10::text IS NULL OR metadata.keywords ILIKE '%' || $10::text || '%')
+  AND ($11::text IS NULL OR metadata.producer ILIKE '%' || $11::text || '%')
+  AND ($12::text IS NULL OR metadata.pdfVersion = $12::text)
+  AND ($13::bool IS NULL OR metadata.encrypted = $13::bool)
+  AND ($14::timestamp IS NULL OR metadata.created >= $14::timestamp)
+  AND ($15::timestamp IS NULL OR metadata.created < $15::timestamp)
+  AND ($16::int IS NULL OR extractorVersion < $16::int)
+  AND ($17::text IS NULL OR (size, records.id) < (CAST($17::text AS bigint), $18::int))
+ORDER BY size DESC, records.id DESC
+LIMIT $19::int
+`
+
+type ListRecordsBySizeDescParams struct {
+	NamePrefix            sql.NullString `json:"name_prefix"`
+	UploadedAfter         sql.NullTime   `json:"uploaded_after"`
+	UploadedBefore        sql.NullTime   `json:"uploaded_before"`
+	MinSize               sql.NullInt64  `json:"min_size"`
+	MaxSize               sql.NullInt64  `json:"max_size"`
+	MinPages              sql.NullInt32  `json:"min_pages"`
+	MaxPages              sql.NullInt32  `json:"max_pages"`
+	Title                 sql.NullString `json:"title"`
+	Author                sql.NullString `json:"author"`
+	Keywords              sql.NullString `json:"keywords"`
+	Producer              sql.NullString `json:"producer"`
+	PdfVersion            sql.NullString `json:"pdf_version"`
+	Encrypted             sql.NullBool   `json:"encrypted"`
+	CreatedAfter          sql.NullTime   `json:"created_after"`
+	CreatedBefore         sql.NullTime   `json:"created_before"`
+	ExtractorVersionBelow sql.NullInt32  `json:"extractor_version_below"`
+	AfterKey              sql.NullString `json:"after_key"`
+	AfterID               int32          `json:"after_id"`
+	PageSize              int32          `json:"page_size"`
+}
+
+func (q *Queries) ListRecordsBySizeDesc(ctx context.Context, arg ListRecordsBySizeDescParams) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listRecordsBySizeDesc,
+		arg.NamePrefix,
+		arg.UploadedAfter,
+		arg.UploadedBefore,
+		arg.MinSize,
+		arg.MaxSize,
+		arg.MinPages,
+		arg.MaxPages,
+		arg.Title,
+		arg.Author,
+		arg.Keywords,
+		arg.Producer,
+		arg.PdfVersion,
+		arg.Encrypted,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ExtractorVersionBelow,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsByUploadTime = `-- name: ListRecordsByUploadTime :many
+SELECT records.id, records.name, records.upload_time, records.numofpages, records.size, records.language, records.mimetype, records.objectkey, records.extractor, records.extractorversion FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE ($1::text IS NULL OR name LIKE $1::text || '%')
+  AND ($2::timestamp IS NULL OR upload_time >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR upload_time < $3::timestamp)
+  AND ($4::bigint IS NULL OR size >= $4::bigint)
+  AND ($5::bigint IS NULL OR size <= $5::bigint)
+  AND ($6::int IS NULL OR numOfPages >= $6::int)
+  AND ($7::int IS NULL OR numOfPages <= $7::int)
+  AND ($8::text IS NULL OR metadata.title ILIKE '%' || $8::text || '%')
+  AND ($9::text IS NULL OR metadata.author ILIKE '%' || $9::text || '%')
+  AND ($10::text IS NULL OR metadata.keywords ILIKE '%' || $10::text || '%')
+  AND ($11::text IS NULL OR metadata.producer ILIKE '%' || $11::text || '%')
+  AND ($12::text IS NULL OR metadata.pdfVersion = $12::text)
+  AND ($13::bool IS NULL OR metadata.encrypted = $13::bool)
+  AND ($14::timestamp IS NULL OR metadata.created >= $14::timestamp)
+  AND ($15::timestamp IS NULL OR metadata.created < $15::timestamp)
+  AND ($16::int IS NULL OR extractorVersion < $16::int)
+  AND ($17::text IS NULL OR (upload_time, records.id) > (CAST($17::text AS timestamp), $18::int))
+ORDER BY upload_time, records.id
+LIMIT $19::int
+`
+
+type ListRecordsByUploadTimeParams struct {
+	NamePrefix            sql.NullString `json:"name_prefix"`
+	UploadedAfter         sql.NullTime   `json:"uploaded_after"`
+	UploadedBefore        sql.NullTime   `json:"uploaded_before"`
+	MinSize               sql.NullInt64  `json:"min_size"`
+	MaxSize               sql.NullInt64  `json:"max_size"`
+	MinPages              sql.NullInt32  `json:"min_pages"`
+	MaxPages              sql.NullInt32  `json:"max_pages"`
+	Title                 sql.NullString `json:"title"`
+	Author                sql.NullString `json:"author"`
+	Keywords              sql.NullString `json:"keywords"`
+	Producer              sql.NullString `json:"producer"`
+	PdfVersion            sql.NullString `json:"pdf_version"`
+	Encrypted             sql.NullBool   `json:"encrypted"`
+	CreatedAfter          sql.NullTime   `json:"created_after"`
+	CreatedBefore         sql.NullTime   `json:"created_before"`
+	ExtractorVersionBelow sql.NullInt32  `json:"extractor_version_below"`
+	AfterKey              sql.NullString `json:"after_key"`
+	AfterID               int32          `json:"after_id"`
+	PageSize              int32          `json:"page_size"`
+}
+
+func (q *Queries) ListRecordsByUploadTime(ctx context.Context, arg ListRecordsByUploadTimeParams) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listRecordsByUploadTime,
+		arg.NamePrefix,
+		arg.UploadedAfter,
+		arg.UploadedBefore,
+		arg.MinSize,
+		arg.MaxSize,
+		arg.MinPages,
+		arg.MaxPages,
+		arg.Title,
+		arg.Author,
+		arg.Keywords,
+		arg.Producer,
+		arg.PdfVersion,
+		arg.Encrypted,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ExtractorVersionBelow,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordsByUploadTimeDesc = `-- name: ListRecordsByUploadTimeDesc :many
+SELECT records.id, records.name, records.upload_time, records.numofpages, records.size, records.language, records.mimetype, records.objectkey, records.extractor, records.extractorversion FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE ($1::text IS NULL OR name LIKE $1::text || '%')
+  AND ($2::timestamp IS NULL OR upload_time >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR upload_time < $3::timestamp)
+  AND ($4::bigint IS NULL OR size >= $4::bigint)
+  AND ($5::bigint IS NULL OR size <= $5::bigint)
+  AND ($6::int IS NULL OR numOfPages >= $6::int)
+  AND ($7::int IS NULL OR numOfPages <= $7::int)
+  AND ($8::text IS NULL OR metadata.title ILIKE '%' || $8::text || '%')
+  AND ($9::text IS NULL OR metadata.author ILIKE '%' || $9::text || '%')
+  AND ($10::text IS NULL OR metadata.keywords ILIKE '%' || $10::text || '%')
+  AND ($11::text IS NULL OR metadata.producer ILIKE '%' || $11::text || '%')
+  AND ($12::text IS NULL OR metadata.pdfVersion = $12::text)
+  AND ($13::bool IS NULL OR metadata.encrypted = $13::bool)
+  AND ($14::timestamp IS NULL OR metadata.created >= $14::timestamp)
+  AND ($15::timestamp IS NULL OR metadata.created < $15::timestamp)
+  AND ($16::int IS NULL OR extractorVersion < $16::int)
+  AND ($17::text IS NULL OR (upload_time, records.id) < (CAST($17::text AS timestamp), $18::int))
+ORDER BY upload_time DESC, records.id DESC
+LIMIT $19::int
+`
+
+type ListRecordsByUploadTimeDescParams struct {
+	NamePrefix            sql.NullString `json:"name_prefix"`
+	UploadedAfter         sql.NullTime   `json:"uploaded_after"`
+	UploadedBefore        sql.NullTime   `json:"uploaded_before"`
+	MinSize               sql.NullInt64  `json:"min_size"`
+	MaxSize               sql.NullInt64  `json:"max_size"`
+	MinPages              sql.NullInt32  `json:"min_pages"`
+	MaxPages              sql.NullInt32  `json:"max_pages"`
+	Title                 sql.NullString `json:"title"`
+	Author                sql.NullString `json:"author"`
+	Keywords              sql.NullString `json:"keywords"`
+	Producer              sql.NullString `json:"producer"`
+	PdfVersion            sql.NullString `json:"pdf_version"`
+	Encrypted             sql.NullBool   `json:"encrypted"`
+	CreatedAfter          sql.NullTime   `json:"created_after"`
+	CreatedBefore         sql.NullTime   `json:"created_before"`
+	ExtractorVersionBelow sql.NullInt32  `json:"extractor_version_below"`
+	AfterKey              sql.NullString `json:"after_key"`
+	AfterID               int32          `json:"after_id"`
+	PageSize              int32          `json:"page_size"`
+}
+
+func (q *Queries) ListRecordsByUploadTimeDesc(ctx context.Context, arg ListRecordsByUploadTimeDescParams) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listRecordsByUploadTimeDesc,
+		arg.NamePrefix,
+		arg.UploadedAfter,
+		arg.UploadedBefore,
+		arg.MinSize,
+		arg.MaxSize,
+		arg.MinPages,
+		arg.MaxPages,
+		arg.Title,
+		arg.Author,
+		arg.Keywords,
+		arg.Producer,
+		arg.PdfVersion,
+		arg.Encrypted,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.ExtractorVersionBelow,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSentences = `-- name: ListSentences :many
+SELECT id, sentence, pdfid, page, paragraphid FROM sentences
+`
+
+func (q *Queries) ListSentences(ctx context.Context) ([]Sentence, error) {
+	rows, err := q.db.Query(ctx, listSentences)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Sentence
+	for rows.Next() {
+		var i Sentence
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sentence,
+			&i.Pdfid,
+			&i.Page,
+			&i.Paragraphid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSentencesByID = `-- name: ListSentencesByID :many
+SELECT id, sentence, pdfid, page, paragraphid FROM sentences
+WHERE id = ANY($1::int[])
+ORDER BY id
+`
+
+func (q *Queries) ListSentencesByID(ctx context.Context, ids []int32) ([]Sentence, error) {
+	rows, err := q.db.Query(ctx, listSentencesByID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Sentence
+	for rows.Next() {
+		var i Sentence
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sentence,
+			&i.Pdfid,
+			&i.Page,
+			&i.Paragraphid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSummarySentences = `-- name: ListSummarySentences :many
+SELECT s.id, s.sentence, s.pdfid, s.page, t.score
+FROM (
+  SELECT sentenceId, score FROM summary_scores
+  WHERE pdfId = $1
+  ORDER BY score DESC, sentenceId
+  LIMIT $2::int
+) AS t
+JOIN sentences s ON s.id = t.sentenceId
+ORDER BY s.id
+`
+
+type ListSummarySentencesParams struct {
+	Pdfid int32 `json:"pdfid"`
+	Size  int32 `json:"size"`
+}
+
+type ListSummarySentencesRow struct {
+	ID       int32   `json:"id"`
+	Sentence string  `json:"sentence"`
+	Pdfid    int32   `json:"pdfid"`
+	Page     int32   `json:"page"`
+	Score    float64 `json:"score"`
+}
+
+func (q *Queries) ListSummarySentences(ctx context.Context, arg ListSummarySentencesParams) ([]ListSummarySentencesRow, error) {
+	rows, err := q.db.Query(ctx, listSummarySentences, arg.Pdfid, arg.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSummarySentencesRow
+	for rows.Next() {
+		var i ListSummarySentencesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sentence,
+			&i.Pdfid,
+			&i.Page,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTableCells = `-- name: ListTableCells :many
+SELECT tableid, rowindex, columnindex, text FROM table_cells
+WHERE tableId = $1
+ORDER BY rowIndex, columnIndex
+`
+
+func (q *Queries) ListTableCells(ctx context.Context, tableid int32) ([]TableCell, error) {
+	rows, err := q.db.Query(ctx, listTableCells, tableid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TableCell
+	for rows.Next() {
+		var i TableCell
+		if err := rows.Scan(
+			&i.Tableid,
+			&i.Rowindex,
+			&i.Columnindex,
+			&i.Text,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTermDocuments = `-- name: ListTermDocuments :many
+SELECT d.pdfId, d.term, d.count, s.term_count, COALESCE(t.document_count, 0)::int AS document_count, r.language
+FROM document_terms d
+JOIN document_stats s ON s.pdfId = d.pdfId
+JOIN records r ON r.id = d.pdfId
+LEFT JOIN term_stats t ON t.term = d.term
+WHERE d.term = ANY($1::text[])
+`
+
+type ListTermDocumentsRow struct {
+	Pdfid         int32  `json:"pdfid"`
+	Term          string `json:"term"`
+	Count         int32  `json:"count"`
+	TermCount     int32  `json:"term_count"`
+	DocumentCount int32  `json:"document_count"`
+	Language      string `json:"language"`
+}
+
+func (q *Queries) ListTermDocuments(ctx context.Context, terms []string) ([]ListTermDocumentsRow, error) {
+	rows, err := q.db.Query(ctx, listTermDocuments, terms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTermDocumentsRow
+	for rows.Next() {
+		var i ListTermDocumentsRow
+		if err := rows.Scan(
+			&i.Pdfid,
+			&i.Term,
+			&i.Count,
+			&i.TermCount,
+			&i.DocumentCount,
+			&i.Language,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnindexedRecords = `-- name: ListUnindexedRecords :many
+SELECT id, name, upload_time, numofpages, size, language, mimetype, objectkey, extractor, extractorversion FROM records
+WHERE NOT EXISTS (SELECT 1 FROM document_stats WHERE document_stats.pdfId = records.id)
+ORDER BY id
+`
+
+func (q *Queries) ListUnindexedRecords(ctx context.Context) ([]Record, error) {
+	rows, err := q.db.Query(ctx, listUnindexedRecords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Record
+	for rows.Next() {
+		var i Record
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.UploadTime,
+			&i.Numofpages,
+			&i.Size,
+			&i.Language,
+			&i.Mimetype,
+			&i.Objectkey,
+			&i.Extractor,
+			&i.Extractorversion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUploadParts = `-- name: ListUploadParts :many
+SELECT uploadid, number, etag, size FROM upload_parts
+WHERE uploadId = $1
+ORDER BY number
+`
+
+func (q *Queries) ListUploadParts(ctx context.Context, uploadid string) ([]UploadPart, error) {
+	rows, err := q.db.Query(ctx, listUploadParts, uploadid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UploadPart
+	for rows.Next() {
+		var i UploadPart
+		if err := rows.Scan(
+			&i.Uploadid,
+			&i.Number,
+			&i.Etag,
+			&i.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchRecordSentences = `-- name: SearchRecordSentences :many
+SELECT id, sentence, pdfid, page, paragraphid FROM sentences
+WHERE pdfId = $1 AND sentence ~* $2::text
+ORDER BY id
+`
+
+type SearchRecordSentencesParams struct {
+	Pdfid   int32  `json:"pdfid"`
+	Pattern string `json:"pattern"`
+}
+
+func (q *Queries) SearchRecordSentences(ctx context.Context, arg SearchRecordSentencesParams) ([]Sentence, error) {
+	rows, err := q.db.Query(ctx, searchRecordSentences, arg.Pdfid, arg.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Sentence
+	for rows.Next() {
+		var i Sentence
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sentence,
+			&i.Pdfid,
+			&i.Page,
+			&i.Paragraphid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchSentences = `-- name: SearchSentences :many
+SELECT id, sentence, pdfid, page, paragraphid FROM sentences
+WHERE sentence ~* $1::text AND pdfId = ANY($2::int[])
+ORDER BY array_position($2::int[], pdfId), id
+LIMIT $3::int
+`
+
+type SearchSentencesParams struct {
+	Pattern      string  `json:"pattern"`
+	Ids          []int32 `json:"ids"`
+	MaxSentences int32   `json:"max_sentences"`
+}
+
+func (q *Queries) SearchSentences(ctx context.Context, arg SearchSentencesParams) ([]Sentence, error) {
+	rows, err := q.db.Query(ctx, searchSentences, arg.Pattern, arg.Ids, arg.MaxSentences)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Sentence
+	for rows.Next() {
+		var i Sentence
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sentence,
+			&i.Pdfid,
+			&i.Page,
+			&i.Paragraphid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateRecord = `-- name: UpdateRecord :exec
+UPDATE records
+  set name = $2
+WHERE id = $1
+`
+
+type UpdateRecordParams struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (q *Queries) UpdateRecord(ctx context.Context, arg UpdateRecordParams) error {
+	_, err := q.db.Exec(ctx, updateRecord, arg.ID, arg.Name)
+	return err
+}
+
+const updateRecordExtraction = `-- name: UpdateRecordExtraction :one
+UPDATE records
+  set numOfPages = $2, language = $3, extractor = $4, extractorVersion = $5
+WHERE id = $1
+RETURNING id, name, upload_time, numofpages, size, language, mimetype, objectkey, extractor, extractorversion
+`
+
+type UpdateRecordExtractionParams struct {
+	ID               int32  `json:"id"`
+	Numofpages       int32  `json:"numofpages"`
+	Language         string `json:"language"`
+	Extractor        string `json:"extractor"`
+	Extractorversion int32  `json:"extractorversion"`
+}
+
+func (q *Queries) UpdateRecordExtraction(ctx context.Context, arg UpdateRecordExtractionParams) (Record, error) {
+	row := q.db.QueryRow(ctx, updateRecordExtraction,
+		arg.ID,
+		arg.Numofpages,
+		arg.Language,
+		arg.Extractor,
+		arg.Extractorversion,
+	)
+	var i Record
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.UploadTime,
+		&i.Numofpages,
+		&i.Size,
+		&i.Language,
+		&i.Mimetype,
+		&i.Objectkey,
+		&i.Extractor,
+		&i.Extractorversion,
+	)
+	return i, err
+}
+
+const updateRecordLanguage = `-- name: UpdateRecordLanguage :exec
+UPDATE records
+  set language = $2
+WHERE id = $1
+`
+
+type UpdateRecordLanguageParams struct {
+	ID       int32  `json:"id"`
+	Language string `json:"language"`
+}
+
+func (q *Queries) UpdateRecordLanguage(ctx context.Context, arg UpdateRecordLanguageParams) error {
+	_, err := q.db.Exec(ctx, updateRecordLanguage, arg.ID, arg.Language)
+	return err
+}
+
+const updateUploadStatus = `-- name: UpdateUploadStatus :exec
+UPDATE uploads
+  set status = $2, pdfId = $3, errorCode = $4, errorMessage = $5
+WHERE id = $1
+`
+
+type UpdateUploadStatusParams struct {
+	ID           string        `json:"id"`
+	Status       string        `json:"status"`
+	Pdfid        sql.NullInt32 `json:"pdfid"`
+	Errorcode    string        `json:"errorcode"`
+	Errormessage string        `json:"errormessage"`
+}
+
+func (q *Queries) UpdateUploadStatus(ctx context.Context, arg UpdateUploadStatusParams) error {
+	_, err := q.db.Exec(ctx, updateUploadStatus,
+		arg.ID,
+		arg.Status,
+		arg.Pdfid,
+		arg.Errorcode,
+		arg.Errormessage,
+	)
 	return err
 }
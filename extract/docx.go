@@ -0,0 +1,213 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// namespaceWord is the namespace of the WordprocessingML elements
+const namespaceWord = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+
+// DOCX reads the paragraphs and tables of a Word document. The headings are the paragraphs
+// whose style is a heading or has an outline level, pages end at the page breaks written by
+// the author and those Word recorded when it last laid the document out.
+type DOCX struct{}
+
+func (DOCX) Name() string { return NameDOCX }
+
+func (d DOCX) Extract(ctx context.Context, path string) (*Document, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, &Error{Extractor: d.Name(), Kind: ErrUnreadable, Err: err}
+	}
+	defer archive.Close()
+
+	var body, styles []byte
+	for _, entry := range archive.File {
+		switch entry.Name {
+		case "word/document.xml":
+			body, err = readEntry(entry, maxEntrySize)
+		case "word/styles.xml":
+			styles, err = readEntry(entry, maxEntrySize)
+		}
+		if err != nil {
+			return nil, &Error{Extractor: d.Name(), Kind: ErrUnreadable, Err: err}
+		}
+	}
+	if body == nil {
+		return nil, &Error{Extractor: d.Name(), Kind: ErrUnreadable, Err: io.ErrUnexpectedEOF}
+	}
+
+	reader := &docxReader{ctx: ctx, flow: newFlow(d.Name()), levels: headingStyles(styles)}
+	if err := reader.read(body); err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &Error{Extractor: d.Name(), Kind: ErrUnreadable, Err: err}
+	}
+	return reader.flow.finish(), nil
+}
+
+var headingName = regexp.MustCompile(`^(?i)heading\s*(\d)$`)
+
+// headingStyles returns the heading level of the paragraph styles, by style id. A style is a
+// heading when it is named so or has an outline level, the title is level 1.
+func headingStyles(styles []byte) map[string]int {
+	levels := map[string]int{"Title": 1}
+	if styles == nil {
+		return levels
+	}
+	var sheet struct {
+		Styles []struct {
+			ID   string `xml:"styleId,attr"`
+			Name struct {
+				Value string `xml:"val,attr"`
+			} `xml:"name"`
+			Outline *struct {
+				Value int `xml:"val,attr"`
+			} `xml:"pPr>outlineLvl"`
+		} `xml:"style"`
+	}
+	if err := xml.Unmarshal(styles, &sheet); err != nil {
+		return levels
+	}
+	for _, style := range sheet.Styles {
+		switch match := headingName.FindStringSubmatch(style.Name.Value); {
+		case strings.EqualFold(style.Name.Value, "title"):
+			levels[style.ID] = 1
+		case match != nil:
+			levels[style.ID], _ = strconv.Atoi(match[1])
+		case style.Outline != nil && style.Outline.Value < 9:
+			// outline levels start at 0, 9 is body text
+			levels[style.ID] = style.Outline.Value + 1
+		}
+	}
+	return levels
+}
+
+// docxReader walks the body of a document. The paragraphs within a table are the text of their cell.
+type docxReader struct {
+	ctx    context.Context
+	flow   *flow
+	levels map[string]int
+
+	text    strings.Builder
+	heading int
+	// tables holds the rows of the tables being read, the outermost first
+	tables [][][]string
+}
+
+func (r *docxReader) read(body []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != namespaceWord {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				r.heading = 0
+			case "pStyle":
+				r.heading = r.levels[attribute(t, "val")]
+			case "outlineLvl":
+				if level, err := strconv.Atoi(attribute(t, "val")); err == nil && level < 9 {
+					r.heading = level + 1
+				}
+			case "t":
+				var text string
+				if err := decoder.DecodeElement(&text, &t); err != nil {
+					return err
+				}
+				r.text.WriteString(text)
+			case "tab":
+				r.text.WriteString(" ")
+			case "br", "cr":
+				if attribute(t, "type") == "page" && len(r.tables) == 0 {
+					r.pageBreak()
+				} else {
+					r.text.WriteString(" ")
+				}
+			case "lastRenderedPageBreak":
+				if len(r.tables) == 0 {
+					r.pageBreak()
+				}
+			case "tbl":
+				r.tables = append(r.tables, [][]string{})
+			case "tr":
+				if len(r.tables) == 1 {
+					r.tables[0] = append(r.tables[0], []string{})
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Space != namespaceWord {
+				continue
+			}
+			switch t.Name.Local {
+			case "p":
+				if len(r.tables) == 0 {
+					r.end()
+				} else {
+					r.text.WriteString(" ")
+				}
+			case "tc":
+				if len(r.tables) == 1 && len(r.tables[0]) > 0 {
+					rows := r.tables[0]
+					rows[len(rows)-1] = append(rows[len(rows)-1], r.text.String())
+					r.text.Reset()
+				}
+			case "tbl":
+				rows := r.tables[len(r.tables)-1]
+				r.tables = r.tables[:len(r.tables)-1]
+				if len(r.tables) == 0 {
+					r.flow.table(rows)
+				}
+			}
+		}
+	}
+	r.end()
+	return nil
+}
+
+// end adds the paragraph read so far
+func (r *docxReader) end() {
+	if r.heading > 0 {
+		r.flow.heading(r.heading, r.text.String())
+	} else {
+		r.flow.paragraph(r.text.String())
+	}
+	r.text.Reset()
+}
+
+// pageBreak ends the page within a paragraph, its text before the break stays on the page
+func (r *docxReader) pageBreak() {
+	r.end()
+	r.flow.pageBreak()
+}
+
+// attribute returns the value of an attribute of an element, whatever its namespace
+func attribute(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+)
+
+// EPUB reads the chapters of a book in the order of its spine, each one a page of its own
+// read as html.
+type EPUB struct{}
+
+func (EPUB) Name() string { return NameEPUB }
+
+func (e EPUB) Extract(ctx context.Context, file string) (*Document, error) {
+	archive, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, &Error{Extractor: e.Name(), Kind: ErrUnreadable, Err: err}
+	}
+	defer archive.Close()
+
+	entries := make(map[string]*zip.File, len(archive.File))
+	for _, entry := range archive.File {
+		entries[entry.Name] = entry
+	}
+	chapters, err := spine(entries)
+	if err != nil {
+		return nil, &Error{Extractor: e.Name(), Kind: ErrUnreadable, Err: err}
+	}
+
+	f := newFlow(e.Name())
+	for i, chapter := range chapters {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		entry, ok := entries[chapter]
+		if !ok {
+			return nil, &Error{Extractor: e.Name(), Page: i + 1, Kind: ErrPage, Err: fmt.Errorf("missing chapter %s", chapter)}
+		}
+		content, err := readEntry(entry, maxEntrySize)
+		if err == nil {
+			err = readHTML(bytes.NewReader(content), f)
+		}
+		if err != nil {
+			return nil, &Error{Extractor: e.Name(), Page: i + 1, Kind: ErrPage, Err: err}
+		}
+		f.pageBreak()
+	}
+	return f.finish(), nil
+}
+
+// spine returns the path in the archive of the chapters of a book, in reading order
+func spine(entries map[string]*zip.File) ([]string, error) {
+	entry, ok := entries["META-INF/container.xml"]
+	if !ok {
+		return nil, fmt.Errorf("missing META-INF/container.xml")
+	}
+	content, err := readEntry(entry, maxEntrySize)
+	if err != nil {
+		return nil, err
+	}
+	var container struct {
+		Rootfiles []struct {
+			Path      string `xml:"full-path,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(content, &container); err != nil {
+		return nil, err
+	}
+	packagePath := ""
+	for _, rootfile := range container.Rootfiles {
+		if rootfile.MediaType == "application/oebps-package+xml" || packagePath == "" {
+			packagePath = rootfile.Path
+		}
+	}
+	entry, ok = entries[packagePath]
+	if !ok {
+		return nil, fmt.Errorf("missing package document %q", packagePath)
+	}
+	content, err = readEntry(entry, maxEntrySize)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg struct {
+		Items []struct {
+			ID        string `xml:"id,attr"`
+			Href      string `xml:"href,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"manifest>item"`
+		Refs []struct {
+			ID string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := xml.Unmarshal(content, &pkg); err != nil {
+		return nil, err
+	}
+	hrefs := map[string]string{}
+	for _, item := range pkg.Items {
+		// the other items of the spine, such as images, have no text
+		if item.MediaType == "application/xhtml+xml" || item.MediaType == "text/html" {
+			hrefs[item.ID] = item.Href
+		}
+	}
+
+	// the references are relative to the package document
+	base := path.Dir(packagePath)
+	chapters := []string{}
+	for _, ref := range pkg.Refs {
+		href, ok := hrefs[ref.ID]
+		if !ok {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+		chapters = append(chapters, path.Join(base, href))
+	}
+	return chapters, nil
+}
@@ -0,0 +1,148 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/logging"
+	"strings"
+)
+
+// Kinds of extraction failures, matched with errors.Is
+var (
+	ErrUnavailable = errors.New("extractor unavailable")
+	ErrUnreadable  = errors.New("unreadable pdf")
+	ErrEncrypted   = errors.New("encrypted pdf")
+	ErrPage        = errors.New("page extraction failed")
+)
+
+// Error is a failed extraction. Page is 0 when the whole file failed.
+type Error struct {
+	Extractor string
+	Page      int
+	Kind      error
+	Err       error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Extractor)
+	if e.Page > 0 {
+		fmt.Fprintf(&b, ": page %d", e.Page)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Kind.Error())
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func (e *Error) Is(target error) bool { return target == e.Kind }
+
+// Document is the text of every page of a file and the extractor that read it. Blocks, in reading
+// order, and Tables are only set by the extractors analyzing the layout or reading a structured format.
+type Document struct {
+	Extractor string
+	Pages     []string
+	Blocks    []Block
+	Tables    []Table
+}
+
+// Extractor reads the text of a file, one string per page
+type Extractor interface {
+	Name() string
+	Extract(ctx context.Context, path string) (*Document, error)
+}
+
+// Names of the extractors accepted by New
+const (
+	NamePdftotext = "pdftotext"
+	NameFitz      = "fitz"
+	NameNative    = "native"
+	NameLayout    = "layout"
+)
+
+// Default needs no external program, pdftotext has to be asked for. So does the layout analysis,
+// in front of the plain text extractors reading the files it cannot.
+var Default Extractor = Chain{Fitz{}, Native{}}
+
+// New returns the named extractors, tried in order until one succeeds, Default without names
+func New(names ...string) (Extractor, error) {
+	if len(names) == 0 {
+		return Default, nil
+	}
+
+	chain := Chain{}
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case NamePdftotext:
+			chain = append(chain, Pdftotext{})
+		case NameFitz:
+			chain = append(chain, Fitz{})
+		case NameNative:
+			chain = append(chain, Native{})
+		case NameLayout:
+			chain = append(chain, Layout{})
+		default:
+			return nil, fmt.Errorf("unknown text extractor %q", name)
+		}
+	}
+	if len(chain) == 1 {
+		return chain[0], nil
+	}
+	return chain, nil
+}
+
+// Chain falls back on the next extractor whenever one fails
+type Chain []Extractor
+
+func (c Chain) Name() string {
+	names := make([]string, len(c))
+	for i, extractor := range c {
+		names[i] = extractor.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (c Chain) Extract(ctx context.Context, path string) (*Document, error) {
+	failures := &FallbackError{}
+	for _, extractor := range c {
+		document, err := extractor.Extract(ctx, path)
+		if err == nil {
+			return document, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		logging.FromContext(ctx).WithError(err).WithField("extractor", extractor.Name()).Warn("text extraction failed, falling back")
+		failures.Errors = append(failures.Errors, err)
+	}
+	return nil, failures
+}
+
+// FallbackError holds the failure of every extractor of a chain
+type FallbackError struct {
+	Errors []error
+}
+
+func (e *FallbackError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return "every extractor failed: " + strings.Join(messages, "; ")
+}
+
+// Is reports whether any extractor failed with target
+func (e *FallbackError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// stub is an extractor returning the same document or error, counting its calls
+type stub struct {
+	name     string
+	document *Document
+	err      error
+	calls    int
+}
+
+func (s *stub) Name() string { return s.name }
+
+func (s *stub) Extract(ctx context.Context, path string) (*Document, error) {
+	s.calls++
+	return s.document, s.err
+}
+
+// TestChain falls back on the next extractor until one succeeds and reports every failure
+// when none does
+func TestChain(t *testing.T) {
+	encrypted := &Error{Extractor: "first", Kind: ErrEncrypted}
+	unreadable := &Error{Extractor: "second", Kind: ErrUnreadable, Err: errors.New("bad xref")}
+	document := &Document{Extractor: "third", Pages: []string{"text"}}
+
+	first := &stub{name: "first", err: encrypted}
+	second := &stub{name: "second", err: unreadable}
+	third := &stub{name: "third", document: document}
+	got, err := Chain{first, second, third}.Extract(context.Background(), "file.pdf")
+	if err != nil || got != document {
+		t.Fatalf("got %v, %v, want the document of the third extractor", got, err)
+	}
+
+	fourth := &stub{name: "fourth", document: document}
+	if _, err := (Chain{third, fourth}).Extract(context.Background(), "file.pdf"); err != nil || fourth.calls != 0 {
+		t.Errorf("got %v and %d calls after a success, want no fallback", err, fourth.calls)
+	}
+
+	_, err = Chain{first, second}.Extract(context.Background(), "file.pdf")
+	var fallback *FallbackError
+	if !errors.As(err, &fallback) {
+		t.Fatalf("got %v, want a FallbackError", err)
+	}
+	if want := []error{encrypted, unreadable}; !reflect.DeepEqual(fallback.Errors, want) {
+		t.Errorf("got errors %v, want %v", fallback.Errors, want)
+	}
+	for _, kind := range []error{ErrEncrypted, ErrUnreadable} {
+		if !errors.Is(err, kind) {
+			t.Errorf("errors.Is(%v, %v) = false, want true", err, kind)
+		}
+	}
+	if errors.Is(err, ErrPage) {
+		t.Errorf("errors.Is(%v, ErrPage) = true, want false", err)
+	}
+	want := "every extractor failed: first: encrypted pdf; second: unreadable pdf: bad xref"
+	if err.Error() != want {
+		t.Errorf("got message %q, want %q", err.Error(), want)
+	}
+}
+
+// TestChainCanceled stops at the first failure once the context is done
+func TestChainCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	first := &stub{name: "first", err: context.Canceled}
+	second := &stub{name: "second", document: &Document{}}
+	if _, err := (Chain{first, second}).Extract(ctx, "file.pdf"); !errors.Is(err, context.Canceled) || second.calls != 0 {
+		t.Errorf("got %v and %d calls of the next extractor, want context.Canceled and none", err, second.calls)
+	}
+}
+
+// TestErrorMessage names the extractor, the page when a single page failed and the cause
+func TestErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want string
+	}{
+		{&Error{Extractor: "fitz", Kind: ErrUnreadable}, "fitz: unreadable pdf"},
+		{&Error{Extractor: "native", Page: 3, Kind: ErrPage, Err: errors.New("bad font")}, "native: page 3: page extraction failed: bad font"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// TestNew chains the named extractors in order and rejects the unknown ones
+func TestNew(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+		err   bool
+	}{
+		{nil, Default.Name(), false},
+		{[]string{"pdftotext"}, "pdftotext", false},
+		{[]string{"fitz", " native"}, "fitz,native", false},
+		{[]string{"fitz", "ocr"}, "", true},
+	}
+	for _, tt := range tests {
+		extractor, err := New(tt.names...)
+		if (err != nil) != tt.err {
+			t.Errorf("New(%q): got error %v", tt.names, err)
+			continue
+		}
+		if err == nil && extractor.Name() != tt.want {
+			t.Errorf("New(%q): got %q, want %q", tt.names, extractor.Name(), tt.want)
+		}
+	}
+}
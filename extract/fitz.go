@@ -0,0 +1,37 @@
+package extract
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gen2brain/go-fitz"
+)
+
+// Fitz reads the text with MuPDF, linked in for rendering the pages
+type Fitz struct{}
+
+func (Fitz) Name() string { return NameFitz }
+
+func (f Fitz) Extract(ctx context.Context, path string) (*Document, error) {
+	doc, err := fitz.New(path)
+	if err != nil {
+		kind := ErrUnreadable
+		if errors.Is(err, fitz.ErrNeedsPassword) {
+			kind = ErrEncrypted
+		}
+		return nil, &Error{Extractor: f.Name(), Kind: kind, Err: err}
+	}
+	defer doc.Close()
+
+	pages := make([]string, doc.NumPage())
+	for i := range pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pages[i], err = doc.Text(i)
+		if err != nil {
+			return nil, &Error{Extractor: f.Name(), Page: i + 1, Kind: ErrPage, Err: err}
+		}
+	}
+	return &Document{Extractor: f.Name(), Pages: pages}, nil
+}
@@ -0,0 +1,297 @@
+package extract
+
+import (
+	"archive/zip"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// MIME types of the formats read, as detected by Sniff
+const (
+	MIMEPDF      = "application/pdf"
+	MIMEText     = "text/plain"
+	MIMEMarkdown = "text/markdown"
+	MIMEHTML     = "text/html"
+	MIMEDOCX     = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MIMEEPUB     = "application/epub+zip"
+)
+
+// extensions are those of the formats read, the copies of the files are named with them for MuPDF
+var extensions = map[string]string{
+	MIMEPDF:      ".pdf",
+	MIMEText:     ".txt",
+	MIMEMarkdown: ".md",
+	MIMEHTML:     ".html",
+	MIMEDOCX:     ".docx",
+	MIMEEPUB:     ".epub",
+}
+
+// Extension returns the usual extension of a format, none for the formats not read
+func Extension(mimeType string) string {
+	return extensions[mimeType]
+}
+
+// ErrUnsupportedFormat is the kind of the files no extractor is registered for
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// Registry maps the MIME type of every format read to its extractor
+type Registry map[string]Extractor
+
+// Formats returns the registry of every supported format, the pdfs read by pdf
+func Formats(pdf Extractor) Registry {
+	return Registry{
+		MIMEPDF:      pdf,
+		MIMEText:     Text{},
+		MIMEMarkdown: Markdown{},
+		MIMEHTML:     HTML{},
+		MIMEDOCX:     DOCX{},
+		MIMEEPUB:     EPUB{},
+	}
+}
+
+// Register sets the extractor of a format, replacing the previous one
+func (r Registry) Register(mimeType string, extractor Extractor) {
+	r[mimeType] = extractor
+}
+
+// Lookup returns the extractor of a format, an error matching ErrUnsupportedFormat when there is none
+func (r Registry) Lookup(mimeType string) (Extractor, error) {
+	extractor, ok := r[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, mimeType)
+	}
+	return extractor, nil
+}
+
+// sniffLength is the number of bytes read to detect a format, as many as http.DetectContentType considers
+const sniffLength = 512
+
+// markdownSample is the number of bytes of a text file searched for Markdown syntax
+const markdownSample = 64 << 10
+
+// Sniff detects the format of a file from its content, whatever its name. The zip archives are
+// told apart by their entries and the Markdown files by their syntax. Formats that are not read
+// are returned as detected, without their parameters, except for text in another charset than UTF-8.
+func Sniff(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	detected := http.DetectContentType(head[:n])
+	mediaType, params, err := mime.ParseMediaType(detected)
+	if err != nil {
+		return detected, nil
+	}
+
+	switch mediaType {
+	case "application/zip":
+		return sniffZip(file)
+	case "text/xml":
+		// XHTML, as in EPUB chapters, starts with an XML declaration
+		if strings.Contains(strings.ToLower(string(head[:n])), "<html") {
+			return MIMEHTML, nil
+		}
+	case MIMEText:
+		if charset := params["charset"]; charset != "utf-8" {
+			return detected, nil
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		if isMarkdown(io.LimitReader(file, markdownSample)) {
+			return MIMEMarkdown, nil
+		}
+	}
+	return mediaType, nil
+}
+
+// sniffZip tells EPUB books and DOCX documents from the other zip archives
+func sniffZip(file *os.File) (string, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return "application/zip", nil
+	}
+	for _, entry := range archive.File {
+		switch entry.Name {
+		case "mimetype":
+			content, err := readEntry(entry, int64(len(MIMEEPUB)))
+			if err == nil && strings.TrimSpace(string(content)) == MIMEEPUB {
+				return MIMEEPUB, nil
+			}
+		case "word/document.xml":
+			return MIMEDOCX, nil
+		}
+	}
+	return "application/zip", nil
+}
+
+var markdownPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^#{1,6}\s+\S`),
+	regexp.MustCompile("^(```|~~~)"),
+	regexp.MustCompile(`^\s{0,3}[-*+]\s+\S`),
+	regexp.MustCompile(`^\s{0,3}\d+[.)]\s+\S`),
+	regexp.MustCompile(`^>\s`),
+	regexp.MustCompile(`\[[^\]]+\]\([^)\s]+\)`),
+	regexp.MustCompile(`^(=+|-+)\s*$`),
+}
+
+// minMarkdownLines is the number of lines with Markdown syntax that makes a text file Markdown
+const minMarkdownLines = 2
+
+// isMarkdown reports whether a text uses the syntax of Markdown on several of its lines
+func isMarkdown(r io.Reader) bool {
+	scanner := bufio.NewScanner(r)
+	matched := 0
+	for scanner.Scan() {
+		for _, pattern := range markdownPatterns {
+			if pattern.MatchString(scanner.Text()) {
+				matched++
+				break
+			}
+		}
+		if matched >= minMarkdownLines {
+			return true
+		}
+	}
+	return false
+}
+
+// maxEntrySize is the size over which an entry of an archive is not read, against zip bombs
+const maxEntrySize = 64 << 20
+
+// readEntry reads an entry of a zip archive up to limit bytes
+func readEntry(entry *zip.File, limit int64) ([]byte, error) {
+	r, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("%s is larger than %d bytes", entry.Name, limit)
+	}
+	return content, nil
+}
+
+// flow builds the document of a format without a layout. Its blocks come in reading order without
+// a bounding box and a page ends at every page break of the file.
+type flow struct {
+	document *Document
+	page     int
+	filled   bool
+}
+
+func newFlow(extractor string) *flow {
+	return &flow{document: &Document{Extractor: extractor, Blocks: []Block{}, Tables: []Table{}}, page: 1}
+}
+
+// heading adds a heading, the levels past the last one HTML knows are kept at the last one
+func (f *flow) heading(level int, text string) {
+	if level > maxHeadingLevel {
+		level = maxHeadingLevel
+	}
+	f.add(level, text)
+}
+
+func (f *flow) paragraph(text string) {
+	f.add(0, text)
+}
+
+func (f *flow) add(heading int, text string) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return
+	}
+	f.document.Blocks = append(f.document.Blocks, Block{Page: f.page, Text: text, Heading: heading})
+	f.filled = true
+}
+
+// table adds a table, its rows padded to the same number of cells. Those with a single row or
+// column are only layout, their cells become paragraphs.
+func (f *flow) table(rows [][]string) {
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if columns == 0 {
+		return
+	}
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		cells[i] = make([]string, columns)
+		for j, text := range row {
+			cells[i][j] = strings.Join(strings.Fields(text), " ")
+		}
+	}
+
+	table, ok := newTable(f.page, Box{}, cells)
+	if !ok {
+		for _, row := range cells {
+			for _, text := range row {
+				f.paragraph(text)
+			}
+		}
+		return
+	}
+	f.document.Tables = append(f.document.Tables, table)
+	f.filled = true
+}
+
+// pageBreak starts a new page, unless nothing was added to the current one
+func (f *flow) pageBreak() {
+	if f.filled {
+		f.page++
+		f.filled = false
+	}
+}
+
+// finish returns the document with the text of its pages
+func (f *flow) finish() *Document {
+	pages := f.page
+	if !f.filled && pages > 1 {
+		pages--
+	}
+	f.document.Pages = make([]string, pages)
+	joinPages(f.document)
+	return f.document
+}
+
+// joinPages sets the text of every page to its blocks, one per line, followed by the rows of its tables
+func joinPages(document *Document) {
+	texts := make([][]string, len(document.Pages))
+	for _, block := range document.Blocks {
+		texts[block.Page-1] = append(texts[block.Page-1], block.Text)
+	}
+	// the cells stay searchable, row by row after the text of their page
+	for _, table := range document.Tables {
+		for _, row := range table.Rows {
+			texts[table.Page-1] = append(texts[table.Page-1], strings.Join(row, " "))
+		}
+	}
+	for i := range document.Pages {
+		document.Pages[i] = strings.Join(texts[i], "\n")
+	}
+}
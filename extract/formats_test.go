@@ -0,0 +1,100 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// archive returns a zip archive of the entries, names and contents, in order
+func archive(t *testing.T, entries ...[2]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		f, err := w.Create(entry[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(entry[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestSniff checks the formats detected from the content of the files, whatever their names
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{"pdf", []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\n"), MIMEPDF},
+		{"text", []byte("Just a few plain sentences.\nNothing more to it.\n"), MIMEText},
+		{"markdown", []byte("# Title\n\nSome text.\n\n- a point\n- another\n"), MIMEMarkdown},
+		{"html", []byte("<!DOCTYPE html><html><body><p>Hi</p></body></html>"), MIMEHTML},
+		{"xhtml", []byte(`<?xml version="1.0" encoding="utf-8"?><html xmlns="http://www.w3.org/1999/xhtml"></html>`), MIMEHTML},
+		{"other xml", []byte(`<?xml version="1.0"?><note></note>`), "text/xml"},
+		{"epub", archive(t, [2]string{"mimetype", MIMEEPUB}, [2]string{"META-INF/container.xml", "<container/>"}), MIMEEPUB},
+		{"docx", archive(t, [2]string{"[Content_Types].xml", "<Types/>"}, [2]string{"word/document.xml", "<document/>"}), MIMEDOCX},
+		{"zip", archive(t, [2]string{"notes.txt", "hello"}), "application/zip"},
+		{"other charset", []byte("\xff\xfeh\x00i\x00"), "text/plain; charset=utf-16le"},
+		{"empty", []byte{}, MIMEText},
+		{"binary", []byte{0x00, 0x01, 0x02, 0x03, 0xfe}, "application/octet-stream"},
+	}
+	dir := t.TempDir()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the names say nothing of the formats
+			path := filepath.Join(dir, strings.ReplaceAll(test.name, " ", "_")+".bin")
+			if err := os.WriteFile(path, test.content, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Sniff(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	if _, err := Sniff(filepath.Join(dir, "missing")); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
+
+// TestIsMarkdown checks the Markdown syntax recognized and the number of lines needed
+func TestIsMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"headings", "# Title\n## Section\n", true},
+		{"setext heading", "Title\n=====\nText with a [link](https://example.com).\n", true},
+		{"lists", "- one\n* two\n+ three\n", true},
+		{"ordered list", "1. first\n2) second\n", true},
+		{"code fence and quote", "```go\nx := 1\n```\n", true},
+		{"quote", "> quoted\n> again\n", true},
+		{"single line", "# Title\nthen plain prose only.\n", false},
+		{"plain text", "Dear reader,\nthis is a letter.\n", false},
+		{"hashtag", "#hashtag\n#another\n", false},
+		{"too deep", "        - indented code\n        - more code\n", false},
+		{"empty", "", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isMarkdown(strings.NewReader(test.text)); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package extract
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// HTML reads the headings, paragraphs and tables of a web page, in their charset. The page
+// is a single one, the text of its scripts and styles is left out.
+type HTML struct{}
+
+func (HTML) Name() string { return NameHTML }
+
+func (h HTML) Extract(ctx context.Context, path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &Error{Extractor: h.Name(), Kind: ErrUnreadable, Err: err}
+	}
+	defer file.Close()
+
+	f := newFlow(h.Name())
+	if err := readHTML(file, f); err != nil {
+		return nil, &Error{Extractor: h.Name(), Kind: ErrUnreadable, Err: err}
+	}
+	return f.finish(), nil
+}
+
+// readHTML adds the blocks of an html document to a flow
+func readHTML(r io.Reader, f *flow) error {
+	decoded, err := charset.NewReader(r, "text/html")
+	if err != nil {
+		return err
+	}
+	root, err := html.Parse(decoded)
+	if err != nil {
+		return err
+	}
+	walker := &htmlWalker{flow: f}
+	walker.walk(root)
+	walker.end()
+	return nil
+}
+
+// htmlWalker gathers the inline text of the elements until a block ends it
+type htmlWalker struct {
+	flow *flow
+	text strings.Builder
+}
+
+func (w *htmlWalker) end() {
+	w.flow.paragraph(w.text.String())
+	w.text.Reset()
+}
+
+func (w *htmlWalker) walk(node *html.Node) {
+	switch node.Type {
+	case html.TextNode:
+		w.text.WriteString(node.Data)
+		return
+	case html.ElementNode:
+	default:
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			w.walk(child)
+		}
+		return
+	}
+
+	switch node.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg, atom.Math:
+		return
+	case atom.Br:
+		w.text.WriteString(" ")
+		return
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		w.end()
+		w.flow.heading(int(node.Data[1]-'0'), htmlText(node))
+		return
+	case atom.Table:
+		w.end()
+		w.flow.table(htmlRows(node))
+		return
+	}
+
+	block := isBlock(node.DataAtom)
+	if block {
+		w.end()
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		w.walk(child)
+	}
+	if block {
+		w.end()
+	}
+}
+
+// isBlock reports whether an element starts a paragraph of its own
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.Address, atom.Article, atom.Aside, atom.Blockquote, atom.Body, atom.Caption, atom.Dd,
+		atom.Details, atom.Dialog, atom.Div, atom.Dl, atom.Dt, atom.Fieldset, atom.Figcaption, atom.Figure,
+		atom.Footer, atom.Form, atom.Header, atom.Hr, atom.Li, atom.Main, atom.Nav, atom.Ol, atom.P,
+		atom.Pre, atom.Section, atom.Summary, atom.Ul:
+		return true
+	}
+	return false
+}
+
+// htmlText returns the text of an element and its descendants
+func htmlText(node *html.Node) string {
+	var text strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			text.WriteString(n.Data)
+			return
+		case n.Type != html.ElementNode:
+		case n.DataAtom == atom.Script || n.DataAtom == atom.Style:
+			return
+		case n.DataAtom == atom.Br || isBlock(n.DataAtom):
+			text.WriteString(" ")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+		if n.Type == html.ElementNode && (isBlock(n.DataAtom) || n.DataAtom == atom.Td || n.DataAtom == atom.Th) {
+			text.WriteString(" ")
+		}
+	}
+	collect(node)
+	return text.String()
+}
+
+// htmlRows returns the text of the cells of a table row by row, the cells of nested tables
+// taken as the text of the cell that holds them
+func htmlRows(table *html.Node) [][]string {
+	rows := [][]string{}
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				collect(child)
+			case atom.Tr:
+				row := []string{}
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						row = append(row, htmlText(cell))
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	collect(table)
+	return rows
+}
@@ -0,0 +1,82 @@
+package extract
+
+import (
+	"context"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Inspection is what the structure of a pdf tells before its text is read
+type Inspection struct {
+	Pages     int
+	Encrypted bool
+	// JavaScript is set when an action, a name tree or a form field of the file carries a script
+	JavaScript bool
+}
+
+// Inspect reads and validates the structure of a pdf with pdfcpu. Files it cannot parse return an
+// *Error of kind ErrUnreadable, those that cannot be opened without a password one of kind ErrEncrypted.
+func Inspect(ctx context.Context, path string) (*Inspection, error) {
+	pdf, err := readStructure(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := pdf.EnsurePageCount(); err != nil {
+		return nil, &Error{Extractor: NameMetadata, Kind: ErrUnreadable, Err: err}
+	}
+
+	inspection := &Inspection{Pages: pdf.PageCount, Encrypted: pdf.Encrypt != nil}
+	for _, entry := range pdf.Table {
+		if entry == nil || entry.Free {
+			continue
+		}
+		if hasJavaScript(entry.Object, 0) {
+			inspection.JavaScript = true
+			break
+		}
+	}
+	return inspection, nil
+}
+
+// maxObjectDepth bounds the walk of the direct objects nested in an object
+const maxObjectDepth = 32
+
+// hasJavaScript reports whether an object, or a direct object nested in it, is a JavaScript
+// action or the JavaScript name tree. Indirect objects are checked as entries of the table.
+func hasJavaScript(object types.Object, depth int) bool {
+	if depth > maxObjectDepth {
+		return false
+	}
+	switch o := object.(type) {
+	case types.Dict:
+		return dictJavaScript(o, depth)
+	case types.StreamDict:
+		return dictJavaScript(o.Dict, depth)
+	case types.Array:
+		for _, element := range o {
+			if hasJavaScript(element, depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func dictJavaScript(d types.Dict, depth int) bool {
+	if s := d.NameEntry("S"); s != nil && *s == "JavaScript" {
+		return true
+	}
+	if _, ok := d.Find("JS"); ok {
+		return true
+	}
+	// the scripts run when the file opens are named in the JavaScript entry of the names dictionary
+	if _, ok := d.Find("JavaScript"); ok {
+		return true
+	}
+	for _, value := range d {
+		if hasJavaScript(value, depth+1) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,301 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gen2brain/go-fitz"
+)
+
+// Heading detection, relative to the font size of the body text
+const (
+	headingRatio    = 1.15
+	maxHeadingRunes = 200
+	maxHeadingLines = 3
+	maxHeadingLevel = 6
+)
+
+// Layout analysis thresholds, relative to the line height
+const (
+	// paragraphGap is the vertical space between two lines that starts a new paragraph
+	paragraphGap = 0.6
+	// fontSizeTolerance is the difference, in points, under which two lines share a font size
+	fontSizeTolerance = 0.5
+	// minColumnLines is the number of side by side lines that makes a page two columns
+	minColumnLines = 3
+	// averageGlyphWidth estimates the width of a line from its length, MuPDF does not report it
+	averageGlyphWidth = 0.5
+)
+
+// Box is the bounding box of a block in points, from the top left corner of the page. X1 is
+// estimated from the length of the lines.
+type Box struct {
+	X0, Y0, X1, Y1 float64
+}
+
+// Block is a paragraph or a heading of a page. Heading is the level of a heading, from 1, and 0 for body text.
+type Block struct {
+	Page     int
+	Text     string
+	Heading  int
+	FontSize float64
+	Box      Box
+	lines    int
+}
+
+// line is one line of text of a page as positioned by MuPDF
+type line struct {
+	text                           string
+	left, right, top, height, size float64
+}
+
+func (l line) bottom() float64 { return l.top + l.height }
+
+var (
+	pagePattern = regexp.MustCompile(`<div id="page\d+" style="width:([\d.]+)pt;height:([\d.]+)pt">`)
+	linePattern = regexp.MustCompile(`<p style="top:([\d.-]+)pt;left:([\d.-]+)pt;line-height:([\d.]+)pt">(.*?)</p>`)
+	spanPattern = regexp.MustCompile(`<span style="[^"]*font-size:([\d.]+)pt[^"]*">(.*?)</span>`)
+	tagPattern  = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Layout reads the positioned lines of MuPDF, restores the reading order of two column pages,
+// groups the lines into paragraphs, joins the words hyphenated across lines and tells the
+// headings apart by their font size. The tables are set apart from the paragraphs. The text of
+// every page is its blocks, one per line, followed by the rows of its tables.
+type Layout struct{}
+
+func (Layout) Name() string { return NameLayout }
+
+func (l Layout) Extract(ctx context.Context, path string) (*Document, error) {
+	doc, err := fitz.New(path)
+	if err != nil {
+		kind := ErrUnreadable
+		if errors.Is(err, fitz.ErrNeedsPassword) {
+			kind = ErrEncrypted
+		}
+		return nil, &Error{Extractor: l.Name(), Kind: kind, Err: err}
+	}
+	defer doc.Close()
+
+	document := &Document{Extractor: l.Name(), Pages: make([]string, doc.NumPage()), Blocks: []Block{}, Tables: []Table{}}
+	for i := range document.Pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		markup, err := doc.HTML(i, false)
+		if err != nil {
+			return nil, &Error{Extractor: l.Name(), Page: i + 1, Kind: ErrPage, Err: err}
+		}
+		svg, err := doc.SVG(i)
+		if err != nil {
+			return nil, &Error{Extractor: l.Name(), Page: i + 1, Kind: ErrPage, Err: err}
+		}
+
+		lines, width := parseLines(markup)
+		tables, rest := pageTables(i+1, lines, parseRules(svg))
+		document.Tables = append(document.Tables, tables...)
+		document.Blocks = append(document.Blocks, pageBlocks(i+1, rest, width)...)
+	}
+	markHeadings(document.Blocks)
+
+	joinPages(document)
+	return document, nil
+}
+
+// parseLines reads the lines and the width of a page from the html of MuPDF
+func parseLines(markup string) ([]line, float64) {
+	width := 0.0
+	if match := pagePattern.FindStringSubmatch(markup); match != nil {
+		width, _ = strconv.ParseFloat(match[1], 64)
+	}
+
+	lines := []line{}
+	for _, match := range linePattern.FindAllStringSubmatch(markup, -1) {
+		l := line{}
+		l.top, _ = strconv.ParseFloat(match[1], 64)
+		l.left, _ = strconv.ParseFloat(match[2], 64)
+		l.height, _ = strconv.ParseFloat(match[3], 64)
+
+		var text strings.Builder
+		runes := 0
+		for _, span := range spanPattern.FindAllStringSubmatch(match[4], -1) {
+			size, _ := strconv.ParseFloat(span[1], 64)
+			content := html.UnescapeString(tagPattern.ReplaceAllString(span[2], ""))
+			count := utf8.RuneCountInString(strings.TrimSpace(content))
+			// the line takes the size of most of its characters
+			if count > runes {
+				l.size, runes = size, count
+			}
+			text.WriteString(content)
+		}
+		l.text = strings.TrimSpace(text.String())
+		if l.text == "" {
+			continue
+		}
+		l.right = l.left + float64(utf8.RuneCountInString(l.text))*l.size*averageGlyphWidth
+		if width > 0 && l.right > width {
+			l.right = width
+		}
+		lines = append(lines, l)
+	}
+	return lines, width
+}
+
+// readingOrder sorts the lines top to bottom. On two column pages the lines above the columns
+// come first, then the left column, then the right one.
+func readingOrder(lines []line, width float64) []line {
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].top != lines[j].top {
+			return lines[i].top < lines[j].top
+		}
+		return lines[i].left < lines[j].left
+	})
+	if width == 0 {
+		return lines
+	}
+
+	middle := width / 2
+	sideBySide := 0
+	columnsTop := math.Inf(1)
+	for _, right := range lines {
+		if right.left < middle {
+			continue
+		}
+		for _, left := range lines {
+			if left.left < middle && left.top < right.bottom() && right.top < left.bottom() {
+				sideBySide++
+				columnsTop = math.Min(columnsTop, right.top)
+				break
+			}
+		}
+	}
+	if sideBySide < minColumnLines {
+		return lines
+	}
+
+	header, left, right := []line{}, []line{}, []line{}
+	for _, l := range lines {
+		switch {
+		case l.left >= middle:
+			right = append(right, l)
+		case l.bottom() <= columnsTop:
+			header = append(header, l)
+		default:
+			left = append(left, l)
+		}
+	}
+	return append(append(header, left...), right...)
+}
+
+// pageBlocks groups the lines of a page into paragraphs
+func pageBlocks(page int, lines []line, width float64) []Block {
+	lines = readingOrder(lines, width)
+
+	blocks := []Block{}
+	var previous line
+	for i, l := range lines {
+		if i > 0 && sameBlock(previous, l) {
+			current := &blocks[len(blocks)-1]
+			current.Text = joinLines(current.Text, l.text)
+			current.lines++
+			current.Box.X0 = math.Min(current.Box.X0, l.left)
+			current.Box.X1 = math.Max(current.Box.X1, l.right)
+			current.Box.Y1 = math.Max(current.Box.Y1, l.bottom())
+		} else {
+			blocks = append(blocks, Block{
+				Page:     page,
+				Text:     l.text,
+				FontSize: l.size,
+				Box:      Box{X0: l.left, Y0: l.top, X1: l.right, Y1: l.bottom()},
+				lines:    1,
+			})
+		}
+		previous = l
+	}
+	return blocks
+}
+
+// sameBlock reports whether next continues the paragraph of prev: it follows it closely, below, in the same font size
+func sameBlock(prev, next line) bool {
+	if math.Abs(prev.size-next.size) > fontSizeTolerance {
+		return false
+	}
+	gap := next.top - prev.bottom()
+	return next.top >= prev.top && gap <= paragraphGap*prev.height
+}
+
+// joinLines appends a line to a paragraph, joining the words hyphenated at the end of the line
+func joinLines(text, next string) string {
+	trimmed := strings.TrimRight(text, " ")
+	last, size := utf8.DecodeLastRuneInString(trimmed)
+	if last == '-' || last == '\u00ad' {
+		before, _ := utf8.DecodeLastRuneInString(trimmed[:len(trimmed)-size])
+		first, _ := utf8.DecodeRuneInString(next)
+		if unicode.IsLetter(before) && unicode.IsLower(first) {
+			return trimmed[:len(trimmed)-size] + next
+		}
+	}
+	return trimmed + " " + next
+}
+
+// markHeadings sets the level of the short blocks set larger than the body text, the
+// largest being level 1. The body text has the font size of most characters.
+func markHeadings(blocks []Block) {
+	characters := map[float64]int{}
+	for _, block := range blocks {
+		characters[roundSize(block.FontSize)] += utf8.RuneCountInString(block.Text)
+	}
+	body, most := 0.0, 0
+	for size, count := range characters {
+		if count > most || (count == most && size < body) {
+			body, most = size, count
+		}
+	}
+	if body == 0 {
+		return
+	}
+
+	sizes := []float64{}
+	seen := map[float64]bool{}
+	for i := range blocks {
+		if !isHeading(blocks[i], body) {
+			continue
+		}
+		size := roundSize(blocks[i].FontSize)
+		if !seen[size] {
+			seen[size] = true
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(sizes)))
+
+	for i := range blocks {
+		if !isHeading(blocks[i], body) {
+			continue
+		}
+		level := sort.Search(len(sizes), func(j int) bool { return sizes[j] <= roundSize(blocks[i].FontSize) }) + 1
+		if level > maxHeadingLevel {
+			level = maxHeadingLevel
+		}
+		blocks[i].Heading = level
+	}
+}
+
+func isHeading(block Block, body float64) bool {
+	return roundSize(block.FontSize) >= body*headingRatio &&
+		utf8.RuneCountInString(block.Text) <= maxHeadingRunes &&
+		block.lines <= maxHeadingLines
+}
+
+// roundSize rounds a font size to half a point so that slightly different sizes count as one
+func roundSize(size float64) float64 {
+	return math.Round(size*2) / 2
+}
@@ -0,0 +1,177 @@
+package extract
+
+import (
+	"testing"
+)
+
+// page is the html MuPDF writes for a page of the given lines
+func page(lines ...string) string {
+	markup := `<div id="page0" style="width:600.0pt;height:800.0pt">`
+	for _, l := range lines {
+		markup += l
+	}
+	return markup + `</div>`
+}
+
+// TestParseLines checks the positions, the font size of most characters and the entities of the html of MuPDF
+func TestParseLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		markup string
+		want   []line
+	}{
+		{
+			"one line",
+			page(`<p style="top:72.5pt;left:36.0pt;line-height:12.0pt"><span style="font-family:Times,serif;font-size:10.0pt;color:#000000">Hello &amp; welcome</span></p>`),
+			[]line{{text: "Hello & welcome", left: 36, top: 72.5, height: 12, size: 10, right: 36 + 15*10*averageGlyphWidth}},
+		},
+		{
+			"mixed sizes",
+			page(`<p style="top:10pt;left:20pt;line-height:14pt"><span style="font-size:18.0pt">A</span><span style="font-size:12.0pt"> longer tail</span></p>`),
+			[]line{{text: "A longer tail", left: 20, top: 10, height: 14, size: 12, right: 20 + 13*12*averageGlyphWidth}},
+		},
+		{
+			"nested tags",
+			page(`<p style="top:10pt;left:20pt;line-height:14pt"><span style="font-size:10pt"><b>bold</b> text</span></p>`),
+			[]line{{text: "bold text", left: 20, top: 10, height: 14, size: 10, right: 20 + 9*10*averageGlyphWidth}},
+		},
+		{
+			"clipped to the page",
+			page(`<p style="top:10pt;left:580pt;line-height:14pt"><span style="font-size:10pt">runs past the edge</span></p>`),
+			[]line{{text: "runs past the edge", left: 580, top: 10, height: 14, size: 10, right: 600}},
+		},
+		{
+			"blank line",
+			page(`<p style="top:10pt;left:20pt;line-height:14pt"><span style="font-size:10pt">   </span></p>`),
+			[]line{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines, width := parseLines(test.markup)
+			if width != 600 {
+				t.Errorf("got width %v, want 600", width)
+			}
+			if len(lines) != len(test.want) {
+				t.Fatalf("got %d lines, want %d", len(lines), len(test.want))
+			}
+			for i := range lines {
+				if lines[i] != test.want[i] {
+					t.Errorf("line %d: got %+v, want %+v", i, lines[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestReadingOrder checks that the columns are read one after the other, after the lines above them
+func TestReadingOrder(t *testing.T) {
+	at := func(text string, left, top float64) line {
+		return line{text: text, left: left, top: top, height: 10, size: 10}
+	}
+	tests := []struct {
+		name  string
+		lines []line
+		want  []string
+	}{
+		{
+			"single column",
+			[]line{at("second", 50, 20), at("first", 50, 10), at("third", 50, 30)},
+			[]string{"first", "second", "third"},
+		},
+		{
+			"two columns",
+			[]line{
+				at("title", 50, 0),
+				at("left 1", 50, 20), at("right 1", 350, 20),
+				at("left 2", 50, 32), at("right 2", 350, 32),
+				at("left 3", 50, 44), at("right 3", 350, 44),
+			},
+			[]string{"title", "left 1", "left 2", "left 3", "right 1", "right 2", "right 3"},
+		},
+		{
+			// too few lines side by side to be columns, a table row or a caption
+			"side by side once",
+			[]line{at("label", 50, 20), at("value", 350, 20), at("next", 50, 32)},
+			[]string{"label", "value", "next"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines := readingOrder(test.lines, 600)
+			for i, l := range lines {
+				if l.text != test.want[i] {
+					t.Errorf("line %d: got %q, want %q", i, l.text, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestPageBlocks checks that the lines are grouped into paragraphs by their gaps and font sizes
+func TestPageBlocks(t *testing.T) {
+	lines := []line{
+		{text: "Title", left: 50, right: 100, top: 0, height: 20, size: 18},
+		{text: "The first para-", left: 50, right: 200, top: 30, height: 12, size: 10},
+		{text: "graph goes on.", left: 50, right: 190, top: 43, height: 12, size: 10},
+		{text: "A second paragraph.", left: 60, right: 250, top: 80, height: 12, size: 10},
+	}
+	blocks := pageBlocks(2, lines, 600)
+
+	want := []Block{
+		{Page: 2, Text: "Title", FontSize: 18, Box: Box{50, 0, 100, 20}, lines: 1},
+		{Page: 2, Text: "The first paragraph goes on.", FontSize: 10, Box: Box{50, 30, 200, 55}, lines: 2},
+		{Page: 2, Text: "A second paragraph.", FontSize: 10, Box: Box{60, 80, 250, 92}, lines: 1},
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d", len(blocks), len(want))
+	}
+	for i := range blocks {
+		if blocks[i] != want[i] {
+			t.Errorf("block %d: got %+v, want %+v", i, blocks[i], want[i])
+		}
+	}
+}
+
+// TestJoinLines checks the words hyphenated across lines
+func TestJoinLines(t *testing.T) {
+	tests := []struct {
+		text, next string
+		want       string
+	}{
+		{"a para-", "graph", "a paragraph"},
+		{"a soft\u00ad", "hyphen", "a softhyphen"},
+		{"well-", "Known", "well- Known"},
+		{"pages 10-", "12", "pages 10- 12"},
+		{"plain ", "text", "plain text"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := joinLines(test.text, test.next); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestMarkHeadings checks the levels of the short blocks larger than the body text
+func TestMarkHeadings(t *testing.T) {
+	body := "A body paragraph long enough to set the font size of the body text of the document."
+	blocks := []Block{
+		{Text: "Chapter", FontSize: 24, lines: 1},
+		{Text: "Section", FontSize: 16, lines: 1},
+		{Text: body, FontSize: 10, lines: 2},
+		{Text: "Slightly larger", FontSize: 10.5, lines: 1},
+		{Text: "Another chapter", FontSize: 24.2, lines: 1},
+		{Text: "Large but long", FontSize: 16, lines: 4},
+		{Text: body, FontSize: 10, lines: 2},
+	}
+	markHeadings(blocks)
+
+	want := []int{1, 2, 0, 0, 1, 0, 0}
+	for i, block := range blocks {
+		if block.Heading != want[i] {
+			t.Errorf("%q: got level %d, want %d", block.Text, block.Heading, want[i])
+		}
+	}
+}
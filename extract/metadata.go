@@ -0,0 +1,295 @@
+package extract
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/validate"
+)
+
+// NameMetadata names the reader of the metadata in its errors
+const NameMetadata = "pdfcpu"
+
+func init() {
+	// pdfcpu would otherwise write its configuration to the home directory on first use
+	model.ConfigPath = "disable"
+}
+
+// Metadata is what a pdf tells about itself: its info dictionary, completed by its XMP
+// metadata, its version, whether it is encrypted, the size of its pages and its bookmarks.
+type Metadata struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords string
+	Creator  string
+	Producer string
+	// Created and Modified are nil when absent or unreadable
+	Created   *time.Time
+	Modified  *time.Time
+	Version   string
+	Encrypted bool
+	PageSizes []PageSize
+	Outline   []OutlineItem
+}
+
+// PageSize is the size of a page in points, as displayed once rotated
+type PageSize struct {
+	Width, Height float64
+}
+
+// OutlineItem is a bookmark of a pdf and the page it points to, from 1
+type OutlineItem struct {
+	Title    string
+	Page     int
+	Children []OutlineItem
+}
+
+// ReadMetadata reads the metadata of a pdf with pdfcpu. Encrypted files that cannot be opened
+// without a password return an *Error of kind ErrEncrypted.
+func ReadMetadata(ctx context.Context, path string) (*Metadata, error) {
+	pdf, err := readStructure(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := &Metadata{
+		Title:     strings.TrimSpace(pdf.Title),
+		Author:    strings.TrimSpace(pdf.Author),
+		Subject:   strings.TrimSpace(pdf.Subject),
+		Keywords:  strings.TrimSpace(pdf.Keywords),
+		Creator:   strings.TrimSpace(pdf.Creator),
+		Producer:  strings.TrimSpace(pdf.Producer),
+		Created:   pdfDate(pdf.CreationDate),
+		Modified:  pdfDate(pdf.ModDate),
+		Version:   pdf.HeaderVersion.String(),
+		Encrypted: pdf.Encrypt != nil,
+	}
+	if pdf.RootVersion != nil {
+		metadata.Version = pdf.RootVersion.String()
+	}
+
+	if dims, err := pdf.PageDims(); err == nil {
+		metadata.PageSizes = make([]PageSize, len(dims))
+		for i, dim := range dims {
+			metadata.PageSizes[i] = PageSize{Width: dim.Width, Height: dim.Height}
+		}
+	}
+
+	if xmp, err := readXMP(pdf); err == nil && xmp != nil {
+		metadata.complete(xmp)
+	}
+
+	metadata.Outline = readOutline(pdf)
+	return metadata, nil
+}
+
+// readStructure reads and validates the objects of a pdf with pdfcpu, tolerating the common
+// deviations from the specification
+func readStructure(ctx context.Context, path string) (*model.Context, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	pdf, err := pdfcpu.Read(f, conf)
+	if err == nil {
+		err = validate.XRefTable(pdf.XRefTable)
+	}
+	if err != nil {
+		kind := ErrUnreadable
+		if errors.Is(err, pdfcpu.ErrWrongPassword) {
+			kind = ErrEncrypted
+		}
+		return nil, &Error{Extractor: NameMetadata, Kind: kind, Err: err}
+	}
+	return pdf, nil
+}
+
+func pdfDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	date, ok := types.DateTime(value, true)
+	if !ok {
+		return nil
+	}
+	date = date.UTC()
+	return &date
+}
+
+// complete fills the fields left empty by the info dictionary with those of the XMP metadata
+func (m *Metadata) complete(xmp *Metadata) {
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&m.Title, xmp.Title)
+	fill(&m.Author, xmp.Author)
+	fill(&m.Subject, xmp.Subject)
+	fill(&m.Keywords, xmp.Keywords)
+	fill(&m.Creator, xmp.Creator)
+	fill(&m.Producer, xmp.Producer)
+	if m.Created == nil {
+		m.Created = xmp.Created
+	}
+	if m.Modified == nil {
+		m.Modified = xmp.Modified
+	}
+}
+
+// XMP namespaces of the properties read
+const (
+	namespaceDC  = "http://purl.org/dc/elements/1.1/"
+	namespacePDF = "http://ns.adobe.com/pdf/1.3/"
+	namespaceXMP = "http://ns.adobe.com/xap/1.0/"
+)
+
+// readXMP reads the metadata stream of the catalog, nil when there is none
+func readXMP(pdf *model.Context) (*Metadata, error) {
+	catalog, err := pdf.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := catalog.Find("Metadata")
+	if !ok {
+		return nil, nil
+	}
+	stream, _, err := pdf.DereferenceStreamDict(entry)
+	if err != nil || stream == nil {
+		return nil, err
+	}
+	if err := stream.Decode(); err != nil {
+		return nil, err
+	}
+	return parseXMP(stream.Content)
+}
+
+// parseXMP reads the Dublin Core, PDF and XMP basic properties, written as elements or as
+// attributes of rdf:Description. The values of lists and alternatives are joined with "; ".
+func parseXMP(content []byte) (*Metadata, error) {
+	values := map[xml.Name][]string{}
+	decoder := xml.NewDecoder(strings.NewReader(string(content)))
+	// the property being read, the text of rdf:li belongs to it
+	var property *xml.Name
+	var text strings.Builder
+	depth, propertyDepth := 0, 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if t.Name.Local == "Description" {
+				for _, attribute := range t.Attr {
+					values[attribute.Name] = append(values[attribute.Name], attribute.Value)
+				}
+				continue
+			}
+			switch t.Name.Space {
+			case namespaceDC, namespacePDF, namespaceXMP:
+				name := t.Name
+				property, propertyDepth = &name, depth
+				text.Reset()
+			}
+			if t.Name.Local == "li" {
+				text.Reset()
+			}
+		case xml.CharData:
+			if property != nil {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			if property != nil && (t.Name.Local == "li" || depth == propertyDepth) {
+				if value := strings.TrimSpace(text.String()); value != "" {
+					values[*property] = append(values[*property], value)
+				}
+				text.Reset()
+			}
+			if depth == propertyDepth {
+				property = nil
+			}
+			depth--
+		}
+	}
+
+	get := func(space, local string) string {
+		return strings.Join(values[xml.Name{Space: space, Local: local}], "; ")
+	}
+	date := func(space, local string) *time.Time {
+		value := get(space, local)
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04Z07:00", "2006-01-02"} {
+			if parsed, err := time.Parse(layout, value); err == nil {
+				parsed = parsed.UTC()
+				return &parsed
+			}
+		}
+		return nil
+	}
+	return &Metadata{
+		Title:    get(namespaceDC, "title"),
+		Author:   get(namespaceDC, "creator"),
+		Subject:  get(namespaceDC, "description"),
+		Keywords: get(namespacePDF, "Keywords"),
+		Creator:  get(namespaceXMP, "CreatorTool"),
+		Producer: get(namespacePDF, "Producer"),
+		Created:  date(namespaceXMP, "CreateDate"),
+		Modified: date(namespaceXMP, "ModifyDate"),
+	}, nil
+}
+
+// readOutline returns the bookmarks of a pdf, none when its outline is missing or broken
+func readOutline(pdf *model.Context) []OutlineItem {
+	catalog, err := pdf.Catalog()
+	if err != nil {
+		return nil
+	}
+	outlines, err := pdf.DereferenceDict(catalog["Outlines"])
+	if err != nil || outlines == nil {
+		return nil
+	}
+	first := outlines.IndirectRefEntry("First")
+	if first == nil {
+		return nil
+	}
+	if err := pdf.LocateNameTree("Dests", false); err != nil {
+		return nil
+	}
+	bookmarks, err := pdfcpu.BookmarksForOutlineItem(pdf, first, nil)
+	if err != nil {
+		return nil
+	}
+	return outlineItems(bookmarks)
+}
+
+func outlineItems(bookmarks []pdfcpu.Bookmark) []OutlineItem {
+	items := make([]OutlineItem, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		items = append(items, OutlineItem{
+			Title:    strings.TrimSpace(bookmark.Title),
+			Page:     bookmark.PageFrom,
+			Children: outlineItems(bookmark.Children),
+		})
+	}
+	return items
+}
@@ -0,0 +1,177 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writePDF writes a pdf of the given objects, numbered from 1, the first being the catalog
+func writePDF(t *testing.T, objects []string, trailer string) string {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.6\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R %s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, xref)
+
+	path := filepath.Join(t.TempDir(), "document.pdf")
+	if err := os.WriteFile(path, b.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func date(value string) *time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return &parsed
+}
+
+// TestReadMetadata reads the info dictionary, the size of the pages, rotated ones included,
+// and the nested bookmarks
+func TestReadMetadata(t *testing.T) {
+	path := writePDF(t, []string{
+		"<< /Type /Catalog /Pages 2 0 R /Outlines 5 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Rotate 90 >>",
+		"<< /Type /Outlines /First 6 0 R /Last 6 0 R /Count 2 >>",
+		"<< /Title (Introduction) /Parent 5 0 R /Dest [3 0 R /Fit] /First 7 0 R /Last 7 0 R /Count 1 >>",
+		"<< /Title ( Results ) /Parent 6 0 R /Dest [4 0 R /Fit] >>",
+		"<< /Title (Annual report) /Author (Jane Doe) /Producer (Writer 2.1) /CreationDate (D:20230115103000Z) >>",
+	}, "/Info 8 0 R")
+
+	metadata, err := ReadMetadata(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.Title != "Annual report" || metadata.Author != "Jane Doe" || metadata.Producer != "Writer 2.1" {
+		t.Errorf("got title %q, author %q and producer %q", metadata.Title, metadata.Author, metadata.Producer)
+	}
+	if want := date("2023-01-15T10:30:00Z"); metadata.Created == nil || !metadata.Created.Equal(*want) {
+		t.Errorf("got creation date %v, want %v", metadata.Created, want)
+	}
+	if metadata.Modified != nil {
+		t.Errorf("got modification date %v, want none", metadata.Modified)
+	}
+	if metadata.Version != "1.6" || metadata.Encrypted {
+		t.Errorf("got version %q and encrypted %v, want 1.6 and false", metadata.Version, metadata.Encrypted)
+	}
+	if want := []PageSize{{612, 792}, {792, 612}}; !reflect.DeepEqual(metadata.PageSizes, want) {
+		t.Errorf("got page sizes %v, want %v", metadata.PageSizes, want)
+	}
+	want := []OutlineItem{{Title: "Introduction", Page: 1, Children: []OutlineItem{{Title: "Results", Page: 2, Children: []OutlineItem{}}}}}
+	if !reflect.DeepEqual(metadata.Outline, want) {
+		t.Errorf("got outline %+v, want %+v", metadata.Outline, want)
+	}
+}
+
+// TestReadMetadataUnreadable fails with an *Error of kind ErrUnreadable
+func TestReadMetadataUnreadable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4\nnot a pdf"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ReadMetadata(context.Background(), path)
+	var extractErr *Error
+	if !errors.As(err, &extractErr) || !errors.Is(err, ErrUnreadable) || extractErr.Extractor != NameMetadata {
+		t.Errorf("got %v, want an unreadable pdf error of %s", err, NameMetadata)
+	}
+}
+
+// TestParseXMP reads the properties written as elements and as attributes and joins the
+// values of lists
+func TestParseXMP(t *testing.T) {
+	content := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:pdf="http://ns.adobe.com/pdf/1.3/"
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    pdf:Producer="Writer 2.1"
+    xmp:CreateDate="2023-01-15T10:30:00+02:00">
+   <pdf:Keywords>budget, forecast</pdf:Keywords>
+   <xmp:ModifyDate>2023-02-01</xmp:ModifyDate>
+   <dc:title><rdf:Alt><rdf:li xml:lang="x-default">Annual report</rdf:li></rdf:Alt></dc:title>
+   <dc:creator><rdf:Seq><rdf:li>Jane Doe</rdf:li><rdf:li>John Roe</rdf:li></rdf:Seq></dc:creator>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`)
+
+	got, err := parseXMP(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Metadata{
+		Title:    "Annual report",
+		Author:   "Jane Doe; John Roe",
+		Keywords: "budget, forecast",
+		Producer: "Writer 2.1",
+		Created:  date("2023-01-15T08:30:00Z"),
+		Modified: date("2023-02-01T00:00:00Z"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := parseXMP([]byte("<x:xmpmeta><unclosed>")); err == nil {
+		t.Error("got no error for malformed XML")
+	}
+}
+
+// TestComplete keeps the values of the info dictionary and fills the empty ones from XMP
+func TestComplete(t *testing.T) {
+	metadata := &Metadata{Title: "Info title", Created: date("2020-01-01T00:00:00Z")}
+	metadata.complete(&Metadata{
+		Title:    "XMP title",
+		Author:   "XMP author",
+		Created:  date("2021-01-01T00:00:00Z"),
+		Modified: date("2021-06-01T00:00:00Z"),
+	})
+
+	want := &Metadata{
+		Title:    "Info title",
+		Author:   "XMP author",
+		Created:  date("2020-01-01T00:00:00Z"),
+		Modified: date("2021-06-01T00:00:00Z"),
+	}
+	if !reflect.DeepEqual(metadata, want) {
+		t.Errorf("got %+v, want %+v", metadata, want)
+	}
+}
+
+// TestPDFDate reads the dates of the info dictionary in UTC, nil when missing or malformed
+func TestPDFDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  *time.Time
+	}{
+		{"D:20230115103000Z", date("2023-01-15T10:30:00Z")},
+		{"D:20230115103000+02'00'", date("2023-01-15T08:30:00Z")},
+		{"", nil},
+		{"yesterday", nil},
+	}
+	for _, tt := range tests {
+		got := pdfDate(tt.value)
+		if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+			t.Errorf("pdfDate(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/dslipak/pdf"
+)
+
+// Native reads the text with a pure Go parser. It handles fewer fonts and encodings than MuPDF.
+type Native struct{}
+
+func (Native) Name() string { return NameNative }
+
+func (n Native) Extract(ctx context.Context, path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &Error{Extractor: n.Name(), Kind: ErrUnreadable, Err: err}
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, &Error{Extractor: n.Name(), Kind: ErrUnreadable, Err: err}
+	}
+
+	reader, err := openNative(file, info.Size())
+	if err != nil {
+		kind := ErrUnreadable
+		if errors.Is(err, pdf.ErrInvalidPassword) {
+			kind = ErrEncrypted
+		}
+		return nil, &Error{Extractor: n.Name(), Kind: kind, Err: err}
+	}
+
+	pages := make([]string, reader.NumPage())
+	for i := range pages {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page := reader.Page(i + 1)
+		if page.V.IsNull() {
+			continue
+		}
+		// fonts are not shared between pages, their names are only unique within a page
+		pages[i], err = page.GetPlainText(nil)
+		if err != nil {
+			return nil, &Error{Extractor: n.Name(), Page: i + 1, Kind: ErrPage, Err: err}
+		}
+	}
+	return &Document{Extractor: n.Name(), Pages: pages}, nil
+}
+
+// openNative turns the panics of the parser on malformed files into errors
+func openNative(file *os.File, size int64) (reader *pdf.Reader, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("malformed pdf")
+		}
+	}()
+	return pdf.NewReader(file, size)
+}
@@ -0,0 +1,47 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Exit codes of pdftotext
+const (
+	pdftotextOpenError       = 1
+	pdftotextPermissionError = 3
+)
+
+// Pdftotext runs poppler's pdftotext, which must be installed
+type Pdftotext struct{}
+
+func (Pdftotext) Name() string { return NamePdftotext }
+
+func (p Pdftotext) Extract(ctx context.Context, path string) (*Document, error) {
+	binary, err := exec.LookPath("pdftotext")
+	if err != nil {
+		return nil, &Error{Extractor: p.Name(), Kind: ErrUnavailable, Err: err}
+	}
+
+	cmd := exec.CommandContext(ctx, binary, "-enc", "UTF-8", path, "-")
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		kind := ErrUnreadable
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && exit.ExitCode() == pdftotextPermissionError {
+			kind = ErrEncrypted
+		} else if !errors.As(err, &exit) || exit.ExitCode() != pdftotextOpenError {
+			kind = ErrPage
+		}
+		return nil, &Error{Extractor: p.Name(), Kind: kind, Err: fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))}
+	}
+
+	// pdftotext ends every page with a form feed
+	pages := strings.Split(out.String(), "\f")
+	return &Document{Extractor: p.Name(), Pages: pages[:len(pages)-1]}, nil
+}
@@ -0,0 +1,447 @@
+package extract
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Table detection thresholds, in points unless stated otherwise
+const (
+	// ruleTolerance is the distance under which two rules touch or sit on the same line
+	ruleTolerance = 2.0
+	// minRuleLength is the length under which a straight segment is not a rule, such as an underline of a letter
+	minRuleLength = 5.0
+	// minTableRows is the number of aligned rows that makes a table without rules
+	minTableRows = 3
+	// maxCellRunes is the average length of the cells of a table without rules, longer ones are columns of text
+	maxCellRunes = 30
+	// rowGap is the vertical space between two rows of a table without rules, relative to the line height
+	rowGap = 1.5
+)
+
+// Table is a table of a page with the text of its cells, row by row. All rows have the same number of cells.
+type Table struct {
+	Page int
+	Box  Box
+	Rows [][]string
+}
+
+// segment is a horizontal or vertical rule of a page, from the top left corner
+type segment struct {
+	x0, y0, x1, y1 float64
+}
+
+func (s segment) horizontal() bool { return s.y1-s.y0 < s.x1-s.x0 }
+
+func (s segment) touches(other segment) bool {
+	return s.x0 <= other.x1+ruleTolerance && other.x0 <= s.x1+ruleTolerance &&
+		s.y0 <= other.y1+ruleTolerance && other.y0 <= s.y1+ruleTolerance
+}
+
+var (
+	pathPattern      = regexp.MustCompile(`<path ([^>]*?)d="([^"]*)"`)
+	transformPattern = regexp.MustCompile(`transform="matrix\(([^)]*)\)"`)
+	pathTokenPattern = regexp.MustCompile(`[A-Za-z]|-?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+)
+
+// parseRules reads the straight horizontal and vertical strokes and fills of the svg of a page
+// drawn by MuPDF. The paths with curves are left out and so are the glyphs, defined with an id.
+func parseRules(svg string) []segment {
+	rules := []segment{}
+	for _, match := range pathPattern.FindAllStringSubmatch(svg, -1) {
+		attributes := match[1]
+		if strings.Contains(attributes, "id=") {
+			continue
+		}
+		matrix := [6]float64{1, 0, 0, 1, 0, 0}
+		if transform := transformPattern.FindStringSubmatch(attributes); transform != nil {
+			for i, value := range strings.Split(transform[1], ",") {
+				if i < len(matrix) {
+					matrix[i], _ = strconv.ParseFloat(strings.TrimSpace(value), 64)
+				}
+			}
+		}
+		rules = append(rules, pathRules(match[2], matrix)...)
+	}
+	return rules
+}
+
+// pathRules returns the straight segments of a path made of moves and lines only
+func pathRules(d string, matrix [6]float64) []segment {
+	tokens := pathTokenPattern.FindAllString(d, -1)
+	points := [][2]float64{}
+	rules := []segment{}
+	var x, y, startX, startY float64
+	command := ""
+	number := func(i *int) (float64, bool) {
+		if *i >= len(tokens) {
+			return 0, false
+		}
+		value, err := strconv.ParseFloat(tokens[*i], 64)
+		if err != nil {
+			return 0, false
+		}
+		*i++
+		return value, true
+	}
+	flush := func() {
+		for i := 1; i < len(points); i++ {
+			rules = appendRule(rules, points[i-1], points[i], matrix)
+		}
+		points = points[:0]
+	}
+
+	for i := 0; i < len(tokens); {
+		if c := tokens[i]; len(c) == 1 && strings.ContainsAny(c, "MmLlHhVvZz") {
+			command = c
+			i++
+			if command == "Z" || command == "z" {
+				points = append(points, [2]float64{startX, startY})
+				x, y = startX, startY
+				flush()
+				continue
+			}
+		} else if unicode.IsLetter(rune(c[0])) {
+			// a curve or an arc, not a rule
+			return nil
+		}
+
+		switch command {
+		case "M", "m", "L", "l":
+			nx, ok1 := number(&i)
+			ny, ok2 := number(&i)
+			if !ok1 || !ok2 {
+				return rules
+			}
+			if command == "m" || command == "l" {
+				nx, ny = x+nx, y+ny
+			}
+			x, y = nx, ny
+			if command == "M" || command == "m" {
+				flush()
+				startX, startY = x, y
+				// the coordinates following a move are lines
+				command = map[string]string{"M": "L", "m": "l"}[command]
+			}
+		case "H", "h":
+			nx, ok := number(&i)
+			if !ok {
+				return rules
+			}
+			if command == "h" {
+				nx += x
+			}
+			x = nx
+		case "V", "v":
+			ny, ok := number(&i)
+			if !ok {
+				return rules
+			}
+			if command == "v" {
+				ny += y
+			}
+			y = ny
+		default:
+			return rules
+		}
+		points = append(points, [2]float64{x, y})
+	}
+	flush()
+	return rules
+}
+
+// appendRule appends the segment between two points of a path once transformed, when it is a long enough rule
+func appendRule(rules []segment, from, to [2]float64, m [6]float64) []segment {
+	x0, y0 := m[0]*from[0]+m[2]*from[1]+m[4], m[1]*from[0]+m[3]*from[1]+m[5]
+	x1, y1 := m[0]*to[0]+m[2]*to[1]+m[4], m[1]*to[0]+m[3]*to[1]+m[5]
+	s := segment{math.Min(x0, x1), math.Min(y0, y1), math.Max(x0, x1), math.Max(y0, y1)}
+	width, height := s.x1-s.x0, s.y1-s.y0
+	if (height <= ruleTolerance && width >= minRuleLength) || (width <= ruleTolerance && height >= minRuleLength) {
+		rules = append(rules, s)
+	}
+	return rules
+}
+
+// pageTables finds the tables of a page: the grids drawn with rules and the runs of rows
+// aligned in columns. It returns the lines that are not part of a table.
+func pageTables(page int, lines []line, rules []segment) ([]Table, []line) {
+	tables := []Table{}
+	for _, grid := range ruleGroups(rules) {
+		if table, ok := ruledTable(page, grid, lines); ok {
+			tables = append(tables, table)
+			lines = outside(lines, table.Box)
+		}
+	}
+	for {
+		table, ok := alignedTable(page, lines)
+		if !ok {
+			break
+		}
+		tables = append(tables, table)
+		lines = outside(lines, table.Box)
+	}
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].Box.Y0 < tables[j].Box.Y0 })
+	return tables, lines
+}
+
+// ruleGroups groups the rules touching each other
+func ruleGroups(rules []segment) [][]segment {
+	parent := make([]int, len(rules))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range rules {
+		for j := i + 1; j < len(rules); j++ {
+			if rules[i].touches(rules[j]) {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	groups := map[int][]segment{}
+	order := []int{}
+	for i, rule := range rules {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], rule)
+	}
+	result := make([][]segment, 0, len(order))
+	for _, root := range order {
+		result = append(result, groups[root])
+	}
+	return result
+}
+
+// positions returns the distinct values, sorted, merging those closer than ruleTolerance
+func positions(values []float64) []float64 {
+	sort.Float64s(values)
+	result := []float64{}
+	for _, value := range values {
+		if len(result) > 0 && value-result[len(result)-1] <= ruleTolerance {
+			continue
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
+// ruledTable reads the cells of a grid of rules. The columns of grids with horizontal rules
+// only are found from the alignment of their text.
+func ruledTable(page int, grid []segment, lines []line) (Table, bool) {
+	rows, columns := []float64{}, []float64{}
+	box := Box{X0: math.Inf(1), Y0: math.Inf(1), X1: math.Inf(-1), Y1: math.Inf(-1)}
+	for _, rule := range grid {
+		if rule.horizontal() {
+			rows = append(rows, (rule.y0+rule.y1)/2)
+		} else {
+			columns = append(columns, (rule.x0+rule.x1)/2)
+		}
+		box = Box{math.Min(box.X0, rule.x0), math.Min(box.Y0, rule.y0), math.Max(box.X1, rule.x1), math.Max(box.Y1, rule.y1)}
+	}
+	rows = positions(rows)
+	columns = positions(columns)
+	if len(rows) < 2 {
+		return Table{}, false
+	}
+
+	inside := []line{}
+	for _, l := range lines {
+		if contains(box, l) {
+			inside = append(inside, l)
+		}
+	}
+	if len(columns) < 2 {
+		columns = textColumns(inside)
+		if len(columns) < 2 {
+			return Table{}, false
+		}
+		columns = append([]float64{box.X0}, append(columns[1:], box.X1)...)
+	}
+
+	cells := make([][]string, len(rows)-1)
+	for i := range cells {
+		cells[i] = make([]string, len(columns)-1)
+	}
+	for _, l := range inside {
+		row := interval(rows, (l.top+l.bottom())/2)
+		column := interval(columns, l.left+ruleTolerance)
+		if row < 0 || column < 0 {
+			continue
+		}
+		cells[row][column] = strings.TrimSpace(cells[row][column] + " " + l.text)
+	}
+	return newTable(page, box, cells)
+}
+
+// interval returns the index of the interval between two consecutive edges holding value, -1 if none
+func interval(edges []float64, value float64) int {
+	for i := 1; i < len(edges); i++ {
+		if value >= edges[i-1] && value < edges[i] {
+			return i - 1
+		}
+	}
+	return -1
+}
+
+// textColumns returns the left edges of the columns of text of the lines, merging the lines
+// whose horizontal extents overlap. Right aligned numbers overlap the other cells of their column.
+func textColumns(lines []line) []float64 {
+	extents := make([][2]float64, len(lines))
+	for i, l := range lines {
+		extents[i] = [2]float64{l.left, l.right}
+	}
+	sort.Slice(extents, func(i, j int) bool { return extents[i][0] < extents[j][0] })
+
+	columns := []float64{}
+	right := math.Inf(-1)
+	for _, extent := range extents {
+		if extent[0] > right {
+			columns = append(columns, extent[0])
+		}
+		right = math.Max(right, extent[1])
+	}
+	return columns
+}
+
+// alignedTable finds the first run of at least minTableRows rows of short cells aligned in columns
+func alignedTable(page int, lines []line) (Table, bool) {
+	rows := textRows(lines)
+	start := 0
+	for start < len(rows) {
+		end := start
+		for end < len(rows) && len(rows[end]) >= 2 &&
+			(end == start || rows[end][0].top-rowBottom(rows[end-1]) <= rowGap*rows[end-1][0].height) {
+			end++
+		}
+		if end-start >= minTableRows {
+			if table, ok := alignedRows(page, rows[start:end]); ok {
+				return table, true
+			}
+		}
+		if end == start {
+			end++
+		}
+		start = end
+	}
+	return Table{}, false
+}
+
+// alignedRows reads a run of rows as a table when their cells are short and fall in columns
+func alignedRows(page int, rows [][]line) (Table, bool) {
+	all := []line{}
+	runes := 0
+	for _, row := range rows {
+		for _, l := range row {
+			all = append(all, l)
+			runes += utf8.RuneCountInString(l.text)
+		}
+	}
+	if runes/len(all) > maxCellRunes {
+		return Table{}, false
+	}
+	columns := textColumns(all)
+	if len(columns) < 2 {
+		return Table{}, false
+	}
+
+	box := Box{X0: math.Inf(1), Y0: math.Inf(1), X1: math.Inf(-1), Y1: math.Inf(-1)}
+	edges := append(columns, math.Inf(1))
+	cells := make([][]string, len(rows))
+	for i, row := range rows {
+		cells[i] = make([]string, len(columns))
+		for _, l := range row {
+			column := interval(edges, l.left)
+			cells[i][column] = strings.TrimSpace(cells[i][column] + " " + l.text)
+			box = Box{math.Min(box.X0, l.left), math.Min(box.Y0, l.top), math.Max(box.X1, l.right), math.Max(box.Y1, l.bottom())}
+		}
+	}
+	return newTable(page, box, cells)
+}
+
+// textRows groups the lines sharing a baseline, top to bottom and left to right
+func textRows(lines []line) [][]line {
+	sorted := append([]line{}, lines...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].top < sorted[j].top })
+
+	rows := [][]line{}
+	for _, l := range sorted {
+		if n := len(rows); n > 0 && math.Abs(rows[n-1][0].top-l.top) <= ruleTolerance {
+			rows[n-1] = append(rows[n-1], l)
+			continue
+		}
+		rows = append(rows, []line{l})
+	}
+	for _, row := range rows {
+		sort.SliceStable(row, func(i, j int) bool { return row[i].left < row[j].left })
+	}
+	return rows
+}
+
+func rowBottom(row []line) float64 {
+	bottom := math.Inf(-1)
+	for _, l := range row {
+		bottom = math.Max(bottom, l.bottom())
+	}
+	return bottom
+}
+
+// newTable drops the empty rows and columns of cells, a table has at least two of both
+func newTable(page int, box Box, cells [][]string) (Table, bool) {
+	used := []int{}
+	for column := range cells[0] {
+		for _, row := range cells {
+			if row[column] != "" {
+				used = append(used, column)
+				break
+			}
+		}
+	}
+
+	rows := [][]string{}
+	for _, row := range cells {
+		kept := make([]string, len(used))
+		empty := true
+		for i, column := range used {
+			kept[i] = row[column]
+			empty = empty && kept[i] == ""
+		}
+		if !empty {
+			rows = append(rows, kept)
+		}
+	}
+	if len(rows) < 2 || len(used) < 2 {
+		return Table{}, false
+	}
+	return Table{Page: page, Box: box, Rows: rows}, true
+}
+
+// contains reports whether the middle of a line is inside a box
+func contains(box Box, l line) bool {
+	x, y := l.left+ruleTolerance, (l.top+l.bottom())/2
+	return x >= box.X0 && x <= box.X1 && y >= box.Y0 && y <= box.Y1
+}
+
+// outside returns the lines out of a box
+func outside(lines []line, box Box) []line {
+	result := []line{}
+	for _, l := range lines {
+		if !contains(box, l) {
+			result = append(result, l)
+		}
+	}
+	return result
+}
@@ -0,0 +1,187 @@
+package extract
+
+import (
+	"reflect"
+	"testing"
+)
+
+// cell is a line of a table at the given position
+func cell(text string, left, top float64) line {
+	return line{text: text, left: left, right: left + 40, top: top, height: 10, size: 10}
+}
+
+// TestParseRules checks the rules read from the paths of the svg of MuPDF
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name string
+		svg  string
+		want []segment
+	}{
+		{
+			"horizontal and vertical lines",
+			`<path d="M 10 20 L 110 20 M 10 20 L 10 80" stroke="#000"/>`,
+			[]segment{{10, 20, 110, 20}, {10, 20, 10, 80}},
+		},
+		{
+			"relative commands",
+			`<path d="m 10 20 h 50 v 30" fill="none"/>`,
+			[]segment{{10, 20, 60, 20}, {60, 20, 60, 50}},
+		},
+		{
+			"closed rectangle",
+			`<path d="M0 0H50V30H0Z"/>`,
+			[]segment{{0, 0, 50, 0}, {50, 0, 50, 30}, {0, 30, 50, 30}, {0, 0, 0, 30}},
+		},
+		{
+			"transformed",
+			`<path transform="matrix(1,0,0,-1,0,800)" d="M 10 100 L 110 100"/>`,
+			[]segment{{10, 700, 110, 700}},
+		},
+		{"curve", `<path d="M 0 0 C 10 10 20 10 30 0"/>`, []segment{}},
+		{"glyph", `<path id="font_1_2" d="M 0 0 L 50 0"/>`, []segment{}},
+		{"diagonal", `<path d="M 0 0 L 50 50"/>`, []segment{}},
+		{"too short", `<path d="M 0 0 L 3 0"/>`, []segment{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseRules(test.svg); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestRuleGroups checks that the touching rules are grouped and the others kept apart
+func TestRuleGroups(t *testing.T) {
+	rules := []segment{
+		{0, 0, 100, 0},
+		{300, 0, 400, 0},
+		{0, 0, 0, 50},
+		{100, 1, 100, 50},
+	}
+	groups := ruleGroups(rules)
+	want := [][]segment{{rules[0], rules[2], rules[3]}, {rules[1]}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("got %v, want %v", groups, want)
+	}
+}
+
+// TestRuledTable checks the cells of a full grid and of a grid of horizontal rules only
+func TestRuledTable(t *testing.T) {
+	// two rows and two columns from (0, 0) to (200, 40)
+	grid := []segment{
+		{0, 0, 200, 0}, {0, 20, 200, 20}, {0, 40, 200, 40},
+		{0, 0, 0, 40}, {100, 0, 100, 40}, {200, 0, 200, 40},
+	}
+	lines := []line{
+		cell("Name", 5, 5), cell("Value", 105, 5),
+		cell("pages", 5, 25), cell("12", 105, 25),
+		cell("below the table", 5, 60),
+	}
+
+	tests := []struct {
+		name string
+		grid []segment
+	}{
+		{"full grid", grid},
+		{"horizontal rules", grid[:3]},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table, ok := ruledTable(1, test.grid, lines)
+			if !ok {
+				t.Fatal("no table")
+			}
+			want := [][]string{{"Name", "Value"}, {"pages", "12"}}
+			if !reflect.DeepEqual(table.Rows, want) {
+				t.Errorf("got %q, want %q", table.Rows, want)
+			}
+			if table.Box != (Box{0, 0, 200, 40}) {
+				t.Errorf("got box %+v", table.Box)
+			}
+		})
+	}
+
+	if _, ok := ruledTable(1, []segment{{0, 0, 200, 0}}, lines); ok {
+		t.Error("a single rule is a table")
+	}
+}
+
+// TestPageTables checks the tables without rules and the lines left out of them
+func TestPageTables(t *testing.T) {
+	paragraph := line{text: "A paragraph of running text above the table that is much longer than a cell.", left: 0, right: 500, top: 0, height: 10, size: 10}
+	tests := []struct {
+		name   string
+		lines  []line
+		tables [][][]string
+		rest   int
+	}{
+		{
+			"aligned rows",
+			[]line{
+				paragraph,
+				cell("Year", 0, 20), cell("Total", 100, 20),
+				cell("2022", 0, 32), cell("10", 100, 32),
+				cell("2023", 0, 44), cell("12", 100, 44),
+			},
+			[][][]string{{{"Year", "Total"}, {"2022", "10"}, {"2023", "12"}}},
+			1,
+		},
+		{
+			"too few rows",
+			[]line{cell("Year", 0, 20), cell("Total", 100, 20), cell("2022", 0, 32), cell("10", 100, 32)},
+			[][][]string{},
+			4,
+		},
+		{
+			"rows too far apart",
+			[]line{
+				cell("Year", 0, 20), cell("Total", 100, 20),
+				cell("2022", 0, 60), cell("10", 100, 60),
+				cell("2023", 0, 100), cell("12", 100, 100),
+			},
+			[][][]string{},
+			6,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tables, rest := pageTables(3, test.lines, nil)
+			if len(tables) != len(test.tables) {
+				t.Fatalf("got %d tables, want %d", len(tables), len(test.tables))
+			}
+			for i, table := range tables {
+				if table.Page != 3 || !reflect.DeepEqual(table.Rows, test.tables[i]) {
+					t.Errorf("table %d: got page %d %q, want %q", i, table.Page, table.Rows, test.tables[i])
+				}
+			}
+			if len(rest) != test.rest {
+				t.Errorf("got %d lines left, want %d", len(rest), test.rest)
+			}
+		})
+	}
+}
+
+// TestNewTable checks that the empty rows and columns are dropped
+func TestNewTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells [][]string
+		want  [][]string
+		ok    bool
+	}{
+		{"full", [][]string{{"a", "b"}, {"c", "d"}}, [][]string{{"a", "b"}, {"c", "d"}}, true},
+		{"empty column", [][]string{{"a", "", "b"}, {"c", "", "d"}}, [][]string{{"a", "b"}, {"c", "d"}}, true},
+		{"empty row", [][]string{{"a", "b"}, {"", ""}, {"c", "d"}}, [][]string{{"a", "b"}, {"c", "d"}}, true},
+		{"single column", [][]string{{"a", ""}, {"c", ""}}, nil, false},
+		{"single row", [][]string{{"a", "b"}, {"", ""}}, nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			table, ok := newTable(1, Box{}, test.cells)
+			if ok != test.ok || !reflect.DeepEqual(table.Rows, test.want) {
+				t.Errorf("got %q %v, want %q %v", table.Rows, ok, test.want, test.ok)
+			}
+		})
+	}
+}
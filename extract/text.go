@@ -0,0 +1,212 @@
+package extract
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Names of the extractors of the formats other than pdf
+const (
+	NameText     = "text"
+	NameMarkdown = "markdown"
+	NameHTML     = "html"
+	NameDOCX     = "docx"
+	NameEPUB     = "epub"
+)
+
+// maxLineSize is the length of the longest line of a text file read
+const maxLineSize = 16 << 20
+
+// pageBreak is the form feed that ends a page of a text file
+const pageBreak = "\f"
+
+// Text reads plain text. Blank lines end a paragraph and form feeds a page, the lines of a
+// paragraph are joined with the words hyphenated across them.
+type Text struct{}
+
+func (Text) Name() string { return NameText }
+
+func (t Text) Extract(ctx context.Context, path string) (*Document, error) {
+	f := newFlow(t.Name())
+	var paragraph string
+	end := func() {
+		f.paragraph(paragraph)
+		paragraph = ""
+	}
+	err := readLines(ctx, path, func(text string) {
+		for i, part := range strings.Split(text, pageBreak) {
+			if i > 0 {
+				end()
+				f.pageBreak()
+			}
+			if strings.TrimSpace(part) == "" {
+				end()
+				continue
+			}
+			paragraph = joinText(paragraph, strings.TrimSpace(part))
+		}
+	})
+	if err != nil {
+		return nil, &Error{Extractor: t.Name(), Kind: ErrUnreadable, Err: err}
+	}
+	end()
+	return f.finish(), nil
+}
+
+// readLines calls line with every line of a file, its invalid UTF-8 replaced
+func readLines(ctx context.Context, path string, line func(string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line(strings.ToValidUTF8(strings.TrimPrefix(scanner.Text(), "\ufeff"), "\ufffd"))
+	}
+	return scanner.Err()
+}
+
+// joinText appends a line to a paragraph
+func joinText(paragraph, line string) string {
+	if paragraph == "" {
+		return line
+	}
+	return joinLines(paragraph, line)
+}
+
+var (
+	atxHeading    = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.*?)(\s+#+)?\s*$`)
+	setextLine    = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	thematicBreak = regexp.MustCompile(`^\s{0,3}((\*\s*){3,}|(-\s*){3,}|(_\s*){3,})$`)
+	codeFence     = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	listItem      = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+`)
+	blockQuote    = regexp.MustCompile(`^\s{0,3}>\s?`)
+	tableRule     = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+
+	markdownImage    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink     = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	markdownRefLink  = regexp.MustCompile(`\[([^\]]+)\]\[[^\]]*\]`)
+	markdownEmphasis = regexp.MustCompile(`(^|[^\pL\pN])(\*{1,3}|_{1,3}|~~)([^*_~]+?)(\*{1,3}|_{1,3}|~~)($|[^\pL\pN])`)
+	markdownCode     = regexp.MustCompile("`+([^`]*)`+")
+	markdownTag      = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+)
+
+// Markdown reads the headings, paragraphs, list items and pipe tables of Markdown, without its
+// syntax. Code blocks are kept as paragraphs, form feeds end a page as in plain text.
+type Markdown struct{}
+
+func (Markdown) Name() string { return NameMarkdown }
+
+func (m Markdown) Extract(ctx context.Context, path string) (*Document, error) {
+	lines := []string{}
+	err := readLines(ctx, path, func(text string) {
+		for i, part := range strings.Split(text, pageBreak) {
+			if i > 0 {
+				lines = append(lines, pageBreak)
+			}
+			lines = append(lines, part)
+		}
+	})
+	if err != nil {
+		return nil, &Error{Extractor: m.Name(), Kind: ErrUnreadable, Err: err}
+	}
+	return parseMarkdown(m.Name(), lines), nil
+}
+
+func parseMarkdown(extractor string, lines []string) *Document {
+	f := newFlow(extractor)
+	var paragraph string
+	end := func() {
+		f.paragraph(inlineMarkdown(paragraph))
+		paragraph = ""
+	}
+
+	fence := ""
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if fence != "" {
+			if strings.HasPrefix(strings.TrimSpace(line), fence) {
+				fence = ""
+				end()
+				continue
+			}
+			paragraph = joinText(paragraph, strings.TrimSpace(line))
+			continue
+		}
+
+		switch {
+		case line == pageBreak:
+			end()
+			f.pageBreak()
+		case codeFence.MatchString(line):
+			end()
+			fence = codeFence.FindStringSubmatch(line)[1]
+		case strings.TrimSpace(line) == "":
+			end()
+		case atxHeading.MatchString(line):
+			end()
+			match := atxHeading.FindStringSubmatch(line)
+			f.heading(len(match[1]), inlineMarkdown(match[2]))
+		case paragraph != "" && setextLine.MatchString(line):
+			level := 1
+			if strings.Contains(line, "-") {
+				level = 2
+			}
+			f.heading(level, inlineMarkdown(paragraph))
+			paragraph = ""
+		case thematicBreak.MatchString(line):
+			end()
+		case strings.Contains(line, "|") && i+1 < len(lines) && tableRule.MatchString(lines[i+1]) && strings.Contains(lines[i+1], "-"):
+			end()
+			rows := [][]string{tableCells(line)}
+			for i += 2; i < len(lines) && strings.Contains(lines[i], "|") && strings.TrimSpace(lines[i]) != ""; i++ {
+				rows = append(rows, tableCells(lines[i]))
+			}
+			i--
+			f.table(rows)
+		case listItem.MatchString(line):
+			end()
+			paragraph = strings.TrimSpace(listItem.ReplaceAllString(line, ""))
+		default:
+			paragraph = joinText(paragraph, strings.TrimSpace(blockQuote.ReplaceAllString(line, "")))
+		}
+	}
+	end()
+	return f.finish()
+}
+
+// tableCells splits a row of a pipe table
+func tableCells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	for i, cell := range cells {
+		cells[i] = inlineMarkdown(strings.TrimSpace(cell))
+	}
+	return cells
+}
+
+// inlineMarkdown removes the inline syntax of Markdown, links keep their text and images their description
+func inlineMarkdown(text string) string {
+	text = markdownImage.ReplaceAllString(text, "$1")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownRefLink.ReplaceAllString(text, "$1")
+	text = markdownCode.ReplaceAllString(text, "$1")
+	text = markdownTag.ReplaceAllString(text, "")
+	for {
+		replaced := markdownEmphasis.ReplaceAllString(text, "$1$3$5")
+		if replaced == text {
+			return text
+		}
+		text = replaced
+	}
+}
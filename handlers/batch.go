@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/api"
+	"main/database"
+	"main/logging"
+	"main/utils"
+	"mime/multipart"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// the ingestion of the files of a batch and the deletion of their documents, replaced in tests
+var (
+	ingestBatchFile   = ingestFile
+	deleteBatchRecord = deleteRecord
+)
+
+// batchFile is a file of a batch, saved locally unless it was rejected before its ingestion
+type batchFile struct {
+	upload
+	// file is the name the file was sent with, archive the name of the archive it was in
+	file    string
+	archive string
+	failure *failure
+}
+
+// batchFailure answers the batches holding more files or bytes than the policy allows
+func batchFailure(err error) *failure {
+	policy := utils.GetUploadPolicy()
+	if errors.Is(err, utils.ErrBatchTooManyFiles) {
+		return &failure{status: fiber.StatusRequestEntityTooLarge, code: api.CodeBatchTooManyFiles, message: fmt.Sprintf("The batch holds more than the %d files accepted", policy.MaxBatchFiles), err: err}
+	}
+	return &failure{status: fiber.StatusRequestEntityTooLarge, code: api.CodeBatchTooLarge, message: fmt.Sprintf("The files of the batch are larger than the %d bytes accepted", policy.MaxBatchSize), err: err}
+}
+
+// entryFailure tells the files of an archive larger than the policy allows from those that cannot be read
+func entryFailure(err error) *failure {
+	if errors.Is(err, utils.ErrUploadEmpty) || errors.Is(err, utils.ErrUploadTooLarge) {
+		return validationFailure(err)
+	}
+	return &failure{status: fiber.StatusUnprocessableEntity, code: api.CodeArchiveInvalid, message: "The file cannot be read from its archive", err: err}
+}
+
+// saveBatch saves the uploaded files to local files, the files of the archives among them
+// expanded. The files rejected before their ingestion carry their failure. The batch fails as a
+// whole, nothing kept, when it holds more files or bytes than the policy allows.
+func saveBatch(c *fiber.Ctx, parts []*multipart.FileHeader) ([]batchFile, *failure) {
+	ctx := c.UserContext()
+	budget := utils.NewBatchBudget()
+	files := []batchFile{}
+	reject := func(f *failure) ([]batchFile, *failure) {
+		removeBatch(files)
+		return nil, f
+	}
+
+	for _, part := range parts {
+		local, err := saveUpload(c, part)
+		if err != nil {
+			files = append(files, batchFile{file: part.Filename, failure: internal(api.CodeUploadSaveFailed, "Failed to save the uploaded file", err)})
+			continue
+		}
+		format, err := utils.ArchiveFormat(local)
+		if err != nil {
+			os.Remove(local)
+			files = append(files, batchFile{file: part.Filename, failure: internal(api.CodeUploadOpenFailed, "Failed to read the uploaded file", err)})
+			continue
+		}
+
+		if format == "" {
+			if err := budget.Take(part.Size); err != nil {
+				os.Remove(local)
+				return reject(batchFailure(err))
+			}
+			file := batchFile{
+				upload: upload{
+					name:         utils.SanitizeFilename(part.Filename),
+					path:         local,
+					size:         part.Size,
+					contentType:  part.Header.Get(fiber.HeaderContentType),
+					declaredName: part.Filename,
+				},
+				file: part.Filename,
+			}
+			if err := utils.CheckUploadSize(part.Size); err != nil {
+				os.Remove(local)
+				file.failure = validationFailure(err)
+			}
+			files = append(files, file)
+			continue
+		}
+
+		entries, err := utils.ExpandArchive(ctx, local, format, budget)
+		os.Remove(local)
+		switch {
+		case errors.Is(err, utils.ErrBatchTooManyFiles) || errors.Is(err, utils.ErrBatchTooLarge):
+			return reject(batchFailure(err))
+		case err != nil:
+			files = append(files, batchFile{file: part.Filename, failure: &failure{status: fiber.StatusUnprocessableEntity, code: api.CodeArchiveInvalid, message: "The archive cannot be read", err: err}})
+			continue
+		case len(entries) == 0:
+			files = append(files, batchFile{file: part.Filename, failure: &failure{status: fiber.StatusBadRequest, code: api.CodeUploadEmpty, message: "The archive holds no files"}})
+			continue
+		}
+		for _, entry := range entries {
+			file := batchFile{
+				upload: upload{
+					name:         utils.SanitizeFilename(entry.Name),
+					path:         entry.Path,
+					size:         entry.Size,
+					declaredName: entry.Name,
+				},
+				file:    entry.Name,
+				archive: part.Filename,
+			}
+			if entry.Err != nil {
+				file.failure = entryFailure(entry.Err)
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// removeBatch removes the local files of a batch that was not ingested
+func removeBatch(files []batchFile) {
+	for _, file := range files {
+		if file.failure == nil {
+			os.Remove(file.path)
+		}
+	}
+}
+
+// ingestBatch ingests the files of a batch, as many at once as the policy has workers. When
+// abort is set, the first failure stops the batch: the files not started yet are skipped, those
+// being ingested are let finish, and the documents created are deleted.
+func ingestBatch(ctx context.Context, files []batchFile, abort bool) api.BatchResult {
+	start := time.Now()
+
+	records := make([]*database.Record, len(files))
+	failures := make([]*failure, len(files))
+	// first is the file whose failure aborted the batch
+	first := -1
+	var mu sync.Mutex
+	fail := func(i int, f *failure) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures[i] = f
+		if abort && first < 0 {
+			first = i
+		}
+	}
+	aborted := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return first >= 0
+	}
+
+	workers := utils.GetUploadPolicy().BatchWorkers
+	if workers > len(files) {
+		workers = len(files)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// cancelling the files being ingested would leave their objects behind, only
+				// those not started yet are skipped
+				if aborted() || ctx.Err() != nil {
+					os.Remove(files[i].path)
+					continue
+				}
+				record, f := ingestBatchFile(logging.WithField(ctx, "file", files[i].name), files[i].upload)
+				if f != nil {
+					fail(i, f)
+				} else {
+					records[i] = &record
+				}
+			}
+		}()
+	}
+	for i, file := range files {
+		if file.failure != nil {
+			fail(i, file.failure)
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	result := api.BatchResult{Aborted: first >= 0, Results: make([]api.BatchItem, len(files))}
+	for i, file := range files {
+		item := api.BatchItem{File: file.file, Archive: file.archive}
+		fileCtx := logging.WithField(ctx, "file", file.file)
+		switch f := failures[i]; {
+		case records[i] != nil && result.Aborted:
+			// an aborted batch leaves nothing behind, as if it was never sent. The records created by
+			// the batch are deleted themselves, the documents of the same name are left alone.
+			if f := deleteBatchRecord(logging.Detach(fileCtx), *records[i]); f != nil {
+				logging.FromContext(fileCtx).WithError(f.err).Error("failed to delete a document of an aborted batch")
+				document := api.NewDocument(*records[i])
+				item.Status, item.Document = api.BatchCreated, &document
+				result.Created++
+			} else {
+				item.Status = api.BatchRolledBack
+			}
+		case records[i] != nil:
+			document := api.NewDocument(*records[i])
+			item.Status, item.Document = api.BatchCreated, &document
+			result.Created++
+		case f != nil:
+			logFailure(fileCtx, f)
+			item.Status, item.Error = api.BatchFailed, &api.Error{Code: f.code, Message: f.message}
+			result.Failed++
+		default:
+			item.Status = api.BatchSkipped
+			item.Error = &api.Error{Code: api.CodeBatchAborted, Message: "The file was not ingested, the batch was aborted on the failure of another file"}
+		}
+		result.Results[i] = item
+	}
+
+	logging.FromContext(ctx).WithFields(logrus.Fields{
+		"files":       len(files),
+		"created":     result.Created,
+		"failed":      result.Failed,
+		"aborted":     result.Aborted,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}).Info("batch ingested")
+
+	return result
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"main/api"
+	"main/database"
+	"main/utils"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeBatch replaces the ingestion and the deletion of the files of a batch, recording the
+// files ingested and the records deleted
+type fakeBatch struct {
+	mu       sync.Mutex
+	ingested []string
+	deleted  []int32
+}
+
+func (b *fakeBatch) install(t *testing.T, workers int, ingest func(ctx context.Context, name string) *failure, deleteErr error) {
+	policy := utils.DefaultUploadPolicy
+	policy.BatchWorkers = workers
+	if err := utils.SetUploadPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		utils.SetUploadPolicy(utils.DefaultUploadPolicy)
+		ingestBatchFile, deleteBatchRecord = ingestFile, deleteRecord
+	})
+
+	ingestBatchFile = func(ctx context.Context, u upload) (database.Record, *failure) {
+		defer os.Remove(u.path)
+		b.mu.Lock()
+		b.ingested = append(b.ingested, u.name)
+		id := int32(len(b.ingested))
+		b.mu.Unlock()
+		if f := ingest(ctx, u.name); f != nil {
+			return database.Record{}, f
+		}
+		return database.Record{ID: id, Name: u.name}, nil
+	}
+	deleteBatchRecord = func(ctx context.Context, record database.Record) *failure {
+		if ctx.Err() != nil {
+			return internal(api.CodeRecordDelete, "Failed to delete the file details from the database", ctx.Err())
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.deleted = append(b.deleted, record.ID)
+		if deleteErr != nil {
+			return internal(api.CodeRecordDelete, "Failed to delete the file details from the database", deleteErr)
+		}
+		return nil
+	}
+}
+
+// batchFiles writes a local file for each name, as saveBatch does
+func batchFiles(t *testing.T, names ...string) []batchFile {
+	dir := t.TempDir()
+	files := make([]batchFile, len(names))
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		files[i] = batchFile{upload: upload{name: name, path: path}, file: name}
+	}
+	return files
+}
+
+func statuses(result api.BatchResult) []string {
+	got := make([]string, len(result.Results))
+	for i, item := range result.Results {
+		got[i] = item.Status
+	}
+	return got
+}
+
+var errIngest = &failure{status: fiber.StatusUnprocessableEntity, code: api.CodePDFEncrypted, message: "The pdf is encrypted"}
+
+// TestIngestBatchAbort skips the files after the first failure, removing their local file, and
+// deletes the documents created before it
+func TestIngestBatchAbort(t *testing.T) {
+	var batch fakeBatch
+	batch.install(t, 1, func(ctx context.Context, name string) *failure {
+		if name == "b.pdf" {
+			return errIngest
+		}
+		return nil
+	}, nil)
+	files := batchFiles(t, "a.pdf", "b.pdf", "c.pdf")
+
+	result := ingestBatch(context.Background(), files, true)
+	want := []string{api.BatchRolledBack, api.BatchFailed, api.BatchSkipped}
+	if got := statuses(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("got statuses %v, want %v", got, want)
+	}
+	if !result.Aborted || result.Created != 0 || result.Failed != 1 {
+		t.Errorf("got aborted %v, %d created and %d failed, want an aborted batch with 1 failure", result.Aborted, result.Created, result.Failed)
+	}
+	if !reflect.DeepEqual(batch.ingested, []string{"a.pdf", "b.pdf"}) || len(batch.deleted) != 1 || batch.deleted[0] != 1 {
+		t.Errorf("got %v ingested and records %v deleted, want a.pdf and b.pdf ingested and a.pdf deleted", batch.ingested, batch.deleted)
+	}
+	if _, err := os.Stat(files[2].path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the local file of the skipped file was kept: %v", err)
+	}
+	if item := result.Results[2]; item.Error == nil || item.Error.Code != api.CodeBatchAborted {
+		t.Errorf("got error %+v for the skipped file, want %s", item.Error, api.CodeBatchAborted)
+	}
+}
+
+// TestIngestBatchAbortInFlight lets the file being ingested when another fails finish, on a
+// context still alive, and deletes its document
+func TestIngestBatchAbortInFlight(t *testing.T) {
+	var batch fakeBatch
+	failed := make(chan struct{})
+	var slowErr error
+	batch.install(t, 2, func(ctx context.Context, name string) *failure {
+		if name == "b.pdf" {
+			defer close(failed)
+			return errIngest
+		}
+		<-failed
+		slowErr = ctx.Err()
+		return nil
+	}, nil)
+
+	result := ingestBatch(context.Background(), batchFiles(t, "a.pdf", "b.pdf"), true)
+	want := []string{api.BatchRolledBack, api.BatchFailed}
+	if got := statuses(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("got statuses %v, want %v", got, want)
+	}
+	if slowErr != nil {
+		t.Errorf("the file being ingested was canceled: %v", slowErr)
+	}
+	if len(batch.deleted) != 1 {
+		t.Errorf("got records %v deleted, want the record of a.pdf", batch.deleted)
+	}
+}
+
+// TestIngestBatchRollbackFailure reports the documents that could not be deleted as created
+func TestIngestBatchRollbackFailure(t *testing.T) {
+	var batch fakeBatch
+	batch.install(t, 1, func(ctx context.Context, name string) *failure {
+		if name == "b.pdf" {
+			return errIngest
+		}
+		return nil
+	}, errors.New("storage unavailable"))
+
+	result := ingestBatch(context.Background(), batchFiles(t, "a.pdf", "b.pdf"), true)
+	want := []string{api.BatchCreated, api.BatchFailed}
+	if got := statuses(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("got statuses %v, want %v", got, want)
+	}
+	if result.Created != 1 || result.Results[0].Document == nil {
+		t.Errorf("got %d created and document %v, want the document kept", result.Created, result.Results[0].Document)
+	}
+}
+
+// TestIngestBatchNoAbort ingests every file whatever fails when abort is not set
+func TestIngestBatchNoAbort(t *testing.T) {
+	var batch fakeBatch
+	batch.install(t, 2, func(ctx context.Context, name string) *failure {
+		if name == "a.pdf" {
+			return errIngest
+		}
+		return nil
+	}, nil)
+	files := batchFiles(t, "a.pdf", "b.pdf", "c.pdf")
+	files = append(files, batchFile{file: "d.pdf", failure: validationFailure(utils.ErrUploadEmpty)})
+
+	result := ingestBatch(context.Background(), files, false)
+	want := []string{api.BatchFailed, api.BatchCreated, api.BatchCreated, api.BatchFailed}
+	if got := statuses(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("got statuses %v, want %v", got, want)
+	}
+	if result.Aborted || result.Created != 2 || result.Failed != 2 || len(batch.deleted) != 0 {
+		t.Errorf("got aborted %v, %d created, %d failed and records %v deleted", result.Aborted, result.Created, result.Failed, batch.deleted)
+	}
+}
@@ -1,116 +1,90 @@
 package handlers
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
+	"main/analytics"
+	"main/api"
+	"main/logging"
+	"main/metrics"
+	"main/openapi"
 	"main/utils"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// legacyFrequencyOptions reproduces the top five words of /getMostOccurring, skipping the stopwords of the document language
+var legacyFrequencyOptions = analytics.FrequencyOptions{N: 5, NGram: 1, MinLength: 2}
+
 func Home(c *fiber.Ctx) error {
-	return c.JSON(utils.GetWelcome())
+	return c.JSON(openapi.Welcome(openapi.Endpoints))
 }
 
 func SaveFile(c *fiber.Ctx) error {
-	file, err := c.FormFile("pdf")
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "File upload failed",
-		})
-	}
-	inFile, err := file.Open()
+	file, err := formFile(c)
 	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to open the pdf file")
+		return utils.SendBadRequestStatus(c, api.CodeUploadMissingFile, "File upload failed")
 	}
-	defer inFile.Close()
 
-	ctx := c.Context()
-	err = utils.UploadPDF(ctx, "pdf", file.Filename, inFile)
-	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to upload the pdf file to MinIO")
+	insertedRecord, f := ingestUpload(c, file)
+	if f != nil {
+		return sendLegacy(c, f)
 	}
 
-	//Insert record of upload into the database
-	err = c.SaveFile(file, file.Filename)
-	defer os.Remove(file.Filename)
-	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to save pdf file")
-	}
+	return c.JSON(fiber.Map{"Id": insertedRecord.ID, "File": insertedRecord.Name})
+}
 
-	pdfFileName := file.Filename
-	reader, text, err := utils.ReadPdf(pdfFileName)
-	if err != nil {
-		fmt.Printf("err: %v\n", err)
-		return utils.SendErrorStatus(c, "Failed to read pdf as text")
+func ListFiles(c *fiber.Ctx) error {
+	filter, f := recordFilter(c)
+	if f != nil {
+		return sendLegacy(c, f)
 	}
 
-	insertedRecord, err := utils.CreateRecord(ctx, file.Filename, int32(reader.NumPage()), int32(file.Size))
-	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to create a record for the file")
+	// the route lists every record unless a page is asked for
+	if !paged(c) {
+		records, err := utils.ListAllRecords(c.UserContext(), filter)
+		if err != nil {
+			return sendLegacy(c, internal(api.CodeRecordList, "Failed to get the list of records", err))
+		}
+		return c.JSON(records)
 	}
 
-	//Split sentences and upload them to the db
-	err = utils.SplitAndStore(ctx, text, insertedRecord)
+	records, next, err := utils.ListRecordsPage(c.UserContext(), filter)
 	if err != nil {
-		return utils.SendErrorStatus(c, err.Error())
+		return sendLegacy(c, pageFailure(err, api.CodeRecordList, "Failed to get the list of records"))
 	}
 
-	return c.JSON(fiber.Map{"Id": insertedRecord.ID, "File": insertedRecord.Name})
-}
-
-func ListFiles(c *fiber.Ctx) error {
-	records, err := utils.ListRecords(c.Context())
-	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to get the list of records")
+	if next != "" {
+		c.Set(HeaderNextCursor, next)
 	}
-
 	return c.JSON(records)
 }
 
 func SearchKeyword(c *fiber.Ctx) error {
 	keyword := c.Params("key")
 	if keyword == "" {
-		return utils.SendBadRequestStatus(c, "Invalid keyword")
+		return utils.SendBadRequestStatus(c, api.CodeInvalidKeyword, "Invalid keyword")
 	}
 
-	ctx := c.Context()
-	records, err := utils.ListRecords(ctx)
+	defer metrics.ObserveSearch("keyword")()
+
+	matches, _, err := utils.Search(c.UserContext(), keyword, utils.SearchOptions{GroupByDocument: true})
 	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to get the list of records")
+		return utils.SendErrorStatus(c, api.CodeSentenceList, "Failed to search the sentences of the records", err)
 	}
 
 	result := fiber.Map{}
-	for _, record := range records {
-		sentences, err := utils.ListRecordSentences(ctx, record.ID)
-		if err != nil {
-			return utils.SendErrorStatus(c, "Failed to get the list of sentences for record: "+fmt.Sprint(record.ID))
-		}
-
+	for _, match := range matches {
+		// sentences are numbered by rank, "1" is the most relevant one
 		containers := fiber.Map{}
-		i := 1
-		for _, sentence := range sentences {
-
-			isContained := false
-			for _, word := range strings.Split(strings.TrimSpace(sentence.Sentence), " ") {
-				if strings.ToLower(word) == strings.ToLower(keyword) {
-					isContained = true
-					break
-				}
-			}
-			if isContained {
-				containers[fmt.Sprint(i)] = sentence.Sentence
-				i++
-			}
-		}
-
-		if len(containers) > 0 {
-			result[fmt.Sprintf("PDF ID %d", record.ID)] = containers
+		for i, sentence := range match.Sentences {
+			containers[fmt.Sprint(i+1)] = sentence.Sentence
 		}
+		result[fmt.Sprintf("PDF ID %d", match.Record.ID)] = containers
 	}
 
 	if len(result) == 0 {
@@ -122,12 +96,13 @@ func SearchKeyword(c *fiber.Ctx) error {
 func GetPDF(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id", -1)
 	if err != nil || id == -1 {
-		return utils.SendBadRequestStatus(c, "Invalid id provided")
+		return utils.SendBadRequestStatus(c, api.CodeInvalidID, "Invalid id provided")
 	}
+	ctx := withRecord(c, id)
 
-	localFile, err := utils.CopyPDF(id)
-	if err != nil {
-		return utils.SendErrorStatus(c, localFile)
+	localFile, f := copyDocument(ctx, id)
+	if f != nil {
+		return sendLegacy(c, f)
 	}
 	defer os.Remove(localFile)
 
@@ -137,53 +112,58 @@ func GetPDF(c *fiber.Ctx) error {
 func ListSentences(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id", -1)
 	if err != nil || id == -1 {
-		return utils.SendBadRequestStatus(c, "Id invalid or not provided")
+		return utils.SendBadRequestStatus(c, api.CodeInvalidID, "Id invalid or not provided")
 	}
 
-	ctx := c.Context()
-	sentences, err := utils.ListRecordSentences(ctx, int32(id))
+	limit, f := queryLimit(c)
+	if f != nil {
+		return sendLegacy(c, f)
+	}
+
+	ctx := withRecord(c, id)
+	if !paged(c) {
+		sentences, err := utils.ListRecordSentences(ctx, int32(id))
+		if err != nil {
+			return sendLegacy(c, internal(api.CodeSentenceList, "Failed to retrieve the list of sentences for the selected file", err))
+		}
+		return c.JSON(sentences)
+	}
+
+	sentences, next, err := utils.ListRecordSentencesPage(ctx, int32(id), c.Query("cursor"), limit)
 	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to retrieve the list of sentences for the selected file")
+		return sendLegacy(c, pageFailure(err, api.CodeSentenceList, "Failed to retrieve the list of sentences for the selected file"))
 	}
 
+	if next != "" {
+		c.Set(HeaderNextCursor, next)
+	}
 	return c.JSON(sentences)
 }
 
 func GetOccurrence(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id", -1)
 	if id == -1 || err != nil {
-		return utils.SendBadRequestStatus(c, "Id invalid or not provided")
+		return utils.SendBadRequestStatus(c, api.CodeInvalidID, "Id invalid or not provided")
 	}
 
 	keyword := c.Params("key")
 	if keyword == "" {
-		return utils.SendBadRequestStatus(c, "Keyword invalid or not provided")
+		return utils.SendBadRequestStatus(c, api.CodeInvalidKeyword, "Keyword invalid or not provided")
 	}
 
-	ctx := c.Context()
+	defer metrics.ObserveSearch("occurrence")()
+
+	ctx := withRecord(c, id)
 	sentences, err := utils.ListRecordSentences(ctx, int32(id))
 	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to retrieve the list of sentences for the selected file")
+		return utils.SendErrorStatus(c, api.CodeSentenceList, "Failed to retrieve the list of sentences for the selected file", err)
 	}
 
-	count := 0
+	count, indexes := utils.FindOccurrences(sentences, keyword)
 	result := fiber.Map{}
 	foundIn := map[string]string{}
-	for index, sentence := range sentences {
-		reg := regexp.MustCompile("[^a-zA-Z\\s-_/]+")
-		rep := reg.ReplaceAllString(sentence.Sentence, " ")
-		words := strings.Split(rep, " ")
-		found := false
-		for _, word := range words {
-
-			if strings.ToLower(word) == strings.ToLower(keyword) {
-				count++
-				found = true
-			}
-		}
-		if found {
-			foundIn[fmt.Sprintf("Sentence %d", index)] = sentence.Sentence
-		}
+	for _, index := range indexes {
+		foundIn[fmt.Sprintf("Sentence %d", index)] = sentences[index].Sentence
 	}
 
 	result["Count"] = count
@@ -195,51 +175,18 @@ func GetOccurrence(c *fiber.Ctx) error {
 func GetMostOccurring(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id", -1)
 	if id == -1 || err != nil {
-		return utils.SendBadRequestStatus(c, "Id invalid or not provided")
+		return utils.SendBadRequestStatus(c, api.CodeInvalidID, "Id invalid or not provided")
 	}
 
-	ctx := c.Context()
-	sentences, err := utils.ListRecordSentences(ctx, int32(id))
+	ctx := withRecord(c, id)
+	terms, err := utils.DocumentFrequencies(ctx, int32(id), utils.FrequencyQuery{FrequencyOptions: legacyFrequencyOptions})
 	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to retrieve the list of sentences for the selected fil")
+		return utils.SendErrorStatus(c, api.CodeSentenceList, "Failed to retrieve the list of sentences for the selected file", err)
 	}
 
-	text := ""
-	for _, sentence := range sentences {
-		trim := strings.TrimSpace(sentence.Sentence)
-		if trim != "" {
-			text += trim + " "
-		}
-	}
-
-	trim := strings.TrimSpace(text)
-	filteredWords := utils.CountFilteredWords(trim)
 	topFive := map[int]string{}
-
-	for key, value := range filteredWords {
-		if topFive[1] == "" || value > utils.ExtractFrequency(topFive[1]) {
-			utils.ShiftAndUpdateTopFive(1, key, value, &topFive)
-			continue
-		}
-
-		if value > utils.ExtractFrequency(topFive[2]) {
-			utils.ShiftAndUpdateTopFive(2, key, value, &topFive)
-			continue
-		}
-
-		if value > utils.ExtractFrequency(topFive[3]) {
-			utils.ShiftAndUpdateTopFive(3, key, value, &topFive)
-			continue
-		}
-
-		if value > utils.ExtractFrequency(topFive[4]) {
-			utils.ShiftAndUpdateTopFive(4, key, value, &topFive)
-			continue
-		}
-
-		if value > utils.ExtractFrequency(topFive[5]) {
-			utils.ShiftAndUpdateTopFive(5, key, value, &topFive)
-		}
+	for i, term := range terms {
+		topFive[i+1] = fmt.Sprintf("%s: %d times", term.Term, term.Count)
 	}
 
 	return c.JSON(topFive)
@@ -248,25 +195,23 @@ func GetMostOccurring(c *fiber.Ctx) error {
 func GetPdfPage(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id", -1)
 	if err != nil || id == -1 {
-		return utils.SendBadRequestStatus(c, "Invalid id provided")
+		return utils.SendBadRequestStatus(c, api.CodeInvalidID, "Invalid id provided")
 	}
 
 	page, err := c.ParamsInt("page", -1)
 	if err != nil || page <= -1 {
-		return utils.SendBadRequestStatus(c, "Page invalid")
+		return utils.SendBadRequestStatus(c, api.CodeInvalidPage, "Page invalid")
 	}
+	ctx := withRecord(c, id)
 
-	localFile, err := utils.CopyPDF(id)
-	if err != nil {
-		return utils.SendErrorStatus(c, localFile)
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendLegacy(c, f)
 	}
-	defer os.Remove(localFile)
 
-	imageFile := "output.png"
-
-	err = utils.ConvertPDFPageToImage(localFile, imageFile, page)
-	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to convert pdf page to an image")
+	imageFile, f := renderPage(ctx, record, page)
+	if f != nil {
+		return sendLegacy(c, f)
 	}
 	defer os.Remove(imageFile)
 
@@ -277,54 +222,63 @@ func DeleteFile(c *fiber.Ctx) error {
 	in := c.FormValue("id")
 	id, err := strconv.Atoi(in)
 	if err != nil {
-		return utils.SendBadRequestStatus(c, "Invalid input")
+		return utils.SendBadRequestStatus(c, api.CodeInvalidInput, "Invalid input")
 	}
 
 	if id <= -1 {
-		return utils.SendBadRequestStatus(c, "Invalid id")
+		return utils.SendBadRequestStatus(c, api.CodeInvalidID, "Invalid id")
 	}
 
-	ctx := c.Context()
-	record, err := utils.GetRecord(ctx, int32(id))
-	if err != nil {
-		return utils.SendErrorStatus(c, "Failed to get the pdf details from the database")
-	}
-
-	pdfName := record.Name
-	err = utils.DeletePDF(ctx, pdfName)
-	if err != nil {
-		switch err.Error() {
-		case "minio":
-			return utils.SendErrorStatus(c, "Failed to delete the file from MinIO")
-		case "db":
-			return utils.SendErrorStatus(c, "Failed to delete the file details from the database")
-		}
+	ctx := withRecord(c, id)
+	if f := deleteDocument(ctx, id); f != nil {
+		return sendLegacy(c, f)
 	}
 
 	return c.JSON(fiber.Map{"success": "pdf deleted without errors"})
 }
 
+// withRecord tags the request logger with the record id being worked on
+func withRecord(c *fiber.Ctx, id int) context.Context {
+	ctx := logging.WithField(c.UserContext(), "record_id", id)
+	c.SetUserContext(ctx)
+	return ctx
+}
+
 func BasicAuthMiddleware(username, password string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		log := logging.FromContext(c.UserContext())
 		auth := c.Get(fiber.HeaderAuthorization)
 		if auth == "" {
-			c.Status(fiber.StatusUnauthorized)
-			return c.SendString("Unauthorized")
+			log.Info("missing credentials")
+			return unauthorized(c, "Unauthorized")
 		}
 
 		encodedCredentials := strings.TrimPrefix(auth, "Basic ")
 		credentials, err := base64.StdEncoding.DecodeString(encodedCredentials)
 		if err != nil {
-			c.Status(fiber.StatusUnauthorized)
-			return c.SendString("Unauthorized\n")
+			log.WithError(err).Info("malformed credentials")
+			return unauthorized(c, "Unauthorized\n")
 		}
 
 		credentialsParts := strings.SplitN(string(credentials), ":", 2)
 		if len(credentialsParts) != 2 || credentialsParts[0] != username || credentialsParts[1] != password {
-			c.Status(fiber.StatusUnauthorized)
-			return c.SendString("Unauthorized\n")
+			log.WithField("user", credentialsParts[0]).Warn("invalid credentials")
+			return unauthorized(c, "Unauthorized\n")
 		}
 
+		c.Locals("user", credentialsParts[0])
+		c.SetUserContext(logging.WithField(c.UserContext(), "user", credentialsParts[0]))
+
 		return c.Next()
 	}
 }
+
+// unauthorized answers with the error envelope on /api routes and plain text on the legacy ones
+func unauthorized(c *fiber.Ctx, legacy string) error {
+	if strings.HasPrefix(c.Path(), "/api/") {
+		return sendError(c, fiber.StatusUnauthorized, api.CodeUnauthorized, "Valid basic auth credentials are required", nil)
+	}
+
+	c.Status(fiber.StatusUnauthorized)
+	return c.SendString(legacy)
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/api"
+	"main/database"
+	"main/extract"
+	"main/logging"
+	"main/metrics"
+	"main/utils"
+	"mime/multipart"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// failure describes why a request could not be completed
+type failure struct {
+	status  int
+	code    string
+	message string
+	err     error
+}
+
+func badRequest(code, message string) *failure {
+	return &failure{status: fiber.StatusBadRequest, code: code, message: message}
+}
+
+func internal(code, message string, err error) *failure {
+	return &failure{status: fiber.StatusInternalServerError, code: code, message: message, err: err}
+}
+
+// logFailure logs a failure that is not answered on its own, such as that of a file of a batch
+func logFailure(ctx context.Context, f *failure) {
+	log := logging.FromContext(ctx).WithField("code", f.code)
+	if f.status >= fiber.StatusInternalServerError {
+		log.WithError(f.err).Error(f.message)
+	} else {
+		log.Info(f.message)
+	}
+}
+
+// sendLegacy answers a failure with the flat error body of the unversioned routes, keeping the
+// status of the failures of the request, such as a file too large or of an unsupported format
+func sendLegacy(c *fiber.Ctx, f *failure) error {
+	if f.status < fiber.StatusInternalServerError {
+		return utils.SendClientErrorStatus(c, f.status, f.code, f.message)
+	}
+	return utils.SendErrorStatus(c, f.code, f.message, f.err)
+}
+
+// extractFailure tells the files that cannot be read apart from the extraction errors
+func extractFailure(err error) *failure {
+	switch {
+	case errors.Is(err, extract.ErrEncrypted):
+		return &failure{status: fiber.StatusUnprocessableEntity, code: api.CodePDFEncrypted, message: "The pdf is encrypted", err: err}
+	case errors.Is(err, extract.ErrUnreadable):
+		return &failure{status: fiber.StatusUnprocessableEntity, code: api.CodePDFUnreadable, message: "The file is not a readable document", err: err}
+	}
+	return internal(api.CodePDFExtractFailed, "Failed to read pdf as text", err)
+}
+
+// formFile returns the uploaded file, sent as "file" or as "pdf" by the clients written when only pdfs were accepted
+func formFile(c *fiber.Ctx) (*multipart.FileHeader, error) {
+	file, err := c.FormFile("file")
+	if err == nil {
+		return file, nil
+	}
+	return c.FormFile("pdf")
+}
+
+// unsupportedFormat answers the uploads of a format no extractor reads
+func unsupportedFormat(mimeType string, err error) *failure {
+	return &failure{
+		status:  fiber.StatusUnsupportedMediaType,
+		code:    api.CodeUnsupportedFormat,
+		message: fmt.Sprintf("Files of type %s are not supported, upload a PDF, plain text, Markdown, HTML, DOCX or EPUB file", mimeType),
+		err:     err,
+	}
+}
+
+// pdfOnly answers the requests for what only a pdf has, such as the image of a page
+func pdfOnly(record database.Record) *failure {
+	if record.Mimetype == extract.MIMEPDF {
+		return nil
+	}
+	return &failure{
+		status:  fiber.StatusUnprocessableEntity,
+		code:    api.CodeDocumentNotPDF,
+		message: fmt.Sprintf("The document is a %s file, only PDFs have this", record.Mimetype),
+	}
+}
+
+// validationFailure tells the uploads rejected by the policy apart from the failed checks
+func validationFailure(err error) *failure {
+	switch {
+	case errors.Is(err, utils.ErrUploadEmpty):
+		return &failure{status: fiber.StatusBadRequest, code: api.CodeUploadEmpty, message: "The uploaded file is empty", err: err}
+	case errors.Is(err, utils.ErrUploadTooLarge):
+		return &failure{status: fiber.StatusRequestEntityTooLarge, code: api.CodeUploadTooLarge, message: fmt.Sprintf("The uploaded file is larger than the %d bytes accepted", utils.GetUploadPolicy().MaxSize), err: err}
+	case errors.Is(err, utils.ErrUploadTooManyPages):
+		return &failure{status: fiber.StatusUnprocessableEntity, code: api.CodeUploadTooManyPages, message: fmt.Sprintf("The document has more than the %d pages accepted", utils.GetUploadPolicy().MaxPages), err: err}
+	case errors.Is(err, utils.ErrUploadMismatch):
+		return &failure{status: fiber.StatusUnsupportedMediaType, code: api.CodeUploadTypeMismatch, message: "The file was sent as a pdf but its content is not one", err: err}
+	case errors.Is(err, utils.ErrUploadEncrypted):
+		return &failure{status: fiber.StatusUnprocessableEntity, code: api.CodePDFEncrypted, message: "Encrypted pdfs are not accepted", err: err}
+	case errors.Is(err, utils.ErrUploadJavaScript):
+		return &failure{status: fiber.StatusUnprocessableEntity, code: api.CodePDFJavaScript, message: "Pdfs carrying JavaScript are not accepted", err: err}
+	case errors.Is(err, extract.ErrEncrypted):
+		return &failure{status: fiber.StatusUnprocessableEntity, code: api.CodePDFEncrypted, message: "The pdf is encrypted", err: err}
+	case errors.Is(err, extract.ErrUnreadable):
+		return &failure{status: fiber.StatusUnprocessableEntity, code: api.CodePDFInvalid, message: "The structure of the pdf is invalid", err: err}
+	}
+	return internal(api.CodeUploadOpenFailed, "Failed to validate the uploaded file", err)
+}
+
+// upload is a file received for ingestion and saved locally
+type upload struct {
+	// name is the sanitized name of the file, shown as the name of the document, the file is
+	// stored under a generated key
+	name string
+	path string
+	size int64
+	// contentType is the type declared by the client, checked against the content of pdfs
+	contentType string
+	// declaredName is the name sent by the client
+	declaredName string
+}
+
+// ingestUpload saves the uploaded file to a local copy and ingests it
+func ingestUpload(c *fiber.Ctx, file *multipart.FileHeader) (database.Record, *failure) {
+	name := utils.SanitizeFilename(file.Filename)
+	ctx := logging.WithField(c.UserContext(), "file", name)
+	c.SetUserContext(ctx)
+
+	if err := utils.CheckUploadSize(file.Size); err != nil {
+		return database.Record{}, validationFailure(err)
+	}
+
+	local, err := saveUpload(c, file)
+	if err != nil {
+		return database.Record{}, internal(api.CodeUploadSaveFailed, "Failed to save the uploaded file", err)
+	}
+
+	record, f := ingestFile(ctx, upload{
+		name:         name,
+		path:         local,
+		size:         file.Size,
+		contentType:  file.Header.Get(fiber.HeaderContentType),
+		declaredName: file.Filename,
+	})
+	if f == nil {
+		c.SetUserContext(logging.WithField(ctx, "record_id", record.ID))
+	}
+	return record, f
+}
+
+// saveUpload saves an uploaded file to a local file the caller must remove
+func saveUpload(c *fiber.Ctx, file *multipart.FileHeader) (string, error) {
+	defer metrics.ObserveStage(metrics.StageSave)()
+
+	// the client name is neither a local path nor trusted to tell the format
+	local, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return "", err
+	}
+	local.Close()
+	if err := c.SaveFile(file, local.Name()); err != nil {
+		os.Remove(local.Name())
+		return "", err
+	}
+	return local.Name(), nil
+}
+
+// ingestFile validates a local file, extracts its sentences, stores it and records it in the
+// database. Nothing is stored before the file passed the checks of the upload policy, nor kept
+// when it cannot be recorded. The local file is removed once done.
+func ingestFile(ctx context.Context, u upload) (database.Record, *failure) {
+	start := time.Now()
+	defer os.Remove(u.path)
+
+	mimeType, err := utils.DetectFormat(u.path)
+	if err != nil {
+		if errors.Is(err, extract.ErrUnsupportedFormat) {
+			return database.Record{}, unsupportedFormat(mimeType, err)
+		}
+		return database.Record{}, internal(api.CodeUploadOpenFailed, "Failed to read the uploaded file", err)
+	}
+	ctx = logging.WithField(ctx, "format", mimeType)
+	if err := utils.CheckDeclaredType(u.declaredName, u.contentType, mimeType); err != nil {
+		return database.Record{}, validationFailure(err)
+	}
+
+	// MuPDF tells the formats apart by the extension of the files
+	localFile := u.path + extract.Extension(mimeType)
+	if err := os.Rename(u.path, localFile); err != nil {
+		return database.Record{}, internal(api.CodeUploadSaveFailed, "Failed to save the uploaded file", err)
+	}
+	defer os.Remove(localFile)
+
+	if mimeType == extract.MIMEPDF {
+		if _, err := utils.InspectPDF(ctx, localFile); err != nil {
+			return database.Record{}, validationFailure(err)
+		}
+	}
+
+	document, err := utils.ReadDocument(ctx, localFile, mimeType)
+	if err != nil {
+		return database.Record{}, extractFailure(err)
+	}
+	if err := utils.CheckPageCount(len(document.Pages)); err != nil {
+		return database.Record{}, validationFailure(err)
+	}
+
+	x := utils.ExtractPages(ctx, localFile, mimeType, document)
+
+	inFile, err := os.Open(localFile)
+	if err != nil {
+		return database.Record{}, internal(api.CodeUploadOpenFailed, "Failed to open the uploaded file", err)
+	}
+	defer inFile.Close()
+
+	insertedRecord, err := utils.StoreDocument(ctx, u.name, inFile, u.size, mimeType, x)
+	if err != nil {
+		if errors.Is(err, utils.ErrStorage) {
+			return database.Record{}, internal(api.CodeStorageUpload, "Failed to upload the file to MinIO", err)
+		}
+		return database.Record{}, internal(api.CodeRecordCreate, "Failed to record the file and its sentences, nothing was kept", err)
+	}
+	ctx = logging.WithField(ctx, "record_id", insertedRecord.ID)
+
+	logging.FromContext(ctx).WithFields(logrus.Fields{
+		"pages":       insertedRecord.Numofpages,
+		"size":        insertedRecord.Size,
+		"language":    insertedRecord.Language,
+		"extractor":   document.Extractor,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}).Info("document ingested")
+
+	return insertedRecord, nil
+}
+
+// copyDocument downloads the pdf of a record to a local file the caller must remove
+func copyDocument(ctx context.Context, id int) (string, *failure) {
+	localFile, err := utils.CopyPDF(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", &failure{status: fiber.StatusNotFound, code: api.CodeDocumentNotFound, message: localFile, err: err}
+		}
+		return "", internal(api.CodePDFCopyFailed, localFile, err)
+	}
+	return localFile, nil
+}
+
+// getRecord returns the record of a document
+func getRecord(ctx context.Context, id int) (database.Record, *failure) {
+	record, err := utils.GetRecord(ctx, int32(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return record, &failure{status: fiber.StatusNotFound, code: api.CodeDocumentNotFound, message: "A file with the id provided does not exist", err: err}
+		}
+		return record, internal(api.CodeRecordGet, "Failed to get the pdf details from the database", err)
+	}
+	return record, nil
+}
+
+// renderPage renders a page of a record to a local image the caller must remove
+func renderPage(ctx context.Context, record database.Record, page int) (string, *failure) {
+	if f := pdfOnly(record); f != nil {
+		return "", f
+	}
+	localFile, f := copyDocument(ctx, int(record.ID))
+	if f != nil {
+		return "", f
+	}
+	defer os.Remove(localFile)
+
+	image, err := os.CreateTemp("", "page-*.jpg")
+	if err != nil {
+		return "", internal(api.CodePageRenderFailed, "Failed to convert pdf page to an image", err)
+	}
+	image.Close()
+
+	err = utils.ConvertPDFPageToImage(ctx, localFile, image.Name(), page)
+	if err != nil {
+		os.Remove(image.Name())
+		return "", internal(api.CodePageRenderFailed, "Failed to convert pdf page to an image", err)
+	}
+
+	return image.Name(), nil
+}
+
+// deleteDocument removes the pdf of a record from the storage and the database
+func deleteDocument(ctx context.Context, id int) *failure {
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return f
+	}
+	return deleteRecord(ctx, record)
+}
+
+// deleteRecord removes a record by its id and its file by its key, neither shared with the
+// documents of the same name
+func deleteRecord(ctx context.Context, record database.Record) *failure {
+	err := utils.DeletePDF(ctx, record)
+	if err != nil {
+		if errors.Is(err, utils.ErrStorage) {
+			return internal(api.CodeStorageDelete, "Failed to delete the file from MinIO", err)
+		}
+		return internal(api.CodeRecordDelete, "Failed to delete the file details from the database", err)
+	}
+
+	return nil
+}
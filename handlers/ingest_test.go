@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"main/api"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestSendLegacy keeps the status of the failures of the request and answers the others with a 500
+func TestSendLegacy(t *testing.T) {
+	tests := []*failure{
+		badRequest(api.CodeInvalidID, "Invalid id provided"),
+		{status: fiber.StatusNotFound, code: api.CodeDocumentNotFound, message: "A file with the id provided does not exist"},
+		{status: fiber.StatusRequestEntityTooLarge, code: api.CodeUploadTooLarge, message: "The file is too large"},
+		unsupportedFormat("application/zip", errors.New("no extractor")),
+		{status: fiber.StatusUnprocessableEntity, code: api.CodePDFEncrypted, message: "The pdf is encrypted"},
+		internal(api.CodeRecordCreate, "Failed to create the record", errors.New("connection refused")),
+	}
+	for _, f := range tests {
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error { return sendLegacy(c, f) })
+		resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != f.status || body["code"] != f.code || body["error"] != f.message {
+			t.Errorf("%s: got %d %v, want %d with its code and message", f.code, resp.StatusCode, body, f.status)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"main/analytics"
+	"main/api"
+	"main/database"
+	"main/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderNextCursor carries the cursor of the next page on the legacy list routes
+const HeaderNextCursor = "X-Next-Cursor"
+
+var recordSorts = map[string]bool{
+	utils.SortName:       true,
+	utils.SortUploadTime: true,
+	utils.SortSize:       true,
+	utils.SortPages:      true,
+}
+
+func queryLimit(c *fiber.Ctx) (int, *failure) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, badRequest(api.CodeInvalidInput, "limit must be a positive integer")
+	}
+	return limit, nil
+}
+
+// paged tells whether a legacy list route is asked for a page rather than the whole list
+func paged(c *fiber.Ctx) bool {
+	return c.Query("limit") != "" || c.Query("cursor") != ""
+}
+
+func queryInt32(c *fiber.Ctx, name string) (*int32, *failure) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 32)
+	if err != nil || value < 0 {
+		return nil, badRequest(api.CodeInvalidInput, name+" must be a non negative integer")
+	}
+	result := int32(value)
+	return &result, nil
+}
+
+func queryInt64(c *fiber.Ctx, name string) (*int64, *failure) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value < 0 {
+		return nil, badRequest(api.CodeInvalidInput, name+" must be a non negative integer")
+	}
+	return &value, nil
+}
+
+func queryBool(c *fiber.Ctx, name string) (*bool, *failure) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, badRequest(api.CodeInvalidInput, name+" must be true or false")
+	}
+	return &value, nil
+}
+
+// queryTime accepts RFC 3339 timestamps or plain dates
+func queryTime(c *fiber.Ctx, name string) (*time.Time, *failure) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if value, err := time.Parse(layout, raw); err == nil {
+			value = value.UTC()
+			return &value, nil
+		}
+	}
+	return nil, badRequest(api.CodeInvalidInput, name+" must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+}
+
+// recordFilter reads the pagination, sorting and filtering query parameters of the record listings
+func recordFilter(c *fiber.Ctx) (utils.RecordFilter, *failure) {
+	filter := utils.RecordFilter{
+		Sort:       c.Query("sort", utils.SortName),
+		NamePrefix: c.Query("name_prefix"),
+		Title:      c.Query("title"),
+		Author:     c.Query("author"),
+		Keywords:   c.Query("keywords"),
+		Producer:   c.Query("producer"),
+		PDFVersion: c.Query("pdf_version"),
+		Cursor:     c.Query("cursor"),
+	}
+	if !recordSorts[filter.Sort] {
+		return filter, badRequest(api.CodeInvalidInput, "sort must be one of name, upload_time, size or pages")
+	}
+
+	switch strings.ToLower(c.Query("order", "asc")) {
+	case "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		return filter, badRequest(api.CodeInvalidInput, "order must be asc or desc")
+	}
+
+	var f *failure
+	if filter.Limit, f = queryLimit(c); f != nil {
+		return filter, f
+	}
+	if filter.UploadedAfter, f = queryTime(c, "uploaded_after"); f != nil {
+		return filter, f
+	}
+	if filter.UploadedBefore, f = queryTime(c, "uploaded_before"); f != nil {
+		return filter, f
+	}
+	if filter.MinSize, f = queryInt64(c, "min_size"); f != nil {
+		return filter, f
+	}
+	if filter.MaxSize, f = queryInt64(c, "max_size"); f != nil {
+		return filter, f
+	}
+	if filter.MinPages, f = queryInt32(c, "min_pages"); f != nil {
+		return filter, f
+	}
+	if filter.MaxPages, f = queryInt32(c, "max_pages"); f != nil {
+		return filter, f
+	}
+	if filter.Encrypted, f = queryBool(c, "encrypted"); f != nil {
+		return filter, f
+	}
+	if filter.CreatedAfter, f = queryTime(c, "created_after"); f != nil {
+		return filter, f
+	}
+	if filter.CreatedBefore, f = queryTime(c, "created_before"); f != nil {
+		return filter, f
+	}
+	outdated, f := queryBool(c, "outdated")
+	if f != nil {
+		return filter, f
+	}
+	filter.Outdated = outdated != nil && *outdated
+
+	return filter, nil
+}
+
+// pageFailure maps the errors of the paged listings
+func pageFailure(err error, code, message string) *failure {
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		return badRequest(api.CodeInvalidCursor, "The cursor is invalid or belongs to another sort order")
+	}
+	return internal(code, message, err)
+}
+
+// queryBoundedInt reads an integer query parameter between min and max
+func queryBoundedInt(c *fiber.Ctx, name string, fallback, min, max int) (int, *failure) {
+	raw := c.Query(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < min || value > max {
+		return 0, badRequest(api.CodeInvalidInput, fmt.Sprintf("%s must be an integer between %d and %d", name, min, max))
+	}
+	return value, nil
+}
+
+// queryFloat reads a number query parameter between min and max
+func queryFloat(c *fiber.Ctx, name string, fallback, min, max float64) (float64, *failure) {
+	raw := c.Query(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < min || value > max {
+		return 0, badRequest(api.CodeInvalidInput, fmt.Sprintf("%s must be a number between %g and %g", name, min, max))
+	}
+	return value, nil
+}
+
+// queryLanguage reads the lang query parameter, the stopword list to use. It is empty when
+// absent, the language detected for the document is used then.
+func queryLanguage(c *fiber.Ctx) (string, *failure) {
+	language := strings.ToLower(c.Query("lang"))
+	if language != "" && !analytics.StopwordLanguages[language] {
+		return "", badRequest(api.CodeInvalidInput, "lang must be the ISO 639-1 code of a supported stopword language")
+	}
+	return language, nil
+}
+
+// documentLanguage resolves an empty language to that of the document
+func documentLanguage(record database.Record, language string) string {
+	if language == "" {
+		return utils.RecordLanguage(record)
+	}
+	return language
+}
+
+// keywordOptions reads the parameters of the keyword extraction
+func keywordOptions(c *fiber.Ctx) (analytics.KeywordOptions, *failure) {
+	options := analytics.KeywordOptions{Weighting: analytics.Weighting(c.Query("weighting", string(analytics.WeightingTFIDF)))}
+	if options.Weighting != analytics.WeightingTFIDF && options.Weighting != analytics.WeightingBM25 {
+		return options, badRequest(api.CodeInvalidInput, "weighting must be tfidf or bm25")
+	}
+
+	var f *failure
+	if options.Language, f = queryLanguage(c); f != nil {
+		return options, f
+	}
+	if options.N, f = queryBoundedInt(c, "n", 10, 1, 100); f != nil {
+		return options, f
+	}
+	if options.MinLength, f = queryBoundedInt(c, "min_length", 2, 1, 50); f != nil {
+		return options, f
+	}
+	return options, nil
+}
+
+// Values of the group query parameter of the search
+const (
+	searchGroupDocument = "document"
+	searchGroupSentence = "sentence"
+)
+
+// searchOptions reads the grouping and size parameters of the search
+func searchOptions(c *fiber.Ctx) (utils.SearchOptions, *failure) {
+	options := utils.SearchOptions{}
+	switch c.Query("group", searchGroupDocument) {
+	case searchGroupDocument:
+		options.GroupByDocument = true
+	case searchGroupSentence:
+	default:
+		return options, badRequest(api.CodeInvalidInput, "group must be document or sentence")
+	}
+
+	var f *failure
+	if options.K, f = queryBoundedInt(c, "k", 3, 1, 100); f != nil {
+		return options, f
+	}
+	if options.Limit, f = queryBoundedInt(c, "limit", 20, 1, 100); f != nil {
+		return options, f
+	}
+	return options, nil
+}
+
+// entityQuery reads the entity looked up by the /entities routes
+func entityQuery(c *fiber.Ctx) (utils.EntityQuery, *failure) {
+	query := utils.EntityQuery{
+		Text:  strings.TrimSpace(c.Query("text")),
+		Label: strings.ToUpper(c.Query("label")),
+	}
+	if query.Text == "" {
+		return query, badRequest(api.CodeInvalidInput, "The text query parameter is required")
+	}
+
+	var f *failure
+	query.Limit, f = queryBoundedInt(c, "limit", utils.DefaultPageSize, 1, utils.MaxPageSize)
+	return query, f
+}
+
+// summaryLength reads the length of a summary, as sentences or as a percentage of the document
+func summaryLength(c *fiber.Ctx) (utils.SummaryLength, *failure) {
+	if c.Query("sentences") != "" && c.Query("percent") != "" {
+		return utils.SummaryLength{}, badRequest(api.CodeInvalidInput, "Only one of sentences and percent can be set")
+	}
+
+	length := utils.SummaryLength{}
+	var f *failure
+	if length.Percent, f = queryBoundedInt(c, "percent", 0, 1, 100); f != nil || length.Percent > 0 {
+		return length, f
+	}
+	length.Sentences, f = queryBoundedInt(c, "sentences", 5, 1, utils.MaxPageSize)
+	return length, f
+}
+
+// frequencyQuery reads the parameters of the frequency analytics
+func frequencyQuery(c *fiber.Ctx) (utils.FrequencyQuery, *failure) {
+	query := utils.FrequencyQuery{}
+	var f *failure
+	if query.Language, f = queryLanguage(c); f != nil {
+		return query, f
+	}
+	if query.N, f = queryBoundedInt(c, "n", 10, 1, 100); f != nil {
+		return query, f
+	}
+	if query.NGram, f = queryBoundedInt(c, "ngram", 1, 1, 3); f != nil {
+		return query, f
+	}
+	if query.MinLength, f = queryBoundedInt(c, "min_length", 2, 1, 50); f != nil {
+		return query, f
+	}
+	if query.PageFrom, f = queryInt32(c, "page_from"); f != nil {
+		return query, f
+	}
+	if query.PageTo, f = queryInt32(c, "page_to"); f != nil {
+		return query, f
+	}
+	if query.PageFrom != nil && query.PageTo != nil && *query.PageFrom > *query.PageTo {
+		return query, badRequest(api.CodeInvalidInput, "page_from must not be after page_to")
+	}
+
+	return query, nil
+}
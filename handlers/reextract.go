@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"main/api"
+	"main/database"
+	"main/logging"
+	"main/utils"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v4"
+)
+
+// reextractFailure tells the missing documents, the files the upload policy rejects now and the
+// failed copies and stores apart from the files that cannot be read anymore
+func reextractFailure(err error) *failure {
+	switch {
+	case errors.Is(err, utils.ErrUploadTooManyPages) || errors.Is(err, utils.ErrUploadEncrypted) || errors.Is(err, utils.ErrUploadJavaScript):
+		return validationFailure(err)
+	case errors.Is(err, pgx.ErrNoRows):
+		return &failure{status: fiber.StatusNotFound, code: api.CodeDocumentNotFound, message: "Document not found", err: err}
+	case errors.Is(err, utils.ErrStorage):
+		return internal(api.CodePDFCopyFailed, "Failed to get the file of the document from MinIO", err)
+	case errors.Is(err, utils.ErrDatabase):
+		return internal(api.CodeSentenceStore, "Failed to replace the sentences of the document, the previous ones are kept", err)
+	}
+	return extractFailure(err)
+}
+
+// ReextractDocument extracts the stored file of a document again, with the current extractors,
+// and replaces its sentences and everything read from its text at once
+func ReextractDocument(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	record, err := utils.Reextract(c.UserContext(), int32(id))
+	if err != nil {
+		return sendFailure(c, reextractFailure(err))
+	}
+	return c.JSON(api.NewDocument(record))
+}
+
+// ReextractDocuments extracts again the documents matching the filters of the listing, the
+// whole corpus without any when all=true is set, and lists the outcome of every document.
+// outdated=true keeps those extracted with an older version.
+func ReextractDocuments(c *fiber.Ctx) error {
+	filter, f := recordFilter(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	if filter.Empty() && !c.QueryBool("all") {
+		return sendFailure(c, badRequest(api.CodeInvalidInput, "Filter the documents to re-extract, or set all=true to re-extract every document"))
+	}
+
+	ctx := c.UserContext()
+	result := api.ReextractResult{Results: []api.ReextractItem{}}
+	var mu sync.Mutex
+	err := utils.ReextractRecords(ctx, filter, utils.GetUploadPolicy().BatchWorkers, func(record database.Record, err error) {
+		item := api.ReextractItem{Document: api.NewDocument(record)}
+		if err != nil {
+			f := reextractFailure(err)
+			logFailure(logging.WithField(ctx, "record_id", record.ID), f)
+			item.Error = &api.Error{Code: f.code, Message: f.message}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if item.Error != nil {
+			result.Failed++
+		} else {
+			result.Reextracted++
+		}
+		result.Results = append(result.Results, item)
+	})
+	if err != nil {
+		return sendFailure(c, pageFailure(err, api.CodeRecordList, "Failed to list the documents to re-extract"))
+	}
+
+	status := fiber.StatusOK
+	if result.Failed > 0 {
+		status = fiber.StatusMultiStatus
+	}
+	return c.Status(status).JSON(result)
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"main/api"
+	"main/database"
+	"main/logging"
+	"main/storage"
+	"main/utils"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// The resumable uploads follow the tus protocol, version 1.0.0, with its creation, checksum,
+// termination and expiration extensions. The file is sent in chunks, each stored as a part of a
+// multipart upload, and ingested once its last byte is received.
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,checksum,termination,expiration"
+	// tusChunkType is the content type of the chunks
+	tusChunkType = "application/offset+octet-stream"
+	// statusChecksumMismatch is the status tus answers a chunk or file that fails its checksum with
+	statusChecksumMismatch = 460
+)
+
+// tusHeaders sets the headers of every tus response and rejects the other versions of the
+// protocol, required is set for the requests that must name theirs
+func tusHeaders(c *fiber.Ctx, required bool) *failure {
+	c.Set("Tus-Resumable", tusVersion)
+	version := c.Get("Tus-Resumable")
+	if version == tusVersion || (version == "" && !required) {
+		return nil
+	}
+	c.Set("Tus-Version", tusVersion)
+	return &failure{status: fiber.StatusPreconditionFailed, code: api.CodeTusVersion, message: "Only version " + tusVersion + " of the tus protocol is supported"}
+}
+
+// uploadHeaders sets the tus headers telling the state of an upload
+func uploadHeaders(c *fiber.Ctx, upload database.Upload) {
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Received, 10))
+	c.Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	c.Set("Upload-Expires", upload.Expiresat.UTC().Format(http.TimeFormat))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+}
+
+// uploadMetadata parses the Upload-Metadata header: comma separated keys, each followed by its
+// value encoded in base64 when it has one
+func uploadMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("value of %s: %w", key, err)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// uploadFailure tells the chunks and uploads rejected by the protocol from the failed operations
+func uploadFailure(err error, code, message string) *failure {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return &failure{status: fiber.StatusNotFound, code: api.CodeUploadNotFound, message: "The upload does not exist or expired", err: err}
+	case errors.Is(err, utils.ErrUploadOffset):
+		return &failure{status: fiber.StatusConflict, code: api.CodeUploadOffset, message: "The offset is not that of the bytes received, send a HEAD request to resume", err: err}
+	case errors.Is(err, utils.ErrUploadLocked):
+		return &failure{status: fiber.StatusLocked, code: api.CodeUploadLocked, message: "Another chunk of the upload is being received", err: err}
+	case errors.Is(err, utils.ErrUploadLength):
+		return &failure{status: fiber.StatusBadRequest, code: api.CodeUploadLength, message: "The chunk ends past the length of the upload", err: err}
+	case errors.Is(err, utils.ErrUploadChunkSize):
+		return &failure{status: fiber.StatusBadRequest, code: api.CodeUploadChunkTooSmall, message: fmt.Sprintf("The chunks but the last one must be at least %d bytes long", storage.MinPartSize), err: err}
+	case errors.Is(err, utils.ErrUploadChecksum):
+		return &failure{status: statusChecksumMismatch, code: api.CodeUploadChecksum, message: "The checksum does not match the bytes received", err: err}
+	case errors.Is(err, utils.ErrUploadAlgorithm):
+		return &failure{status: fiber.StatusBadRequest, code: api.CodeInvalidInput, message: "The checksum algorithm is not one of " + strings.Join(utils.ChecksumAlgorithms, ", "), err: err}
+	case errors.Is(err, utils.ErrChecksumInvalid):
+		return &failure{status: fiber.StatusBadRequest, code: api.CodeInvalidInput, message: "The checksum must be an algorithm and a base64 digest separated by a space", err: err}
+	case errors.Is(err, utils.ErrUploadEmpty), errors.Is(err, utils.ErrUploadTooLarge):
+		return validationFailure(err)
+	}
+	return internal(code, message, err)
+}
+
+// getUpload returns the upload of the :id parameter, the expired ones are not found
+func getUpload(c *fiber.Ctx) (database.Upload, *failure) {
+	id := c.Params("id")
+	if _, err := uuid.Parse(id); err != nil {
+		return database.Upload{}, uploadFailure(pgx.ErrNoRows, "", "")
+	}
+	c.SetUserContext(logging.WithField(c.UserContext(), "upload_id", id))
+	upload, err := utils.GetUpload(c.UserContext(), id)
+	if err == nil && upload.Expiresat.Before(time.Now()) {
+		err = pgx.ErrNoRows
+	}
+	if err != nil {
+		return upload, uploadFailure(err, api.CodeUploadGet, "Failed to get the upload")
+	}
+	return upload, nil
+}
+
+// UploadOptions tells the tus clients the version, extensions and limits of the protocol
+func UploadOptions(c *fiber.Ctx) error {
+	tusHeaders(c, false)
+	c.Set("Tus-Version", tusVersion)
+	c.Set("Tus-Extension", tusExtensions)
+	c.Set("Tus-Max-Size", strconv.FormatInt(utils.GetUploadPolicy().MaxSize, 10))
+	c.Set("Tus-Checksum-Algorithm", strings.Join(utils.ChecksumAlgorithms, ","))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CreateUpload starts a resumable upload of Upload-Length bytes. Its Upload-Metadata names the
+// file with filename, and may give its type with filetype and its checksum with checksum, as
+// the algorithm and the base64 digest separated by a space.
+func CreateUpload(c *fiber.Ctx) error {
+	if f := tusHeaders(c, true); f != nil {
+		return sendFailure(c, f)
+	}
+	if c.Get("Upload-Defer-Length") != "" {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidInput, "The length of the upload must be known, Upload-Defer-Length is not supported", nil)
+	}
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidInput, "Upload-Length must be the size of the file in bytes", err)
+	}
+	metadata, err := uploadMetadata(c.Get("Upload-Metadata"))
+	if err != nil {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidInput, "Upload-Metadata must list keys and base64 values", err)
+	}
+
+	name := utils.SanitizeFilename(metadata["filename"])
+	ctx := logging.WithField(c.UserContext(), "file", name)
+	c.SetUserContext(ctx)
+	upload, err := utils.CreateUpload(ctx, name, metadata["filetype"], length, metadata["checksum"])
+	if err != nil {
+		return sendFailure(c, uploadFailure(err, api.CodeUploadCreate, "Failed to create the upload"))
+	}
+
+	uploadHeaders(c, upload)
+	c.Location("/api/v1/uploads/" + upload.ID)
+	return c.Status(fiber.StatusCreated).JSON(api.NewUpload(upload))
+}
+
+// GetUpload answers HEAD requests with the tus headers of the bytes received, for the clients
+// to resume from, and GET requests with the state of the upload and its document once ingested
+func GetUpload(c *fiber.Ctx) error {
+	tusHeaders(c, false)
+	upload, f := getUpload(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	uploadHeaders(c, upload)
+	return c.JSON(api.NewUpload(upload))
+}
+
+// WriteUpload stores a chunk of a resumable upload at its Upload-Offset, checked against its
+// Upload-Checksum when there is one. The file is ingested in the background once its last byte is
+// received, GET on the upload tells when it is done.
+func WriteUpload(c *fiber.Ctx) error {
+	if f := tusHeaders(c, true); f != nil {
+		return sendFailure(c, f)
+	}
+	upload, f := getUpload(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType)); mediaType != tusChunkType {
+		return sendError(c, fiber.StatusUnsupportedMediaType, api.CodeInvalidInput, "The chunks must be sent as "+tusChunkType, nil)
+	}
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidInput, "Upload-Offset must be the number of bytes received", err)
+	}
+
+	ctx := c.UserContext()
+	upload, err = utils.WriteUploadChunk(ctx, upload.ID, offset, c.Body(), c.Get("Upload-Checksum"))
+	if err != nil {
+		return sendFailure(c, uploadFailure(err, api.CodeStorageUpload, "Failed to store the chunk"))
+	}
+	uploadHeaders(c, upload)
+
+	if upload.Received == upload.Length {
+		localFile, err := utils.CompleteUpload(ctx, upload.ID)
+		if err != nil {
+			f := uploadFailure(err, api.CodeStorageUpload, "Failed to assemble the received file")
+			// a file whose checksum does not match keeps its parts, for the client to delete the upload
+			if !errors.Is(err, utils.ErrUploadLocked) && !errors.Is(err, utils.ErrUploadOffset) && !errors.Is(err, utils.ErrUploadChecksum) {
+				utils.FinishUpload(ctx, upload.ID, 0, f.code, f.message)
+			}
+			return sendFailure(c, f)
+		}
+		go ingestReceived(logging.Detach(ctx), upload, localFile)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ingestSlots bounds the received uploads ingested at once to the workers of the upload policy,
+// sized on the first upload received
+var (
+	ingestSlots     chan struct{}
+	ingestSlotsOnce sync.Once
+)
+
+// ingestReceived ingests the file of a received upload and records its outcome on the upload.
+// The upload stays ingesting while it waits for a free slot.
+func ingestReceived(ctx context.Context, received database.Upload, localFile string) {
+	ingestSlotsOnce.Do(func() {
+		ingestSlots = make(chan struct{}, utils.GetUploadPolicy().BatchWorkers)
+	})
+	ingestSlots <- struct{}{}
+	defer func() { <-ingestSlots }()
+
+	ctx = logging.WithField(ctx, "file", received.Name)
+	record, f := ingestFile(ctx, upload{
+		name:         received.Name,
+		path:         localFile,
+		size:         received.Length,
+		contentType:  received.Contenttype,
+		declaredName: received.Name,
+	})
+	if f != nil {
+		logFailure(ctx, f)
+		utils.FinishUpload(ctx, received.ID, 0, f.code, f.message)
+		return
+	}
+	utils.FinishUpload(ctx, received.ID, record.ID, "", "")
+}
+
+// FailInterruptedUploads fails the uploads whose ingestion was interrupted by the end of the
+// process, the clients polling them are told to upload their files again
+func FailInterruptedUploads(ctx context.Context) {
+	failed, err := utils.FailIngestingUploads(ctx, api.CodeUploadInterrupted, "The ingestion of the file was interrupted, upload it again")
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("failed to fail the interrupted uploads")
+		return
+	}
+	if failed > 0 {
+		logging.FromContext(ctx).WithField("uploads", failed).Warn("uploads interrupted while ingested were failed")
+	}
+}
+
+// DeleteUpload terminates a resumable upload, removing the chunks received
+func DeleteUpload(c *fiber.Ctx) error {
+	tusHeaders(c, false)
+	upload, f := getUpload(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	if err := utils.DeleteUpload(c.UserContext(), upload); err != nil {
+		return sendFailure(c, uploadFailure(err, api.CodeUploadDelete, "Failed to delete the upload"))
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,893 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"main/analytics"
+	"main/api"
+	"main/extract"
+	"main/logging"
+	"main/metrics"
+	"main/utils"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v4"
+)
+
+// sendError answers with the /api/v1 error envelope, logging err when the failure is internal
+func sendError(c *fiber.Ctx, status int, code, message string, err error) error {
+	ctx := c.UserContext()
+	log := logging.FromContext(ctx).WithField("code", code)
+	if status >= fiber.StatusInternalServerError {
+		log.WithError(err).Error(message)
+	} else {
+		log.Info(message)
+	}
+
+	return c.Status(status).JSON(api.ErrorEnvelope{Error: api.Error{
+		Code:      code,
+		Message:   message,
+		RequestID: logging.RequestID(ctx),
+	}})
+}
+
+func sendFailure(c *fiber.Ctx, f *failure) error {
+	return sendError(c, f.status, f.code, f.message, f.err)
+}
+
+// documentID parses the :id parameter shared by the document routes
+func documentID(c *fiber.Ctx) (int, *failure) {
+	id, err := c.ParamsInt("id", -1)
+	if err != nil || id < 1 {
+		return 0, badRequest(api.CodeInvalidID, "Id invalid or not provided")
+	}
+	withRecord(c, id)
+	return id, nil
+}
+
+// ErrorHandler answers errors returned by handlers, such as unmatched routes, with the error envelope
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+	}
+
+	code := api.CodeInternal
+	message := "Internal server error"
+	switch status {
+	case fiber.StatusNotFound:
+		code, message = api.CodeNotFound, "Route not found"
+	case fiber.StatusMethodNotAllowed:
+		code, message = api.CodeMethodNotAllowed, "Method not allowed"
+	case fiber.StatusRequestEntityTooLarge:
+		code, message = api.CodeUploadTooLarge, fmt.Sprintf("The request is larger than the %d bytes accepted", utils.GetUploadPolicy().MaxBodySize())
+	default:
+		if status < fiber.StatusInternalServerError {
+			code, message = api.CodeBadRequest, err.Error()
+		}
+	}
+
+	return sendError(c, status, code, message, err)
+}
+
+// Deprecated marks a legacy route and points clients to its /api/v1 successor
+func Deprecated(successor string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", "true")
+		c.Set(fiber.HeaderLink, "<"+successor+">; rel=\"successor-version\"")
+		return c.Next()
+	}
+}
+
+func ListDocuments(c *fiber.Ctx) error {
+	filter, f := recordFilter(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	records, next, err := utils.ListRecordsPage(c.UserContext(), filter)
+	if err != nil {
+		return sendFailure(c, pageFailure(err, api.CodeRecordList, "Failed to get the list of documents"))
+	}
+
+	return c.JSON(api.DocumentList{Documents: api.NewDocuments(records), NextCursor: next})
+}
+
+func CreateDocument(c *fiber.Ctx) error {
+	file, err := formFile(c)
+	if err != nil {
+		return sendError(c, fiber.StatusBadRequest, api.CodeUploadMissingFile, "The \"file\" form file is required", err)
+	}
+
+	record, f := ingestUpload(c, file)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	c.Location("/api/v1/documents/" + fmt.Sprint(record.ID))
+	return c.Status(fiber.StatusCreated).JSON(api.NewDocument(record))
+}
+
+// CreateDocuments ingests a batch of files sent as several parts, zip or tar archives among them,
+// and lists the outcome of every file. The files that fail do not stop the others unless
+// abort_on_error is set.
+func CreateDocuments(c *fiber.Ctx) error {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return sendError(c, fiber.StatusBadRequest, api.CodeUploadMissingFile, "The \"file\" form files are required", err)
+	}
+	parts := append(form.File["file"], form.File["pdf"]...)
+	if len(parts) == 0 {
+		return sendError(c, fiber.StatusBadRequest, api.CodeUploadMissingFile, "The \"file\" form files are required", nil)
+	}
+
+	files, f := saveBatch(c, parts)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	result := ingestBatch(c.UserContext(), files, c.QueryBool("abort_on_error"))
+
+	status := fiber.StatusCreated
+	if result.Created < len(result.Results) {
+		status = fiber.StatusMultiStatus
+	}
+	return c.Status(status).JSON(result)
+}
+
+func GetDocument(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	record, f := getRecord(c.UserContext(), id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	return c.JSON(api.NewDocument(record))
+}
+
+func ListDocumentPages(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	pages, err := utils.ListRecordPages(ctx, record)
+	if err != nil {
+		return sendError(c, fiber.StatusInternalServerError, api.CodePageList, "Failed to get the pages of the document", err)
+	}
+
+	result := api.PageList{DocumentID: record.ID, Pages: make([]api.Page, 0, len(pages))}
+	for _, page := range pages {
+		entry := api.Page{Page: page.Page, OCR: page.OCR, PageSize: api.PageSize{Width: page.Width, Height: page.Height}}
+		if page.OCR {
+			confidence := page.Confidence
+			entry.Confidence = &confidence
+		}
+		result.Pages = append(result.Pages, entry)
+	}
+
+	return c.JSON(result)
+}
+
+// GetDocumentStructure returns the sections, paragraphs and sentences of a document
+func GetDocumentStructure(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	sections, err := utils.GetStructure(ctx, record.ID)
+	if err != nil {
+		return sendError(c, fiber.StatusInternalServerError, api.CodeStructureFailed, "Failed to get the structure of the document", err)
+	}
+	if sections == nil {
+		return sendError(c, fiber.StatusNotFound, api.CodeStructureNotFound, "The layout of the document was not extracted", nil)
+	}
+
+	return c.JSON(api.Structure{DocumentID: record.ID, Sections: newSections(sections)})
+}
+
+func newSections(sections []*utils.Section) []api.Section {
+	result := make([]api.Section, 0, len(sections))
+	for _, section := range sections {
+		entry := api.Section{
+			Title:      section.Title,
+			Level:      section.Level,
+			Page:       section.Page,
+			Paragraphs: make([]api.Paragraph, 0, len(section.Paragraphs)),
+			Sections:   newSections(section.Sections),
+		}
+		for _, paragraph := range section.Paragraphs {
+			box := paragraph.Box
+			entry.Paragraphs = append(entry.Paragraphs, api.Paragraph{
+				ID:        paragraph.ID,
+				Page:      paragraph.Page,
+				Box:       api.Box{X0: box.X0, Y0: box.Y0, X1: box.X1, Y1: box.Y1},
+				Sentences: api.NewSentences(paragraph.Sentences),
+			})
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+func GetDocumentMetadata(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	if f := pdfOnly(record); f != nil {
+		return sendFailure(c, f)
+	}
+
+	metadata, err := utils.GetMetadata(ctx, record.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sendError(c, fiber.StatusNotFound, api.CodeMetadataNotFound, "The metadata of the document was not read", err)
+		}
+		return sendError(c, fiber.StatusInternalServerError, api.CodeMetadataFailed, "Failed to get the metadata of the document", err)
+	}
+
+	return c.JSON(api.NewMetadata(metadata))
+}
+
+// GetDocumentOutline returns the bookmarks of a document, each linked to the image of its page
+func GetDocumentOutline(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	if f := pdfOnly(record); f != nil {
+		return sendFailure(c, f)
+	}
+
+	items, err := utils.GetOutline(ctx, record.ID)
+	if err != nil {
+		return sendError(c, fiber.StatusInternalServerError, api.CodeMetadataFailed, "Failed to get the outline of the document", err)
+	}
+
+	return c.JSON(api.Outline{DocumentID: record.ID, Items: newOutlineItems(record.ID, items)})
+}
+
+func newOutlineItems(id int32, items []extract.OutlineItem) []api.OutlineItem {
+	result := make([]api.OutlineItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, api.OutlineItem{
+			Title:    item.Title,
+			Page:     item.Page,
+			Image:    fmt.Sprintf("/api/v1/documents/%d/pages/%d", id, item.Page),
+			Children: newOutlineItems(id, item.Children),
+		})
+	}
+	return result
+}
+
+func ListDocumentTables(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	tables, err := utils.ListRecordTables(ctx, record.ID)
+	if err != nil {
+		return sendError(c, fiber.StatusInternalServerError, api.CodeTableList, "Failed to get the tables of the document", err)
+	}
+
+	result := api.TableList{DocumentID: record.ID, Tables: make([]api.Table, 0, len(tables))}
+	for _, table := range tables {
+		result.Tables = append(result.Tables, api.NewTable(table))
+	}
+	return c.JSON(result)
+}
+
+// GetDocumentTable returns the cells of a table as JSON or CSV
+func GetDocumentTable(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	tableID, err := c.ParamsInt("table", -1)
+	if err != nil || tableID < 1 {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidID, "Table id invalid or not provided", err)
+	}
+
+	format := c.Query("format", "json")
+	if format != "json" && format != "csv" {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidInput, "format must be json or csv", nil)
+	}
+
+	ctx := c.UserContext()
+	if _, f := getRecord(ctx, id); f != nil {
+		return sendFailure(c, f)
+	}
+
+	table, rows, err := utils.GetTable(ctx, int32(id), int32(tableID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sendError(c, fiber.StatusNotFound, api.CodeTableNotFound, "Table not found in the document", err)
+		}
+		return sendError(c, fiber.StatusInternalServerError, api.CodeTableList, "Failed to get the table", err)
+	}
+
+	if format == "csv" {
+		var b bytes.Buffer
+		w := csv.NewWriter(&b)
+		if err := w.WriteAll(rows); err != nil {
+			return sendError(c, fiber.StatusInternalServerError, api.CodeInternal, "Failed to write the table", err)
+		}
+		c.Type("csv", "utf-8")
+		c.Attachment(fmt.Sprintf("document-%d-table-%d.csv", id, table.ID))
+		return c.Send(b.Bytes())
+	}
+
+	return c.JSON(api.TableData{Table: api.NewTable(table), Rows: rows})
+}
+
+func DownloadDocument(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	localFile, f := copyDocument(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	defer os.Remove(localFile)
+
+	if err := c.SendFile(localFile); err != nil {
+		return err
+	}
+	// SendFile sets the type from the extension of the name, the record knows the format
+	c.Set(fiber.HeaderContentType, record.Mimetype)
+	return nil
+}
+
+func DeleteDocument(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	if f := deleteDocument(c.UserContext(), id); f != nil {
+		return sendFailure(c, f)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func ListDocumentSentences(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	limit, f := queryLimit(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	if _, f := getRecord(ctx, id); f != nil {
+		return sendFailure(c, f)
+	}
+
+	sentences, next, err := utils.ListRecordSentencesPage(ctx, int32(id), c.Query("cursor"), limit)
+	if err != nil {
+		return sendFailure(c, pageFailure(err, api.CodeSentenceList, "Failed to get the sentences of the document"))
+	}
+
+	return c.JSON(api.SentenceList{Sentences: api.NewSentences(sentences), NextCursor: next})
+}
+
+func GetDocumentPage(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	page, err := c.ParamsInt("n", -1)
+	if err != nil || page < 1 {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidPage, "Page numbers start at 1", err)
+	}
+
+	ctx := c.UserContext()
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	if page > int(record.Numofpages) {
+		return sendError(c, fiber.StatusNotFound, api.CodePageNotFound, "The document has fewer pages", nil)
+	}
+
+	imageFile, f := renderPage(ctx, record, page)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	defer os.Remove(imageFile)
+
+	c.Type("jpg")
+	return c.SendFile(imageFile)
+}
+
+func GetDocumentOccurrences(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	term := strings.TrimSpace(c.Query("q"))
+	if term == "" {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidKeyword, "The q query parameter is required", nil)
+	}
+
+	ctx := c.UserContext()
+	if _, f := getRecord(ctx, id); f != nil {
+		return sendFailure(c, f)
+	}
+
+	defer metrics.ObserveSearch("occurrence")()
+
+	sentences, err := utils.ListRecordSentences(ctx, int32(id))
+	if err != nil {
+		return sendError(c, fiber.StatusInternalServerError, api.CodeSentenceList, "Failed to get the sentences of the document", err)
+	}
+
+	count, indexes := utils.FindOccurrences(sentences, term)
+	result := api.Occurrences{DocumentID: int32(id), Term: term, Count: count, Sentences: []api.Sentence{}}
+	for _, index := range indexes {
+		result.Sentences = append(result.Sentences, api.NewSentence(sentences[index]))
+	}
+
+	return c.JSON(result)
+}
+
+func GetDocumentConcordance(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidKeyword, "The q query parameter is required", nil)
+	}
+
+	window, f := queryBoundedInt(c, "window", 5, 0, 50)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	order := c.Query("sort", analytics.SortPosition)
+	if order != analytics.SortPosition && order != analytics.SortLeft && order != analytics.SortRight {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidInput, "sort must be position, left or right", nil)
+	}
+
+	format := c.Query("format", "json")
+	if format != "json" && format != "text" {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidInput, "format must be json or text", nil)
+	}
+
+	limit, f := queryLimit(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	if _, f := getRecord(ctx, id); f != nil {
+		return sendFailure(c, f)
+	}
+
+	defer metrics.ObserveSearch("concordance")()
+
+	lines, count, next, err := utils.Concordance(ctx, int32(id), query, window, order, c.Query("cursor"), limit)
+	if err != nil {
+		return sendFailure(c, pageFailure(err, api.CodeSentenceList, "Failed to get the sentences of the document"))
+	}
+
+	if format == "text" {
+		if next != "" {
+			c.Set(HeaderNextCursor, next)
+		}
+		c.Type("txt", "utf-8")
+		return c.SendString(utils.FormatConcordance(lines))
+	}
+
+	result := api.Concordance{DocumentID: int32(id), Query: query, Window: window, Sort: order, Count: count, Lines: []api.ConcordanceLine{}, NextCursor: next}
+	for _, line := range lines {
+		result.Lines = append(result.Lines, api.ConcordanceLine{
+			SentenceID: line.SentenceID,
+			Page:       line.Page,
+			Left:       line.Left,
+			Match:      line.Match,
+			Right:      line.Right,
+			Start:      line.Start,
+			End:        line.End,
+		})
+	}
+
+	return c.JSON(result)
+}
+
+func GetDocumentFrequencies(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	query, f := frequencyQuery(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	query.Language = documentLanguage(record, query.Language)
+
+	terms, err := utils.DocumentFrequencies(ctx, int32(id), query)
+	if err != nil {
+		return sendError(c, fiber.StatusInternalServerError, api.CodeSentenceList, "Failed to get the sentences of the document", err)
+	}
+
+	result := api.Frequencies{
+		DocumentID: int32(id),
+		NGram:      query.NGram,
+		Language:   query.Language,
+		PageFrom:   query.PageFrom,
+		PageTo:     query.PageTo,
+		Terms:      []api.TermFrequency{},
+	}
+	for _, term := range terms {
+		result.Terms = append(result.Terms, api.TermFrequency{Term: term.Term, Count: term.Count, Share: term.Share})
+	}
+
+	return c.JSON(result)
+}
+
+func GetDocumentKeywords(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	options, f := keywordOptions(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	options.Language = documentLanguage(record, options.Language)
+
+	keywords, corpus, err := utils.DocumentKeywords(ctx, int32(id), options)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotIndexed) {
+			return sendError(c, fiber.StatusConflict, api.CodeDocumentNotIndexed, "The terms of the document have not been indexed yet", nil)
+		}
+		return sendError(c, fiber.StatusInternalServerError, api.CodeCorpusStats, "Failed to get the corpus statistics", err)
+	}
+
+	result := api.Keywords{
+		DocumentID: int32(id),
+		Weighting:  string(options.Weighting),
+		Language:   options.Language,
+		Documents:  corpus.Documents,
+		Terms:      []api.Keyword{},
+	}
+	for _, keyword := range keywords {
+		term := keyword.Surface
+		if term == "" {
+			term = keyword.Term
+		}
+		result.Terms = append(result.Terms, api.Keyword{
+			Term:              term,
+			Stem:              keyword.Term,
+			Count:             keyword.Count,
+			DocumentFrequency: keyword.DocumentCount,
+			Score:             keyword.Score,
+		})
+	}
+
+	return c.JSON(result)
+}
+
+func GetDocumentEntities(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	limit, f := queryBoundedInt(c, "limit", utils.DefaultPageSize, 1, utils.MaxPageSize)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	record, f := getRecord(ctx, id)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	// an empty list would read as a document without entities
+	if language := utils.RecordLanguage(record); language != utils.EntityLanguage {
+		return sendError(c, fiber.StatusUnprocessableEntity, api.CodeEntityLanguage, fmt.Sprintf("The entities are only extracted from the documents in %s, the document is in %s", utils.EntityLanguage, language), nil)
+	}
+
+	entities, err := utils.DocumentEntities(ctx, int32(id), strings.ToUpper(c.Query("label")), limit)
+	if err != nil {
+		return sendError(c, fiber.StatusInternalServerError, api.CodeEntityList, "Failed to get the entities of the document", err)
+	}
+
+	return c.JSON(api.DocumentEntities{DocumentID: int32(id), Entities: newEntityCounts(entities)})
+}
+
+func ListEntityDocuments(c *fiber.Ctx) error {
+	query, f := entityQuery(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	documents, err := utils.EntityDocuments(c.UserContext(), query)
+	if err != nil {
+		return sendError(c, fiber.StatusInternalServerError, api.CodeEntityList, "Failed to get the documents mentioning the entity", err)
+	}
+
+	result := api.EntityDocuments{Text: query.Text, Label: query.Label, Documents: []api.EntityDocument{}}
+	for _, document := range documents {
+		result.Documents = append(result.Documents, api.EntityDocument{Document: api.NewDocument(document.Record), Mentions: document.Mentions})
+	}
+
+	return c.JSON(result)
+}
+
+func GetEntityCoOccurrences(c *fiber.Ctx) error {
+	query, f := entityQuery(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+	if query.DocumentID, f = queryInt32(c, "document_id"); f != nil {
+		return sendFailure(c, f)
+	}
+
+	entities, err := utils.CoOccurringEntities(c.UserContext(), query)
+	if err != nil {
+		return sendError(c, fiber.StatusInternalServerError, api.CodeEntityList, "Failed to get the co-occurring entities", err)
+	}
+
+	return c.JSON(api.CoOccurrences{
+		Text:       query.Text,
+		Label:      query.Label,
+		DocumentID: query.DocumentID,
+		Entities:   newEntityCounts(entities),
+	})
+}
+
+func newEntityCounts(entities []utils.EntityCount) []api.EntityCount {
+	result := make([]api.EntityCount, 0, len(entities))
+	for _, entity := range entities {
+		result = append(result, api.EntityCount{Text: entity.Text, Label: entity.Label, Mentions: entity.Mentions})
+	}
+	return result
+}
+
+func GetDocumentSummary(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	length, f := summaryLength(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	if _, f := getRecord(ctx, id); f != nil {
+		return sendFailure(c, f)
+	}
+
+	sentences, total, err := utils.Summary(ctx, int32(id), length)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sendError(c, fiber.StatusNotFound, api.CodeDocumentNotFound, "Document not found", err)
+		}
+		return sendError(c, fiber.StatusInternalServerError, api.CodeSummaryFailed, "Failed to summarize the document", err)
+	}
+
+	result := api.Summary{DocumentID: int32(id), Total: total, Sentences: []api.ScoredSentence{}}
+	for _, sentence := range sentences {
+		result.Sentences = append(result.Sentences, api.ScoredSentence{Sentence: api.NewSentence(sentence.Sentence), Score: sentence.Score})
+	}
+
+	return c.JSON(result)
+}
+
+func GetSimilarDocuments(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	k, f := queryBoundedInt(c, "k", 10, 1, 100)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	if _, f := getRecord(ctx, id); f != nil {
+		return sendFailure(c, f)
+	}
+
+	documents, err := utils.SimilarDocuments(ctx, int32(id), k)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sendError(c, fiber.StatusNotFound, api.CodeDocumentNotFound, "Document not found", err)
+		}
+		return sendError(c, fiber.StatusInternalServerError, api.CodeSimilarityFailed, "Failed to find the similar documents", err)
+	}
+
+	result := api.SimilarDocuments{DocumentID: int32(id), Documents: []api.SimilarDocument{}}
+	for _, document := range documents {
+		result.Documents = append(result.Documents, api.SimilarDocument{Document: api.NewDocument(document.Record), Score: document.Score})
+	}
+
+	return c.JSON(result)
+}
+
+func GetDocumentDuplicates(c *fiber.Ctx) error {
+	id, f := documentID(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	threshold, f := queryFloat(c, "threshold", 0.5, 0, 1)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	ctx := c.UserContext()
+	if _, f := getRecord(ctx, id); f != nil {
+		return sendFailure(c, f)
+	}
+
+	documents, err := utils.Duplicates(ctx, int32(id), threshold)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sendError(c, fiber.StatusNotFound, api.CodeDocumentNotFound, "Document not found", err)
+		}
+		return sendError(c, fiber.StatusInternalServerError, api.CodeDuplicatesFailed, "Failed to find the duplicated passages", err)
+	}
+
+	result := api.Duplicates{DocumentID: int32(id), Threshold: threshold, Documents: []api.DuplicateDocument{}}
+	for _, document := range documents {
+		pairs := make([]api.DuplicatePair, 0, len(document.Pairs))
+		for _, pair := range document.Pairs {
+			pairs = append(pairs, api.DuplicatePair{
+				Sentence:   api.NewSentence(pair.Sentence),
+				Other:      api.NewSentence(pair.Other),
+				Similarity: pair.Similarity,
+			})
+		}
+		result.Documents = append(result.Documents, api.DuplicateDocument{
+			Document: api.NewDocument(document.Record),
+			Overlap:  document.Overlap,
+			Pairs:    pairs,
+		})
+	}
+
+	return c.JSON(result)
+}
+
+func GetSimilarSentences(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id", -1)
+	if err != nil || id < 1 {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidID, "Id invalid or not provided", err)
+	}
+
+	k, f := queryBoundedInt(c, "k", 10, 1, 100)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	sentences, err := utils.SimilarSentences(c.UserContext(), int32(id), k, c.QueryBool("other_documents"))
+	if err != nil {
+		if errors.Is(err, utils.ErrSentenceNotFound) {
+			return sendError(c, fiber.StatusNotFound, api.CodeSentenceNotFound, "Sentence not found", err)
+		}
+		return sendError(c, fiber.StatusInternalServerError, api.CodeSimilarityFailed, "Failed to find the similar sentences", err)
+	}
+
+	result := api.SimilarSentences{SentenceID: int32(id), Sentences: []api.ScoredSentence{}}
+	for _, sentence := range sentences {
+		result.Sentences = append(result.Sentences, api.ScoredSentence{Sentence: api.NewSentence(sentence.Sentence), Score: sentence.Score})
+	}
+
+	return c.JSON(result)
+}
+
+func Search(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return sendError(c, fiber.StatusBadRequest, api.CodeInvalidKeyword, "The q query parameter is required", nil)
+	}
+
+	options, f := searchOptions(c)
+	if f != nil {
+		return sendFailure(c, f)
+	}
+
+	defer metrics.ObserveSearch("keyword")()
+
+	hits, total, err := utils.Search(c.UserContext(), query, options)
+	if err != nil {
+		return sendError(c, fiber.StatusInternalServerError, api.CodeSentenceList, "Failed to search the documents", err)
+	}
+
+	result := api.SearchResults{Query: query, Group: searchGroupSentence, Total: total, Results: []api.SearchHit{}}
+	if options.GroupByDocument {
+		result.Group = searchGroupDocument
+	}
+	for _, hit := range hits {
+		sentences := make([]api.ScoredSentence, 0, len(hit.Sentences))
+		for _, sentence := range hit.Sentences {
+			sentences = append(sentences, api.ScoredSentence{Sentence: api.NewSentence(sentence.Sentence), Score: sentence.Score})
+		}
+		result.Results = append(result.Results, api.SearchHit{
+			Document:  api.NewDocument(hit.Record),
+			Score:     hit.Score,
+			Sentences: sentences,
+		})
+	}
+
+	return c.JSON(result)
+}
@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderRequestID is the header used to accept and return the request id
+const HeaderRequestID = "X-Request-ID"
+
+// Base is the logger every request and background job derives from
+var Base = logrus.New()
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type entryKey struct{}
+
+type requestIDKey struct{}
+
+// Configure sets the level ("debug", "info", ...) and format ("json" or "text") of the base logger
+func Configure(level, format string) error {
+	if level != "" {
+		lvl, err := logrus.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		Base.SetLevel(lvl)
+	}
+
+	switch strings.ToLower(format) {
+	case "", "json":
+		Base.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	case "text":
+		Base.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		return errors.New("unknown log format: " + format)
+	}
+
+	return nil
+}
+
+// FromContext returns the logger carried by ctx, or the base logger
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(entryKey{}).(*logrus.Entry); ok {
+			return entry
+		}
+	}
+	return logrus.NewEntry(Base)
+}
+
+// WithFields returns a copy of ctx whose logger carries the given fields
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return context.WithValue(ctx, entryKey{}, FromContext(ctx).WithFields(fields))
+}
+
+// WithField returns a copy of ctx whose logger carries the given field
+func WithField(ctx context.Context, key string, value interface{}) context.Context {
+	return WithFields(ctx, logrus.Fields{key: value})
+}
+
+// Detach returns a context carrying the logger and request id of ctx but none of its deadline,
+// cancellation and spans, for the work that outlives a request
+func Detach(ctx context.Context) context.Context {
+	detached := context.WithValue(context.Background(), requestIDKey{}, RequestID(ctx))
+	return context.WithValue(detached, entryKey{}, FromContext(ctx))
+}
+
+// RequestID returns the id of the request ctx belongs to
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Middleware accepts or generates the X-Request-ID of every request,
+// stores a logger carrying it in the user context and logs the outcome of the request.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		id := c.Get(HeaderRequestID)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Set(HeaderRequestID, id)
+
+		ctx := context.WithValue(c.UserContext(), requestIDKey{}, id)
+		fields := logrus.Fields{"request_id": id}
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+			fields["trace_id"] = spanCtx.TraceID().String()
+		}
+		c.SetUserContext(WithFields(ctx, fields))
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		entry := FromContext(c.UserContext()).WithFields(logrus.Fields{
+			"method":      c.Method(),
+			"path":        c.Path(),
+			"route":       c.Route().Path,
+			"status":      status,
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+		if err != nil {
+			entry = entry.WithError(err)
+		}
+
+		if status >= fiber.StatusInternalServerError {
+			entry.Error("request failed")
+		} else {
+			entry.Info("request completed")
+		}
+
+		return err
+	}
+}
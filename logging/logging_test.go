@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestMiddlewareRequestID(t *testing.T) {
+	Base.SetOutput(io.Discard)
+	hook := test.NewLocal(Base)
+	defer hook.Reset()
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		// the handlers log with the id of their request
+		FromContext(c.UserContext()).Info("handled")
+		return c.SendString(RequestID(c.UserContext()))
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusInternalServerError) })
+
+	tests := []struct {
+		path    string
+		header  string
+		keep    bool
+		level   logrus.Level
+		entries int
+	}{
+		{"/ok", "client-id.1", true, logrus.InfoLevel, 2},
+		{"/ok", "", false, logrus.InfoLevel, 2},
+		{"/ok", "not a valid id", false, logrus.InfoLevel, 2},
+		{"/fail", "failing", true, logrus.ErrorLevel, 1},
+	}
+	for _, tt := range tests {
+		hook.Reset()
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set(HeaderRequestID, tt.header)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id := resp.Header.Get(HeaderRequestID)
+		if tt.keep && id != tt.header {
+			t.Errorf("%s %q: got request id %q, want it kept", tt.path, tt.header, id)
+		}
+		if !tt.keep && (id == "" || id == tt.header) {
+			t.Errorf("%s %q: got request id %q, want a generated one", tt.path, tt.header, id)
+		}
+
+		entries := hook.AllEntries()
+		if len(entries) != tt.entries {
+			t.Fatalf("%s %q: got %d log entries, want %d", tt.path, tt.header, len(entries), tt.entries)
+		}
+		for _, entry := range entries {
+			if entry.Data["request_id"] != id {
+				t.Errorf("%s %q: entry %q has request id %v, want %q", tt.path, tt.header, entry.Message, entry.Data["request_id"], id)
+			}
+		}
+		last := hook.LastEntry()
+		if last.Level != tt.level || last.Data["route"] != tt.path {
+			t.Errorf("%s %q: got outcome %s on route %v, want %s on %s", tt.path, tt.header, last.Level, last.Data["route"], tt.level, tt.path)
+		}
+	}
+}
+
+func TestDetach(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "request")
+	ctx = WithField(ctx, "record_id", 7)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	cancel()
+
+	detached := Detach(ctx)
+	if detached.Err() != nil {
+		t.Errorf("got error %v on the detached context, want none", detached.Err())
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Error("got a deadline on the detached context, want none")
+	}
+	if id := RequestID(detached); id != "request" {
+		t.Errorf("got request id %q, want %q", id, "request")
+	}
+	if value := FromContext(detached).Data["record_id"]; value != 7 {
+		t.Errorf("got field record_id %v, want 7", value)
+	}
+}
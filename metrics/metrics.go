@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "codestacker"
+
+// Ingestion stages observed by IngestStageDuration
+const (
+	StageUpload   = "upload"
+	StageSave     = "save"
+	StageExtract  = "extract"
+	StageSplit    = "split"
+	StageStore    = "store"
+	StageRender   = "render"
+	StageEntities = "entities"
+	StageSummary  = "summary"
+	StageOCR      = "ocr"
+	StageMetadata = "metadata"
+	StageValidate = "validate"
+)
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	IngestStageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ingest_stage_duration_seconds",
+		Help:      "Time spent in each stage of the ingestion pipeline.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"stage"})
+
+	PagesProcessed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ingest_pages_total",
+		Help:      "Number of PDF pages ingested.",
+	})
+
+	PagesRecognized = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ingest_ocr_pages_total",
+		Help:      "Number of scanned PDF pages run through OCR.",
+	})
+
+	SentencesProcessed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ingest_sentences_total",
+		Help:      "Number of sentences stored by the ingestion pipeline.",
+	})
+
+	SearchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "search_duration_seconds",
+		Help:      "Time spent searching the stored sentences.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"kind"})
+
+	StorageBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_bytes_total",
+		Help:      "Bytes transferred to and from the object storage.",
+	}, []string{"direction"})
+)
+
+// ObserveStage starts timing an ingestion stage, the returned function stops it
+func ObserveStage(stage string) func() {
+	start := time.Now()
+	return func() {
+		IngestStageDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveSearch starts timing a search, the returned function stops it
+func ObserveSearch(kind string) func() {
+	start := time.Now()
+	return func() {
+		SearchDuration.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Middleware records the count and latency of every request.
+// The route label is the matched route template, never the raw path.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+			// the router fails the paths no route handles, the route reached last is then that of
+			// a middleware in front of them, such as the authentication
+			if status == fiber.StatusNotFound || status == fiber.StatusMethodNotAllowed {
+				route = "unmatched"
+			}
+		}
+
+		labels := []string{c.Method(), route, strconv.Itoa(status)}
+		HTTPRequests.WithLabelValues(labels...).Inc()
+		HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// Handler exposes the default registry, including the Go runtime metrics
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.Handler())
+}
+
+// RegisterPool exposes the connection pool statistics
+func RegisterPool(pool *pgxpool.Pool) {
+	gauge := func(name, help string, value func(*pgxpool.Stat) float64) {
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "db_pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(pool.Stat()) })
+	}
+	counter := func(name, help string, value func(*pgxpool.Stat) float64) {
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db_pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(pool.Stat()) })
+	}
+
+	gauge("acquired_conns", "Connections currently in use.", func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) })
+	gauge("idle_conns", "Idle connections in the pool.", func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) })
+	gauge("total_conns", "Total connections in the pool.", func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) })
+	gauge("max_conns", "Maximum size of the pool.", func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) })
+	counter("acquire_total", "Successful connection acquisitions.", func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) })
+	counter("acquire_duration_seconds_total", "Total time spent acquiring connections.", func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() })
+	counter("empty_acquire_total", "Acquisitions that had to wait for a connection.", func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) })
+	counter("canceled_acquire_total", "Acquisitions canceled by their context.", func(s *pgxpool.Stat) float64 { return float64(s.CanceledAcquireCount()) })
+}
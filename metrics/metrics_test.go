@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddlewareRoute(t *testing.T) {
+	app := fiber.New()
+	app.Use(Middleware())
+	// a middleware in front of every path, as the authentication is
+	app.Use(func(c *fiber.Ctx) error { return c.Next() })
+	app.Get("/documents/:id", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	tests := []struct {
+		path   string
+		route  string
+		status string
+	}{
+		{"/documents/1", "/documents/:id", "200"},
+		{"/missing", "unmatched", "404"},
+	}
+	for _, tt := range tests {
+		before := testutil.ToFloat64(HTTPRequests.WithLabelValues("GET", tt.route, tt.status))
+		if _, err := app.Test(httptest.NewRequest("GET", tt.path, nil)); err != nil {
+			t.Fatal(err)
+		}
+		after := testutil.ToFloat64(HTTPRequests.WithLabelValues("GET", tt.route, tt.status))
+		if after != before+1 {
+			t.Errorf("GET %s: count of route %q status %s went from %v to %v", tt.path, tt.route, tt.status, before, after)
+		}
+	}
+}
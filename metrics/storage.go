@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"main/storage"
+)
+
+type countingReader struct {
+	io.Reader
+	direction string
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	StorageBytes.WithLabelValues(r.direction).Add(float64(n))
+	return n, err
+}
+
+type countingReadCloser struct {
+	countingReader
+	closer io.Closer
+}
+
+func (r *countingReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+type meteredStore struct {
+	next storage.BlobStore
+}
+
+// InstrumentStore counts the bytes sent to and read from a BlobStore
+func InstrumentStore(next storage.BlobStore) storage.BlobStore {
+	return &meteredStore{next: next}
+}
+
+func (m *meteredStore) Put(ctx context.Context, bucket, key string, r io.Reader, size int64, contentType string) error {
+	return m.next.Put(ctx, bucket, key, &countingReader{Reader: r, direction: "upload"}, size, contentType)
+}
+
+func (m *meteredStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	obj, err := m.next.Get(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return &countingReadCloser{countingReader: countingReader{Reader: obj, direction: "download"}, closer: obj}, nil
+}
+
+func (m *meteredStore) Remove(ctx context.Context, bucket, key string) error {
+	return m.next.Remove(ctx, bucket, key)
+}
+
+func (m *meteredStore) NewMultipart(ctx context.Context, bucket, key, contentType string) (string, error) {
+	return m.next.NewMultipart(ctx, bucket, key, contentType)
+}
+
+func (m *meteredStore) PutPart(ctx context.Context, bucket, key, uploadID string, number int, r io.Reader, size int64) (string, error) {
+	return m.next.PutPart(ctx, bucket, key, uploadID, number, &countingReader{Reader: r, direction: "upload"}, size)
+}
+
+func (m *meteredStore) CompleteMultipart(ctx context.Context, bucket, key, uploadID string, parts []storage.Part) error {
+	return m.next.CompleteMultipart(ctx, bucket, key, uploadID, parts)
+}
+
+func (m *meteredStore) AbortMultipart(ctx context.Context, bucket, key, uploadID string) error {
+	return m.next.AbortMultipart(ctx, bucket, key, uploadID)
+}
@@ -32,8 +32,7 @@ func (m *migrateLogger) Verbose() bool {
 }
 
 // Migrate creates a db connection then migrate
-func Migrate(connStr string, logMode LogMode) error {
-	logger := logrus.New()
+func Migrate(connStr string, logMode LogMode, logger *logrus.Logger) error {
 
 	sourceDriver, err := iofs.New(schema, "schema")
 	if err != nil {
@@ -0,0 +1,12 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS pages (
+  pdfId INT NOT NULL,
+  page INT NOT NULL,
+  ocr BOOLEAN NOT NULL DEFAULT FALSE,
+  confidence FLOAT8 NOT NULL DEFAULT 0,
+  PRIMARY KEY (pdfId, page),
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE
+);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE sentences DROP COLUMN IF EXISTS paragraphId;
+DROP TABLE IF EXISTS paragraphs;
+DROP TABLE IF EXISTS sections;
+
+COMMIT;
@@ -0,0 +1,33 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS sections (
+  id SERIAL PRIMARY KEY,
+  pdfId INT NOT NULL,
+  parentId INT,
+  level INT NOT NULL,
+  title TEXT NOT NULL,
+  page INT NOT NULL,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE,
+  FOREIGN KEY (parentId) REFERENCES sections(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS sections_pdfid_id_idx ON sections (pdfId, id);
+
+CREATE TABLE IF NOT EXISTS paragraphs (
+  id SERIAL PRIMARY KEY,
+  pdfId INT NOT NULL,
+  sectionId INT NOT NULL,
+  page INT NOT NULL,
+  x0 FLOAT8 NOT NULL,
+  y0 FLOAT8 NOT NULL,
+  x1 FLOAT8 NOT NULL,
+  y1 FLOAT8 NOT NULL,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE,
+  FOREIGN KEY (sectionId) REFERENCES sections(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS paragraphs_pdfid_id_idx ON paragraphs (pdfId, id);
+
+ALTER TABLE sentences ADD COLUMN IF NOT EXISTS paragraphId INT REFERENCES paragraphs(id) ON DELETE SET NULL;
+
+COMMIT;
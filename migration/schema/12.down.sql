@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS table_cells;
+DROP TABLE IF EXISTS document_tables;
+
+COMMIT;
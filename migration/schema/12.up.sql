@@ -0,0 +1,27 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS document_tables (
+  id SERIAL PRIMARY KEY,
+  pdfId INT NOT NULL,
+  page INT NOT NULL,
+  x0 FLOAT8 NOT NULL,
+  y0 FLOAT8 NOT NULL,
+  x1 FLOAT8 NOT NULL,
+  y1 FLOAT8 NOT NULL,
+  numOfRows INT NOT NULL,
+  numOfColumns INT NOT NULL,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS document_tables_pdfid_id_idx ON document_tables (pdfId, id);
+
+CREATE TABLE IF NOT EXISTS table_cells (
+  tableId INT NOT NULL,
+  rowIndex INT NOT NULL,
+  columnIndex INT NOT NULL,
+  text TEXT NOT NULL,
+  PRIMARY KEY (tableId, rowIndex, columnIndex),
+  FOREIGN KEY (tableId) REFERENCES document_tables(id) ON DELETE CASCADE
+);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS outline_items;
+ALTER TABLE pages DROP COLUMN IF EXISTS height;
+ALTER TABLE pages DROP COLUMN IF EXISTS width;
+DROP TABLE IF EXISTS document_metadata;
+
+COMMIT;
@@ -0,0 +1,33 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS document_metadata (
+  pdfId INT PRIMARY KEY,
+  title TEXT NOT NULL DEFAULT '',
+  author TEXT NOT NULL DEFAULT '',
+  subject TEXT NOT NULL DEFAULT '',
+  keywords TEXT NOT NULL DEFAULT '',
+  creator TEXT NOT NULL DEFAULT '',
+  producer TEXT NOT NULL DEFAULT '',
+  created TIMESTAMP,
+  modified TIMESTAMP,
+  pdfVersion VARCHAR(8) NOT NULL DEFAULT '',
+  encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE
+);
+
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS width FLOAT8 NOT NULL DEFAULT 0;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS height FLOAT8 NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS outline_items (
+  id SERIAL PRIMARY KEY,
+  pdfId INT NOT NULL,
+  parentId INT,
+  title TEXT NOT NULL,
+  page INT NOT NULL,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE,
+  FOREIGN KEY (parentId) REFERENCES outline_items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS outline_items_pdfid_id_idx ON outline_items (pdfId, id);
+
+COMMIT;
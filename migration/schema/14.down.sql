@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE records DROP COLUMN IF EXISTS mimeType;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- the records uploaded before the other formats were accepted are all pdfs
+ALTER TABLE records ADD COLUMN IF NOT EXISTS mimeType VARCHAR(128) NOT NULL DEFAULT 'application/pdf';
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS records_objectkey_idx;
+ALTER TABLE records DROP COLUMN IF EXISTS objectKey;
+
+ALTER TABLE records ALTER COLUMN size TYPE INT;
+
+COMMIT;
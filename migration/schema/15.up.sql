@@ -0,0 +1,14 @@
+BEGIN;
+
+-- sizes of 2 GiB and more overflowed INT
+ALTER TABLE records ALTER COLUMN size TYPE BIGINT;
+
+-- the files are stored under a generated key, those uploaded before keep the object of their name
+-- which the records of the same name share
+ALTER TABLE records ADD COLUMN IF NOT EXISTS objectKey VARCHAR(255);
+UPDATE records SET objectKey = name WHERE objectKey IS NULL;
+ALTER TABLE records ALTER COLUMN objectKey SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS records_objectkey_idx ON records (objectKey);
+
+COMMIT;
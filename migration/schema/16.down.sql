@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS upload_parts;
+DROP TABLE IF EXISTS uploads;
+
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS uploads (
+  id VARCHAR(36) PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  contentType VARCHAR(255) NOT NULL DEFAULT '',
+  length BIGINT NOT NULL,
+  received BIGINT NOT NULL DEFAULT 0,
+  parts INT NOT NULL DEFAULT 0,
+  multipartId TEXT NOT NULL,
+  checksum VARCHAR(255) NOT NULL DEFAULT '',
+  status VARCHAR(16) NOT NULL DEFAULT 'receiving',
+  pdfId INT,
+  errorCode VARCHAR(64) NOT NULL DEFAULT '',
+  errorMessage TEXT NOT NULL DEFAULT '',
+  createdAt TIMESTAMP NOT NULL DEFAULT NOW(),
+  expiresAt TIMESTAMP NOT NULL,
+  -- the hash of the chunks received so far, when the file has a checksum
+  hashState BYTEA,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS uploads_expiresat_idx ON uploads (expiresAt);
+
+CREATE TABLE IF NOT EXISTS upload_parts (
+  uploadId VARCHAR(36) NOT NULL,
+  number INT NOT NULL,
+  etag VARCHAR(255) NOT NULL,
+  size BIGINT NOT NULL,
+  PRIMARY KEY (uploadId, number),
+  FOREIGN KEY (uploadId) REFERENCES uploads(id) ON DELETE CASCADE
+);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS records_extractorversion_idx;
+ALTER TABLE records DROP COLUMN IF EXISTS extractorVersion;
+ALTER TABLE records DROP COLUMN IF EXISTS extractor;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- the records ingested before the version was recorded are extracted again as outdated
+ALTER TABLE records ADD COLUMN IF NOT EXISTS extractor VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE records ADD COLUMN IF NOT EXISTS extractorVersion INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS records_extractorversion_idx ON records (extractorVersion);
+
+COMMIT;
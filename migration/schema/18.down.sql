@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS records_numofpages_id_idx;
+DROP INDEX IF EXISTS records_size_id_idx;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- every sort of the listing walks the index of its key, ties broken by id
+CREATE INDEX IF NOT EXISTS records_size_id_idx ON records (size, id);
+CREATE INDEX IF NOT EXISTS records_numofpages_id_idx ON records (numOfPages, id);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS records_upload_time_id_idx;
+DROP INDEX IF EXISTS records_name_id_idx;
+DROP INDEX IF EXISTS sentences_pdfid_id_idx;
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS records (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    upload_time TIMESTAMP DEFAULT NOW() NOT NULL,
+    numOfPages INT NOT NULL,
+    size INT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sentences (
+  id SERIAL PRIMARY KEY,
+  sentence TEXT NOT NULL,
+  pdfId INT NOT NULL,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS sentences_pdfid_id_idx ON sentences (pdfId, id);
+CREATE INDEX IF NOT EXISTS records_name_id_idx ON records (name, id);
+CREATE INDEX IF NOT EXISTS records_upload_time_id_idx ON records (upload_time, id);
+
+COMMIT;
@@ -0,0 +1 @@
+ALTER TABLE sentences DROP COLUMN IF EXISTS page;
@@ -0,0 +1 @@
+ALTER TABLE sentences ADD COLUMN IF NOT EXISTS page INT NOT NULL DEFAULT 0;
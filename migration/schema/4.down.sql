@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS document_terms;
+DROP TABLE IF EXISTS document_stats;
+DROP TABLE IF EXISTS term_stats;
+DROP FUNCTION IF EXISTS count_document_terms();
+
+COMMIT;
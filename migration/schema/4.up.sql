@@ -0,0 +1,51 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS document_stats (
+  pdfId INT PRIMARY KEY,
+  term_count INT NOT NULL,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS document_terms (
+  pdfId INT NOT NULL,
+  term TEXT NOT NULL,
+  count INT NOT NULL,
+  PRIMARY KEY (pdfId, term),
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS term_stats (
+  term TEXT PRIMARY KEY,
+  document_count INT NOT NULL
+);
+
+-- term_stats follows every insert and delete of document_terms, including the
+-- cascaded deletes of records, so the corpus frequencies never drift.
+CREATE OR REPLACE FUNCTION count_document_terms() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO term_stats (term, document_count)
+    SELECT term, count(*) FROM new_terms GROUP BY term
+    ON CONFLICT (term) DO UPDATE SET document_count = term_stats.document_count + EXCLUDED.document_count;
+  ELSE
+    UPDATE term_stats SET document_count = term_stats.document_count - removed.n
+    FROM (SELECT term, count(*) AS n FROM old_terms GROUP BY term) AS removed
+    WHERE term_stats.term = removed.term;
+    DELETE FROM term_stats
+    WHERE document_count <= 0 AND term IN (SELECT term FROM old_terms);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS document_terms_inserted ON document_terms;
+CREATE TRIGGER document_terms_inserted AFTER INSERT ON document_terms
+REFERENCING NEW TABLE AS new_terms
+FOR EACH STATEMENT EXECUTE FUNCTION count_document_terms();
+
+DROP TRIGGER IF EXISTS document_terms_deleted ON document_terms;
+CREATE TRIGGER document_terms_deleted AFTER DELETE ON document_terms
+REFERENCING OLD TABLE AS old_terms
+FOR EACH STATEMENT EXECUTE FUNCTION count_document_terms();
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS sentences_sentence_trgm_idx;
+DROP EXTENSION IF EXISTS pg_trgm;
+DROP INDEX IF EXISTS document_terms_term_idx;
+
+COMMIT;
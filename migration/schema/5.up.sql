@@ -0,0 +1,10 @@
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS document_terms_term_idx ON document_terms (term);
+
+-- the searched words are matched by regular expressions, the trigrams of the sentences narrow
+-- the rows they are tried on
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS sentences_sentence_trgm_idx ON sentences USING GIN (sentence gin_trgm_ops);
+
+COMMIT;
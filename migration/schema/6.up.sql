@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS entities (
+  id SERIAL PRIMARY KEY,
+  sentenceId INT NOT NULL,
+  pdfId INT NOT NULL,
+  text TEXT NOT NULL,
+  label VARCHAR(32) NOT NULL,
+  FOREIGN KEY (sentenceId) REFERENCES sentences(id) ON DELETE CASCADE,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS entities_pdfid_idx ON entities (pdfId);
+CREATE INDEX IF NOT EXISTS entities_sentenceid_idx ON entities (sentenceId);
+CREATE INDEX IF NOT EXISTS entities_text_idx ON entities (lower(text));
+
+COMMIT;
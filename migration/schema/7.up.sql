@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS summary_scores (
+  sentenceId INT PRIMARY KEY,
+  pdfId INT NOT NULL,
+  score FLOAT8 NOT NULL,
+  FOREIGN KEY (sentenceId) REFERENCES sentences(id) ON DELETE CASCADE,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS summary_scores_pdfid_score_idx ON summary_scores (pdfId, score DESC, sentenceId);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS sentence_bands;
+DROP TABLE IF EXISTS duplicate_index;
+
+COMMIT;
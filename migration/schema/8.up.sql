@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS duplicate_index (
+  pdfId INT PRIMARY KEY,
+  indexed_at TIMESTAMP DEFAULT NOW() NOT NULL,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS sentence_bands (
+  band INT NOT NULL,
+  bucket BIGINT NOT NULL,
+  sentenceId INT NOT NULL,
+  pdfId INT NOT NULL,
+  PRIMARY KEY (band, bucket, sentenceId),
+  FOREIGN KEY (sentenceId) REFERENCES sentences(id) ON DELETE CASCADE,
+  FOREIGN KEY (pdfId) REFERENCES records(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS sentence_bands_pdfid_idx ON sentence_bands (pdfId);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DELETE FROM document_terms;
+DELETE FROM document_stats;
+DELETE FROM sentence_bands;
+DELETE FROM duplicate_index;
+
+ALTER TABLE document_terms DROP COLUMN IF EXISTS surface;
+ALTER TABLE records DROP COLUMN IF EXISTS language;
+
+COMMIT;
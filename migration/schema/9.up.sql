@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE records ADD COLUMN IF NOT EXISTS language VARCHAR(8) NOT NULL DEFAULT '';
+ALTER TABLE document_terms ADD COLUMN IF NOT EXISTS surface TEXT NOT NULL DEFAULT '';
+
+-- The terms and shingles were built by the ASCII tokenizer. They are dropped so that
+-- the startup indexing and the duplicate indexer rebuild them, stemmed per language.
+DELETE FROM document_terms;
+DELETE FROM document_stats;
+DELETE FROM sentence_bands;
+DELETE FROM duplicate_index;
+
+COMMIT;
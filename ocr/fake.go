@@ -0,0 +1,30 @@
+package ocr
+
+import (
+	"context"
+	"image"
+	"sync"
+)
+
+// Fake returns the same result for every image and counts its calls, for tests
+type Fake struct {
+	Result Result
+	Err    error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *Fake) Recognize(ctx context.Context, img image.Image) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return f.Result, f.Err
+}
+
+// Calls is the number of images recognized so far
+func (f *Fake) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
@@ -0,0 +1,21 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"image"
+)
+
+// ErrUnavailable is returned when no OCR engine is configured
+var ErrUnavailable = errors.New("ocr engine unavailable")
+
+// Result is the text recognized on an image and the mean confidence of its words, from 0 to 1
+type Result struct {
+	Text       string
+	Confidence float64
+}
+
+// Engine recognizes the text of a rendered page
+type Engine interface {
+	Recognize(ctx context.Context, img image.Image) (Result, error)
+}
@@ -0,0 +1,102 @@
+package ocr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// tsvColumns is the number of columns of the tsv output of tesseract, the text being the last one
+const tsvColumns = 12
+
+// Tesseract runs the tesseract command line on every image
+type Tesseract struct {
+	// Binary is the path of the tesseract executable
+	Binary string
+	// Languages are the trained models to use, joined with + as in eng+ara
+	Languages string
+}
+
+// NewTesseract looks up the tesseract executable on the PATH
+func NewTesseract(languages string) (*Tesseract, error) {
+	binary, err := exec.LookPath("tesseract")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	if languages == "" {
+		languages = "eng"
+	}
+	return &Tesseract{Binary: binary, Languages: languages}, nil
+}
+
+// Recognize pipes the image to tesseract as a png and reads back its words with their confidence
+func (t *Tesseract) Recognize(ctx context.Context, img image.Image) (Result, error) {
+	var in bytes.Buffer
+	if err := png.Encode(&in, img); err != nil {
+		return Result{}, err
+	}
+
+	cmd := exec.CommandContext(ctx, t.Binary, "stdin", "stdout", "-l", t.Languages, "tsv")
+	var out, stderr bytes.Buffer
+	cmd.Stdin = &in
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return Result{}, fmt.Errorf("tesseract: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseTSV(&out)
+}
+
+// parseTSV joins the words of the tsv output, one line of text per recognized line, and averages
+// the confidence of the words. Rows without a word have a confidence of -1.
+func parseTSV(out *bytes.Buffer) (Result, error) {
+	var text strings.Builder
+	lastLine := ""
+	total, words := 0.0, 0
+
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for first := true; scanner.Scan(); first = false {
+		if first {
+			continue // header
+		}
+		fields := strings.SplitN(scanner.Text(), "\t", tsvColumns)
+		if len(fields) < tsvColumns {
+			continue
+		}
+		confidence, err := strconv.ParseFloat(fields[10], 64)
+		word := strings.TrimSpace(fields[11])
+		if err != nil || confidence < 0 || word == "" {
+			continue
+		}
+
+		// page, block, paragraph and line numbers
+		line := strings.Join(fields[1:5], ".")
+		if text.Len() > 0 {
+			if line != lastLine {
+				text.WriteString("\n")
+			} else {
+				text.WriteString(" ")
+			}
+		}
+		lastLine = line
+		text.WriteString(word)
+		total += confidence
+		words++
+	}
+	if err := scanner.Err(); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Text: text.String()}
+	if words > 0 {
+		result.Confidence = total / float64(words) / 100
+	}
+	return result, nil
+}
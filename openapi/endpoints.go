@@ -0,0 +1,168 @@
+package openapi
+
+import (
+	"main/api"
+	"main/database"
+)
+
+var (
+	queryTerm = Parameter{Name: "q", In: "query", Required: true, Description: "Word to look for", Schema: &Schema{Type: "string"}}
+
+	pageParams = []Parameter{
+		{Name: "limit", In: "query", Description: "Page size, 50 by default and at most 500", Schema: &Schema{Type: "integer"}},
+		{Name: "cursor", In: "query", Description: "Cursor of the next page returned by the previous one", Schema: &Schema{Type: "string"}},
+	}
+
+	// the legacy routes list everything unless a page is asked for
+	legacyPageParams = []Parameter{
+		{Name: "limit", In: "query", Description: "Page size, at most 500, the whole list is returned without limit and cursor", Schema: &Schema{Type: "integer"}},
+		pageParams[1],
+	}
+
+	frequencyParams = []Parameter{
+		{Name: "n", In: "query", Description: "Number of terms, 10 by default and at most 100", Schema: &Schema{Type: "integer"}},
+		{Name: "ngram", In: "query", Description: "Words per term, 1 to 3", Schema: &Schema{Type: "integer"}},
+		{Name: "lang", In: "query", Description: "ISO 639-1 code of the stopword list, the detected language of the document by default", Schema: &Schema{Type: "string"}},
+		{Name: "min_length", In: "query", Description: "Minimum letters per word, 2 by default", Schema: &Schema{Type: "integer"}},
+		{Name: "page_from", In: "query", Schema: &Schema{Type: "integer"}},
+		{Name: "page_to", In: "query", Schema: &Schema{Type: "integer"}},
+	}
+
+	keywordParams = []Parameter{
+		{Name: "n", In: "query", Description: "Number of terms, 10 by default and at most 100", Schema: &Schema{Type: "integer"}},
+		{Name: "weighting", In: "query", Schema: &Schema{Type: "string", Enum: []string{"tfidf", "bm25"}}},
+		{Name: "lang", In: "query", Description: "ISO 639-1 code of the stopword list, the detected language of the document by default", Schema: &Schema{Type: "string"}},
+		{Name: "min_length", In: "query", Description: "Minimum letters per word, 2 by default", Schema: &Schema{Type: "integer"}},
+	}
+
+	searchParams = []Parameter{
+		{Name: "q", In: "query", Required: true, Description: "Words to look for", Schema: &Schema{Type: "string"}},
+		{Name: "group", In: "query", Description: "One hit per document, the default, or per sentence", Schema: &Schema{Type: "string", Enum: []string{"document", "sentence"}}},
+		{Name: "k", In: "query", Description: "Sentences per document, 3 by default", Schema: &Schema{Type: "integer"}},
+		{Name: "limit", In: "query", Description: "Number of hits, 20 by default and at most 100", Schema: &Schema{Type: "integer"}},
+	}
+
+	entityLimit  = Parameter{Name: "limit", In: "query", Description: "Number of results, 50 by default and at most 500", Schema: &Schema{Type: "integer"}}
+	entityParams = []Parameter{
+		{Name: "text", In: "query", Required: true, Description: "Text of the entity, case insensitive", Schema: &Schema{Type: "string"}},
+		{Name: "label", In: "query", Description: "Label of the entity, such as PERSON or GPE", Schema: &Schema{Type: "string"}},
+		entityLimit,
+	}
+
+	tusResumable = Parameter{Name: "Tus-Resumable", In: "header", Required: true, Description: "Version of the tus protocol, 1.0.0", Schema: &Schema{Type: "string"}}
+
+	similarK = Parameter{Name: "k", In: "query", Description: "Number of neighbors, 10 by default and at most 100", Schema: &Schema{Type: "integer"}}
+
+	filterParams = []Parameter{
+		{Name: "name_prefix", In: "query", Schema: &Schema{Type: "string"}},
+		{Name: "uploaded_after", In: "query", Description: "RFC 3339 timestamp or YYYY-MM-DD date, inclusive", Schema: &Schema{Type: "string"}},
+		{Name: "uploaded_before", In: "query", Description: "RFC 3339 timestamp or YYYY-MM-DD date, exclusive", Schema: &Schema{Type: "string"}},
+		{Name: "min_size", In: "query", Schema: &Schema{Type: "integer"}},
+		{Name: "max_size", In: "query", Schema: &Schema{Type: "integer"}},
+		{Name: "min_pages", In: "query", Schema: &Schema{Type: "integer"}},
+		{Name: "max_pages", In: "query", Schema: &Schema{Type: "integer"}},
+		{Name: "title", In: "query", Description: "Text contained in the title of the metadata, case insensitive", Schema: &Schema{Type: "string"}},
+		{Name: "author", In: "query", Description: "Text contained in the author of the metadata, case insensitive", Schema: &Schema{Type: "string"}},
+		{Name: "keywords", In: "query", Description: "Text contained in the keywords of the metadata, case insensitive", Schema: &Schema{Type: "string"}},
+		{Name: "producer", In: "query", Description: "Text contained in the producer of the metadata, case insensitive", Schema: &Schema{Type: "string"}},
+		{Name: "pdf_version", In: "query", Description: "PDF version, such as 1.7", Schema: &Schema{Type: "string"}},
+		{Name: "encrypted", In: "query", Schema: &Schema{Type: "boolean"}},
+		{Name: "created_after", In: "query", Description: "RFC 3339 timestamp or YYYY-MM-DD date of the metadata, inclusive", Schema: &Schema{Type: "string"}},
+		{Name: "created_before", In: "query", Description: "RFC 3339 timestamp or YYYY-MM-DD date of the metadata, exclusive", Schema: &Schema{Type: "string"}},
+		{Name: "outdated", In: "query", Description: "Only the documents extracted with an older version of the extraction", Schema: &Schema{Type: "boolean"}},
+	}
+
+	sortParams = append([]Parameter{
+		{Name: "sort", In: "query", Schema: &Schema{Type: "string", Enum: []string{"name", "upload_time", "size", "pages"}}},
+		{Name: "order", In: "query", Schema: &Schema{Type: "string", Enum: []string{"asc", "desc"}}},
+	}, filterParams...)
+
+	recordParams = append(sortParams[:len(sortParams):len(sortParams)], pageParams...)
+
+	legacyRecordParams = append(sortParams[:len(sortParams):len(sortParams)], legacyPageParams...)
+)
+
+// Endpoints documents every route registered in cmd/routes.go
+var Endpoints = []Endpoint{
+	{Method: "GET", Path: "/", Summary: "List the available routes", Public: true, Response: map[string]string{}},
+	{Method: "GET", Path: "/metrics", Summary: "Prometheus metrics", ContentType: "text/plain"},
+	{Method: "GET", Path: "/openapi.json", Summary: "OpenAPI specification", Public: true, Response: freeform{}},
+	{Method: "GET", Path: "/docs", Summary: "API documentation", Public: true, ContentType: "text/html"},
+
+	{Method: "GET", Path: "/api/v1/documents", Tag: "documents", Summary: "To get a list of uploaded documents", Query: recordParams, Response: api.DocumentList{}},
+	{Method: "POST", Path: "/api/v1/documents", Tag: "documents", Summary: "To upload a PDF, plain text, Markdown, HTML, DOCX or EPUB document, its format sniffed from its content", Upload: "file", Status: 201, Response: api.Document{}},
+	{Method: "POST", Path: "/api/v1/documents/batch", Tag: "documents", Summary: "To upload many documents at once, as repeated file parts or in zip or tar archives, with the outcome of every file: 201 when all were created, 207 otherwise", Query: []Parameter{
+		{Name: "abort_on_error", In: "query", Description: "Stops the batch on the first failure, skipping the files left and deleting those created", Schema: &Schema{Type: "boolean"}},
+	}, Upload: "file", UploadMany: true, Status: 201, Response: api.BatchResult{}},
+	{Method: "POST", Path: "/api/v1/documents/reextract", Tag: "documents", Summary: "To extract the stored files of the documents matching the filters again, with the outcome of every document: 200 when all were re-extracted, 207 otherwise", Query: append([]Parameter{
+		{Name: "all", In: "query", Description: "Required to re-extract every document when no filter is given", Schema: &Schema{Type: "boolean"}},
+	}, filterParams...), Response: api.ReextractResult{}},
+	{Method: "GET", Path: "/api/v1/documents/:id", Tag: "documents", Summary: "To get the details of a PDF", Response: api.Document{}},
+	{Method: "DELETE", Path: "/api/v1/documents/:id", Tag: "documents", Summary: "To delete a PDF", Status: 204},
+	{Method: "POST", Path: "/api/v1/documents/:id/reextract", Tag: "documents", Summary: "To extract the stored file of a document again, replacing its sentences and everything read from its text at once", Response: api.Document{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/file", Tag: "documents", Summary: "To download a document in its format", ContentType: "application/octet-stream"},
+	{Method: "GET", Path: "/api/v1/documents/:id/sentences", Tag: "documents", Summary: "To get a list of sentences in a PDF", Query: pageParams, Response: api.SentenceList{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/pages", Tag: "documents", Summary: "To get the size of the pages of a PDF and which were recognized with OCR, with their confidence", Response: api.PageList{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/structure", Tag: "documents", Summary: "To get the sections, paragraphs and sentences of a PDF in reading order", Response: api.Structure{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/metadata", Tag: "documents", Summary: "To get the metadata of a PDF: title, author, dates, version and encryption, only for PDFs", Response: api.Metadata{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/outline", Tag: "documents", Summary: "To get the bookmarks of a PDF as a table of contents linked to the page images, only for PDFs", Response: api.Outline{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/tables", Tag: "documents", Summary: "To get the tables found in a PDF", Response: api.TableList{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/tables/:table", Tag: "documents", Summary: "To download the cells of a table of a PDF as JSON or CSV", Query: []Parameter{
+		{Name: "format", In: "query", Description: "csv returns the table as a text/csv attachment", Schema: &Schema{Type: "string", Enum: []string{"json", "csv"}}},
+	}, Response: api.TableData{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/pages/:n", Tag: "documents", Summary: "To get an image of a page in a PDF, only for PDFs", ContentType: "image/jpeg"},
+	{Method: "GET", Path: "/api/v1/documents/:id/occurrences", Tag: "analytics", Summary: "To check the number of occurrences of a word in a PDF", Query: []Parameter{queryTerm}, Response: api.Occurrences{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/concordance", Tag: "analytics", Summary: "To get the occurrences of a word or phrase in a PDF with the words around them", Query: []Parameter{
+		{Name: "q", In: "query", Required: true, Description: "Word or phrase to look for", Schema: &Schema{Type: "string"}},
+		{Name: "window", In: "query", Description: "Words of context on each side, 5 by default and at most 50", Schema: &Schema{Type: "integer"}},
+		{Name: "sort", In: "query", Schema: &Schema{Type: "string", Enum: []string{"position", "left", "right"}}},
+		{Name: "format", In: "query", Description: "text returns the lines aligned on the match as text/plain, the cursor of the next page in X-Next-Cursor", Schema: &Schema{Type: "string", Enum: []string{"json", "text"}}},
+		pageParams[0],
+		pageParams[1],
+	}, Response: api.Concordance{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/frequencies", Tag: "analytics", Summary: "To get the most occurring words or n-grams in a PDF", Query: frequencyParams, Response: api.Frequencies{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/keywords", Tag: "analytics", Summary: "To get the most distinctive words of a PDF against all PDFs", Query: keywordParams, Response: api.Keywords{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/entities", Tag: "entities", Summary: "To get the named entities of an English PDF with their number of mentions", Query: []Parameter{
+		{Name: "label", In: "query", Description: "Only the entities with this label, such as PERSON or GPE", Schema: &Schema{Type: "string"}},
+		entityLimit,
+	}, Response: api.DocumentEntities{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/summary", Tag: "analytics", Summary: "To get an extractive summary of a PDF", Query: []Parameter{
+		{Name: "sentences", In: "query", Description: "Length in sentences, 5 by default", Schema: &Schema{Type: "integer"}},
+		{Name: "percent", In: "query", Description: "Length as a percentage of the sentences of the PDF, instead of sentences", Schema: &Schema{Type: "integer"}},
+	}, Response: api.Summary{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/similar", Tag: "similarity", Summary: "To get the PDFs most similar to a PDF", Query: []Parameter{similarK}, Response: api.SimilarDocuments{}},
+	{Method: "GET", Path: "/api/v1/documents/:id/duplicates", Tag: "similarity", Summary: "To get the PDFs sharing passages with a PDF and the overlapping sentences", Query: []Parameter{
+		{Name: "threshold", In: "query", Description: "Minimum Jaccard similarity of two sentences, 0.5 by default", Schema: &Schema{Type: "number"}},
+	}, Response: api.Duplicates{}},
+	{Method: "GET", Path: "/api/v1/sentences/:id/similar", Tag: "similarity", Summary: "To get the sentences most similar to a sentence in all PDFs", Query: []Parameter{
+		similarK,
+		{Name: "other_documents", In: "query", Description: "Skip the sentences of the same PDF", Schema: &Schema{Type: "boolean"}},
+	}, Response: api.SimilarSentences{}},
+	{Method: "GET", Path: "/api/v1/entities/documents", Tag: "entities", Summary: "To get the PDFs mentioning a named entity", Query: entityParams, Response: api.EntityDocuments{}},
+	{Method: "GET", Path: "/api/v1/entities/cooccurrences", Tag: "entities", Summary: "To get the named entities mentioned in the same sentences as an entity", Query: append([]Parameter{
+		{Name: "document_id", In: "query", Description: "Only the sentences of this PDF", Schema: &Schema{Type: "integer"}},
+	}, entityParams...), Response: api.CoOccurrences{}},
+	{Method: "GET", Path: "/api/v1/search", Tag: "search", Summary: "To search all PDFs, ranking the matching sentences and PDFs with BM25", Query: searchParams, Response: api.SearchResults{}},
+
+	{Method: "OPTIONS", Path: "/api/v1/uploads", Tag: "uploads", Summary: "To get the version, extensions, maximum size and checksum algorithms of the tus protocol of the resumable uploads", Status: 204},
+	{Method: "POST", Path: "/api/v1/uploads", Tag: "uploads", Summary: "To start a resumable upload with the tus protocol, its chunks then sent with PATCH", Query: append([]Parameter{
+		{Name: "Upload-Length", In: "header", Required: true, Description: "Size of the file in bytes", Schema: &Schema{Type: "integer"}},
+		{Name: "Upload-Metadata", In: "header", Description: "Comma separated keys with their base64 values: filename, filetype and checksum, the algorithm and base64 digest of the whole file", Schema: &Schema{Type: "string"}},
+	}, tusResumable), Status: 201, Response: api.Upload{}},
+	{Method: "GET", Path: "/api/v1/uploads/:id", Tag: "uploads", Summary: "To get the bytes received of a resumable upload, and its document once ingested. HEAD answers with the Upload-Offset to resume from.", Response: api.Upload{}},
+	{Method: "PATCH", Path: "/api/v1/uploads/:id", Tag: "uploads", Summary: "To send a chunk of a resumable upload, of at least 5 MiB but the last one. The file is ingested once received, 460 when it fails its checksum, the upload then kept until deleted.", Query: append([]Parameter{
+		{Name: "Upload-Offset", In: "header", Required: true, Description: "Bytes received so far, as returned by HEAD", Schema: &Schema{Type: "integer"}},
+		{Name: "Upload-Checksum", In: "header", Description: "Algorithm and base64 digest of the chunk", Schema: &Schema{Type: "string"}},
+	}, tusResumable), Body: "application/offset+octet-stream", Status: 204},
+	{Method: "DELETE", Path: "/api/v1/uploads/:id", Tag: "uploads", Summary: "To abort a resumable upload, deleting the chunks received", Status: 204},
+
+	{Method: "POST", Path: "/uploadPDF", Tag: "legacy", Deprecated: true, Summary: "Upload a PDF", Upload: "pdf", Response: freeform{}},
+	{Method: "GET", Path: "/listPDF", Tag: "legacy", Deprecated: true, Summary: "List the uploaded PDFs", Query: legacyRecordParams, Response: []database.Record{}},
+	{Method: "GET", Path: "/searchKeyword/:key", Tag: "legacy", Deprecated: true, Summary: "Search a keyword in all PDFs", Response: freeform{}},
+	{Method: "GET", Path: "/getPDF/:id", Tag: "legacy", Deprecated: true, Summary: "Download a PDF", ContentType: "application/pdf"},
+	{Method: "GET", Path: "/listSentences/:id", Tag: "legacy", Deprecated: true, Summary: "List the sentences of a PDF", Query: legacyPageParams, Response: []database.Sentence{}},
+	{Method: "GET", Path: "/getOccurrence/:id/:key", Tag: "legacy", Deprecated: true, Summary: "Count the occurrences of a word in a PDF", Response: freeform{}},
+	{Method: "GET", Path: "/getMostOccurring/:id", Tag: "legacy", Deprecated: true, Summary: "Top 5 occurring words of a PDF", Response: freeform{}},
+	{Method: "GET", Path: "/getPDF/:id/:page", Tag: "legacy", Deprecated: true, Summary: "Image of a page of a PDF", ContentType: "image/jpeg"},
+	{Method: "DELETE", Path: "/deletePDF", Tag: "legacy", Deprecated: true, Summary: "Delete a PDF given its id as a form value", Response: freeform{}},
+}
@@ -0,0 +1,211 @@
+package openapi
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"main/api"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// Endpoint documents one registered route
+type Endpoint struct {
+	Method     string
+	Path       string
+	Summary    string
+	Tag        string
+	Public     bool
+	Deprecated bool
+	Query      []Parameter
+	Upload     string
+	// UploadMany lets the Upload field be repeated, one part per file
+	UploadMany bool
+	// Body is the content type of a raw request body, such as the chunks of a resumable upload
+	Body     string
+	Status   int
+	Response interface{}
+	// ContentType of the response when it is not JSON
+	ContentType string
+}
+
+// freeform stands for the untyped bodies of the legacy routes
+type freeform map[string]interface{}
+
+// Build generates the OpenAPI document of the endpoints
+func Build(endpoints []Endpoint) *Spec {
+	components := schemas{}
+	errorSchema := components.of(api.ErrorEnvelope{})
+
+	spec := &Spec{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: "Codestacker PDF API", Version: "1.0.0"},
+		Paths:   map[string]map[string]Operation{},
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{"basicAuth": {Type: "http", Scheme: "basic"}},
+		},
+	}
+
+	for _, endpoint := range endpoints {
+		path := specPath(endpoint.Path)
+		if spec.Paths[path] == nil {
+			spec.Paths[path] = map[string]Operation{}
+		}
+
+		status := endpoint.Status
+		if status == 0 {
+			status = fiber.StatusOK
+		}
+
+		success := Response{Description: endpoint.Summary}
+		switch {
+		case endpoint.ContentType != "":
+			success.Content = map[string]MediaType{endpoint.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+		case endpoint.Response != nil:
+			success.Content = map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: components.of(endpoint.Response)}}
+		}
+
+		operation := Operation{
+			OperationID: operationID(endpoint.Method, endpoint.Path),
+			Summary:     endpoint.Summary,
+			Deprecated:  endpoint.Deprecated,
+			Responses: map[string]Response{
+				fmt.Sprint(status): success,
+				"default": {
+					Description: "Error",
+					Content:     map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: errorSchema}},
+				},
+			},
+		}
+		if endpoint.Tag != "" {
+			operation.Tags = []string{endpoint.Tag}
+		}
+		if !endpoint.Public {
+			operation.Security = []map[string][]string{{"basicAuth": {}}}
+		}
+
+		for _, name := range pathParams(endpoint.Path) {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+		operation.Parameters = append(operation.Parameters, endpoint.Query...)
+
+		if endpoint.Upload != "" {
+			file := &Schema{Type: "string", Format: "binary"}
+			if endpoint.UploadMany {
+				file = &Schema{Type: "array", Items: file}
+			}
+			operation.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{fiber.MIMEMultipartForm: {Schema: &Schema{
+					Type:       "object",
+					Properties: map[string]*Schema{endpoint.Upload: file},
+					Required:   []string{endpoint.Upload},
+				}}},
+			}
+		}
+
+		if endpoint.Body != "" {
+			operation.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{endpoint.Body: {Schema: &Schema{Type: "string", Format: "binary"}}},
+			}
+		}
+
+		spec.Paths[path][strings.ToLower(endpoint.Method)] = operation
+	}
+
+	spec.Components.Schemas = components
+	return spec
+}
+
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.Split(path, "/") {
+		part = strings.Trim(part, ":?")
+		if part == "" {
+			continue
+		}
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// Validate reports the registered routes missing from the endpoints and the endpoints that are not registered
+func Validate(routes []fiber.Route, endpoints []Endpoint) error {
+	documented := map[string]bool{}
+	for _, endpoint := range endpoints {
+		documented[endpoint.Method+" "+endpoint.Path] = true
+	}
+
+	registered := map[string]bool{}
+	missing := []string{}
+	for _, route := range routes {
+		// fiber registers a HEAD route for every GET route
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+	}
+
+	unknown := []string{}
+	for key := range documented {
+		if !registered[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(missing) == 0 && len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+	sort.Strings(unknown)
+	message := []string{}
+	if len(missing) > 0 {
+		message = append(message, "routes missing from the spec: "+strings.Join(missing, ", "))
+	}
+	if len(unknown) > 0 {
+		message = append(message, "documented routes that are not registered: "+strings.Join(unknown, ", "))
+	}
+	return errors.New(strings.Join(message, "; "))
+}
+
+// Handler serves the OpenAPI document
+func Handler(spec *Spec) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(spec)
+	}
+}
+
+// DocsHandler serves the Redoc page rendering /openapi.json
+func DocsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Type("html")
+		return c.Send(docsPage)
+	}
+}
+
+// Welcome lists the documented endpoints by summary
+func Welcome(endpoints []Endpoint) map[string]string {
+	welcome := map[string]string{}
+	for _, endpoint := range endpoints {
+		if endpoint.Deprecated || endpoint.Public {
+			continue
+		}
+		welcome[endpoint.Summary] = endpoint.Method + " " + endpoint.Path
+	}
+	welcome["OpenAPI specification"] = "GET /openapi.json"
+	welcome["API documentation"] = "GET /docs"
+	return welcome
+}
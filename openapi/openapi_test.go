@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestValidate(t *testing.T) {
+	routes := []fiber.Route{
+		{Method: fiber.MethodGet, Path: "/documents"},
+		{Method: fiber.MethodHead, Path: "/documents"},
+		{Method: fiber.MethodPost, Path: "/documents"},
+	}
+	tests := []struct {
+		name      string
+		endpoints []Endpoint
+		want      string
+	}{
+		{"documented", []Endpoint{{Method: "GET", Path: "/documents"}, {Method: "POST", Path: "/documents"}}, ""},
+		{"route missing", []Endpoint{{Method: "GET", Path: "/documents"}}, "routes missing from the spec: POST /documents"},
+		{"not registered", []Endpoint{{Method: "GET", Path: "/documents"}, {Method: "POST", Path: "/documents"}, {Method: "DELETE", Path: "/documents"}}, "documented routes that are not registered: DELETE /documents"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(routes, tt.endpoints)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
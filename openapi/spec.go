@@ -0,0 +1,186 @@
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Spec is the subset of an OpenAPI 3 document used by the service
+type Spec struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Tags        []string              `json:"tags,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+}
+
+var fiberParam = regexp.MustCompile(`:([A-Za-z0-9_]+)\??`)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// specPath converts a fiber route path ("/documents/:id") to an OpenAPI path ("/documents/{id}")
+func specPath(path string) string {
+	return fiberParam.ReplaceAllString(path, "{$1}")
+}
+
+// pathParams lists the parameter names of a fiber route path
+func pathParams(path string) []string {
+	params := []string{}
+	for _, match := range fiberParam.FindAllStringSubmatch(path, -1) {
+		params = append(params, match[1])
+	}
+	return params
+}
+
+// schemas builds component schemas from Go types using their json tags
+type schemas map[string]*Schema
+
+func (s schemas) of(value interface{}) *Schema {
+	return s.schema(reflect.TypeOf(value))
+}
+
+func (s schemas) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		schema := s.schema(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.String:
+		return &Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case t.Kind() == reflect.Int32 || t.Kind() == reflect.Uint32 || t.Kind() == reflect.Int16 || t.Kind() == reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return &Schema{Type: "string", Format: "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case t.Kind() == reflect.Struct:
+		return s.object(t)
+	}
+
+	return &Schema{}
+}
+
+func (s schemas) object(t reflect.Type) *Schema {
+	name := t.Name()
+	if name != "" {
+		if _, ok := s[name]; ok {
+			return &Schema{Ref: "#/components/schemas/" + name}
+		}
+		// Reserve the name first so recursive types terminate
+		s[name] = nil
+	}
+
+	object := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.fields(object, t)
+
+	if name == "" {
+		return object
+	}
+	s[name] = object
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// fields adds the properties of a struct to object, promoting those of embedded structs like encoding/json
+func (s schemas) fields(object *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			s.fields(object, field.Type)
+			continue
+		}
+		fieldName, options, _ := strings.Cut(tag, ",")
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+
+		object.Properties[fieldName] = s.schema(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			object.Required = append(object.Required, fieldName)
+		}
+	}
+}
@@ -10,11 +10,21 @@ ORDER BY name;
 INSERT INTO records (
   name,
   numOfPages,
-  size
+  size,
+  language,
+  mimeType,
+  objectKey,
+  extractor,
+  extractorVersion
 ) VALUES (
   $1,
   $2,
-  $3
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8
 )
 RETURNING *;
 
@@ -22,18 +32,61 @@ RETURNING *;
 DELETE FROM records
 WHERE name = $1;
 
+-- name: DeleteRecordByID :exec
+DELETE FROM records
+WHERE id = $1;
+
+-- name: CountObjectRecords :one
+SELECT count(*) FROM records
+WHERE objectKey = $1;
+
 -- name: UpdateRecord :exec
 UPDATE records
   set name = $2
 WHERE id = $1;
 
+-- name: UpdateRecordLanguage :exec
+UPDATE records
+  set language = $2
+WHERE id = $1;
+
+-- name: UpdateRecordExtraction :one
+UPDATE records
+  set numOfPages = $2, language = $3, extractor = $4, extractorVersion = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteRecordExtraction :exec
+WITH deleted_sentences AS (
+  DELETE FROM sentences WHERE pdfId = $1
+), deleted_sections AS (
+  DELETE FROM sections WHERE pdfId = $1
+), deleted_pages AS (
+  DELETE FROM pages WHERE pdfId = $1
+), deleted_tables AS (
+  DELETE FROM document_tables WHERE pdfId = $1
+), deleted_metadata AS (
+  DELETE FROM document_metadata WHERE pdfId = $1
+), deleted_outline AS (
+  DELETE FROM outline_items WHERE pdfId = $1
+), deleted_terms AS (
+  DELETE FROM document_terms WHERE pdfId = $1
+), deleted_stats AS (
+  DELETE FROM document_stats WHERE pdfId = $1
+)
+DELETE FROM duplicate_index WHERE pdfId = $1;
+
 -- name: CreateSentence :one
 INSERT INTO sentences (
   sentence,
-  pdfId
+  pdfId,
+  page,
+  paragraphId
 ) VALUES (
   $1,
-  $2
+  $2,
+  $3,
+  $4
   )
   RETURNING *;
 
@@ -41,4 +94,457 @@ INSERT INTO sentences (
 SELECT * FROM sentences;
 
 -- name: ListRecordSentences :many
-SELECT * FROM sentences where pdfId = $1;
\ No newline at end of file
+SELECT * FROM sentences where pdfId = $1;
+
+-- name: ListRecordsByName :many
+SELECT records.* FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE (sqlc.narg('name_prefix')::text IS NULL OR name LIKE sqlc.narg('name_prefix')::text || '%')
+  AND (sqlc.narg('uploaded_after')::timestamp IS NULL OR upload_time >= sqlc.narg('uploaded_after')::timestamp)
+  AND (sqlc.narg('uploaded_before')::timestamp IS NULL OR upload_time < sqlc.narg('uploaded_before')::timestamp)
+  AND (sqlc.narg('min_size')::bigint IS NULL OR size >= sqlc.narg('min_size')::bigint)
+  AND (sqlc.narg('max_size')::bigint IS NULL OR size <= sqlc.narg('max_size')::bigint)
+  AND (sqlc.narg('min_pages')::int IS NULL OR numOfPages >= sqlc.narg('min_pages')::int)
+  AND (sqlc.narg('max_pages')::int IS NULL OR numOfPages <= sqlc.narg('max_pages')::int)
+  AND (sqlc.narg('title')::text IS NULL OR metadata.title ILIKE '%' || sqlc.narg('title')::text || '%')
+  AND (sqlc.narg('author')::text IS NULL OR metadata.author ILIKE '%' || sqlc.narg('author')::text || '%')
+  AND (sqlc.narg('keywords')::text IS NULL OR metadata.keywords ILIKE '%' || sqlc.narg('keywords')::text || '%')
+  AND (sqlc.narg('producer')::text IS NULL OR metadata.producer ILIKE '%' || sqlc.narg('producer')::text || '%')
+  AND (sqlc.narg('pdf_version')::text IS NULL OR metadata.pdfVersion = sqlc.narg('pdf_version')::text)
+  AND (sqlc.narg('encrypted')::bool IS NULL OR metadata.encrypted = sqlc.narg('encrypted')::bool)
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR metadata.created >= sqlc.narg('created_after')::timestamp)
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR metadata.created < sqlc.narg('created_before')::timestamp)
+  AND (sqlc.narg('extractor_version_below')::int IS NULL OR extractorVersion < sqlc.narg('extractor_version_below')::int)
+  AND (sqlc.narg('after_key')::text IS NULL OR (name, records.id) > (sqlc.narg('after_key')::text, @after_id::int))
+ORDER BY name, records.id
+LIMIT @page_size::int;
+
+-- name: ListRecordsByNameDesc :many
+SELECT records.* FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE (sqlc.narg('name_prefix')::text IS NULL OR name LIKE sqlc.narg('name_prefix')::text || '%')
+  AND (sqlc.narg('uploaded_after')::timestamp IS NULL OR upload_time >= sqlc.narg('uploaded_after')::timestamp)
+  AND (sqlc.narg('uploaded_before')::timestamp IS NULL OR upload_time < sqlc.narg('uploaded_before')::timestamp)
+  AND (sqlc.narg('min_size')::bigint IS NULL OR size >= sqlc.narg('min_size')::bigint)
+  AND (sqlc.narg('max_size')::bigint IS NULL OR size <= sqlc.narg('max_size')::bigint)
+  AND (sqlc.narg('min_pages')::int IS NULL OR numOfPages >= sqlc.narg('min_pages')::int)
+  AND (sqlc.narg('max_pages')::int IS NULL OR numOfPages <= sqlc.narg('max_pages')::int)
+  AND (sqlc.narg('title')::text IS NULL OR metadata.title ILIKE '%' || sqlc.narg('title')::text || '%')
+  AND (sqlc.narg('author')::text IS NULL OR metadata.author ILIKE '%' || sqlc.narg('author')::text || '%')
+  AND (sqlc.narg('keywords')::text IS NULL OR metadata.keywords ILIKE '%' || sqlc.narg('keywords')::text || '%')
+  AND (sqlc.narg('producer')::text IS NULL OR metadata.producer ILIKE '%' || sqlc.narg('producer')::text || '%')
+  AND (sqlc.narg('pdf_version')::text IS NULL OR metadata.pdfVersion = sqlc.narg('pdf_version')::text)
+  AND (sqlc.narg('encrypted')::bool IS NULL OR metadata.encrypted = sqlc.narg('encrypted')::bool)
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR metadata.created >= sqlc.narg('created_after')::timestamp)
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR metadata.created < sqlc.narg('created_before')::timestamp)
+  AND (sqlc.narg('extractor_version_below')::int IS NULL OR extractorVersion < sqlc.narg('extractor_version_below')::int)
+  AND (sqlc.narg('after_key')::text IS NULL OR (name, records.id) < (sqlc.narg('after_key')::text, @after_id::int))
+ORDER BY name DESC, records.id DESC
+LIMIT @page_size::int;
+
+-- name: ListRecordsByUploadTime :many
+SELECT records.* FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE (sqlc.narg('name_prefix')::text IS NULL OR name LIKE sqlc.narg('name_prefix')::text || '%')
+  AND (sqlc.narg('uploaded_after')::timestamp IS NULL OR upload_time >= sqlc.narg('uploaded_after')::timestamp)
+  AND (sqlc.narg('uploaded_before')::timestamp IS NULL OR upload_time < sqlc.narg('uploaded_before')::timestamp)
+  AND (sqlc.narg('min_size')::bigint IS NULL OR size >= sqlc.narg('min_size')::bigint)
+  AND (sqlc.narg('max_size')::bigint IS NULL OR size <= sqlc.narg('max_size')::bigint)
+  AND (sqlc.narg('min_pages')::int IS NULL OR numOfPages >= sqlc.narg('min_pages')::int)
+  AND (sqlc.narg('max_pages')::int IS NULL OR numOfPages <= sqlc.narg('max_pages')::int)
+  AND (sqlc.narg('title')::text IS NULL OR metadata.title ILIKE '%' || sqlc.narg('title')::text || '%')
+  AND (sqlc.narg('author')::text IS NULL OR metadata.author ILIKE '%' || sqlc.narg('author')::text || '%')
+  AND (sqlc.narg('keywords')::text IS NULL OR metadata.keywords ILIKE '%' || sqlc.narg('keywords')::text || '%')
+  AND (sqlc.narg('producer')::text IS NULL OR metadata.producer ILIKE '%' || sqlc.narg('producer')::text || '%')
+  AND (sqlc.narg('pdf_version')::text IS NULL OR metadata.pdfVersion = sqlc.narg('pdf_version')::text)
+  AND (sqlc.narg('encrypted')::bool IS NULL OR metadata.encrypted = sqlc.narg('encrypted')::bool)
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR metadata.created >= sqlc.narg('created_after')::timestamp)
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR metadata.created < sqlc.narg('created_before')::timestamp)
+  AND (sqlc.narg('extractor_version_below')::int IS NULL OR extractorVersion < sqlc.narg('extractor_version_below')::int)
+  AND (sqlc.narg('after_key')::text IS NULL OR (upload_time, records.id) > (CAST(sqlc.narg('after_key')::text AS timestamp), @after_id::int))
+ORDER BY upload_time, records.id
+LIMIT @page_size::int;
+
+-- name: ListRecordsByUploadTimeDesc :many
+SELECT records.* FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE (sqlc.narg('name_prefix')::text IS NULL OR name LIKE sqlc.narg('name_prefix')::text || '%')
+  AND (sqlc.narg('uploaded_after')::timestamp IS NULL OR upload_time >= sqlc.narg('uploaded_after')::timestamp)
+  AND (sqlc.narg('uploaded_before')::timestamp IS NULL OR upload_time < sqlc.narg('uploaded_before')::timestamp)
+  AND (sqlc.narg('min_size')::bigint IS NULL OR size >= sqlc.narg('min_size')::bigint)
+  AND (sqlc.narg('max_size')::bigint IS NULL OR size <= sqlc.narg('max_size')::bigint)
+  AND (sqlc.narg('min_pages')::int IS NULL OR numOfPages >= sqlc.narg('min_pages')::int)
+  AND (sqlc.narg('max_pages')::int IS NULL OR numOfPages <= sqlc.narg('max_pages')::int)
+  AND (sqlc.narg('title')::text IS NULL OR metadata.title ILIKE '%' || sqlc.narg('title')::text || '%')
+  AND (sqlc.narg('author')::text IS NULL OR metadata.author ILIKE '%' || sqlc.narg('author')::text || '%')
+  AND (sqlc.narg('keywords')::text IS NULL OR metadata.keywords ILIKE '%' || sqlc.narg('keywords')::text || '%')
+  AND (sqlc.narg('producer')::text IS NULL OR metadata.producer ILIKE '%' || sqlc.narg('producer')::text || '%')
+  AND (sqlc.narg('pdf_version')::text IS NULL OR metadata.pdfVersion = sqlc.narg('pdf_version')::text)
+  AND (sqlc.narg('encrypted')::bool IS NULL OR metadata.encrypted = sqlc.narg('encrypted')::bool)
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR metadata.created >= sqlc.narg('created_after')::timestamp)
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR metadata.created < sqlc.narg('created_before')::timestamp)
+  AND (sqlc.narg('extractor_version_below')::int IS NULL OR extractorVersion < sqlc.narg('extractor_version_below')::int)
+  AND (sqlc.narg('after_key')::text IS NULL OR (upload_time, records.id) < (CAST(sqlc.narg('after_key')::text AS timestamp), @after_id::int))
+ORDER BY upload_time DESC, records.id DESC
+LIMIT @page_size::int;
+
+-- name: ListRecordsBySize :many
+SELECT records.* FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE (sqlc.narg('name_prefix')::text IS NULL OR name LIKE sqlc.narg('name_prefix')::text || '%')
+  AND (sqlc.narg('uploaded_after')::timestamp IS NULL OR upload_time >= sqlc.narg('uploaded_after')::timestamp)
+  AND (sqlc.narg('uploaded_before')::timestamp IS NULL OR upload_time < sqlc.narg('uploaded_before')::timestamp)
+  AND (sqlc.narg('min_size')::bigint IS NULL OR size >= sqlc.narg('min_size')::bigint)
+  AND (sqlc.narg('max_size')::bigint IS NULL OR size <= sqlc.narg('max_size')::bigint)
+  AND (sqlc.narg('min_pages')::int IS NULL OR numOfPages >= sqlc.narg('min_pages')::int)
+  AND (sqlc.narg('max_pages')::int IS NULL OR numOfPages <= sqlc.narg('max_pages')::int)
+  AND (sqlc.narg('title')::text IS NULL OR metadata.title ILIKE '%' || sqlc.narg('title')::text || '%')
+  AND (sqlc.narg('author')::text IS NULL OR metadata.author ILIKE '%' || sqlc.narg('author')::text || '%')
+  AND (sqlc.narg('keywords')::text IS NULL OR metadata.keywords ILIKE '%' || sqlc.narg('keywords')::text || '%')
+  AND (sqlc.narg('producer')::text IS NULL OR metadata.producer ILIKE '%' || sqlc.narg('producer')::text || '%')
+  AND (sqlc.narg('pdf_version')::text IS NULL OR metadata.pdfVersion = sqlc.narg('pdf_version')::text)
+  AND (sqlc.narg('encrypted')::bool IS NULL OR metadata.encrypted = sqlc.narg('encrypted')::bool)
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR metadata.created >= sqlc.narg('created_after')::timestamp)
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR metadata.created < sqlc.narg('created_before')::timestamp)
+  AND (sqlc.narg('extractor_version_below')::int IS NULL OR extractorVersion < sqlc.narg('extractor_version_below')::int)
+  AND (sqlc.narg('after_key')::text IS NULL OR (size, records.id) > (CAST(sqlc.narg('after_key')::text AS bigint), @after_id::int))
+ORDER BY size, records.id
+LIMIT @page_size::int;
+
+-- name: ListRecordsBySizeDesc :many
+SELECT records.* FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE (sqlc.narg('name_prefix')::text IS NULL OR name LIKE sqlc.narg('name_prefix')::text || '%')
+  AND (sqlc.narg('uploaded_after')::timestamp IS NULL OR upload_time >= sqlc.narg('uploaded_after')::timestamp)
+  AND (sqlc.narg('uploaded_before')::timestamp IS NULL OR upload_time < sqlc.narg('uploaded_before')::timestamp)
+  AND (sqlc.narg('min_size')::bigint IS NULL OR size >= sqlc.narg('min_size')::bigint)
+  AND (sqlc.narg('max_size')::bigint IS NULL OR size <= sqlc.narg('max_size')::bigint)
+  AND (sqlc.narg('min_pages')::int IS NULL OR numOfPages >= sqlc.narg('min_pages')::int)
+  AND (sqlc.narg('max_pages')::int IS NULL OR numOfPages <= sqlc.narg('max_pages')::int)
+  AND (sqlc.narg('title')::text IS NULL OR metadata.title ILIKE '%' || sqlc.narg('title')::text || '%')
+  AND (sqlc.narg('author')::text IS NULL OR metadata.author ILIKE '%' || sqlc.narg('author')::text || '%')
+  AND (sqlc.narg('keywords')::text IS NULL OR metadata.keywords ILIKE '%' || sqlc.narg('keywords')::text || '%')
+  AND (sqlc.narg('producer')::text IS NULL OR metadata.producer ILIKE '%' || sqlc.narg('producer')::text || '%')
+  AND (sqlc.narg('pdf_version')::text IS NULL OR metadata.pdfVersion = sqlc.narg('pdf_version')::text)
+  AND (sqlc.narg('encrypted')::bool IS NULL OR metadata.encrypted = sqlc.narg('encrypted')::bool)
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR metadata.created >= sqlc.narg('created_after')::timestamp)
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR metadata.created < sqlc.narg('created_before')::timestamp)
+  AND (sqlc.narg('extractor_version_below')::int IS NULL OR extractorVersion < sqlc.narg('extractor_version_below')::int)
+  AND (sqlc.narg('after_key')::text IS NULL OR (size, records.id) < (CAST(sqlc.narg('after_key')::text AS bigint), @after_id::int))
+ORDER BY size DESC, records.id DESC
+LIMIT @page_size::int;
+
+-- name: ListRecordsByPages :many
+SELECT records.* FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE (sqlc.narg('name_prefix')::text IS NULL OR name LIKE sqlc.narg('name_prefix')::text || '%')
+  AND (sqlc.narg('uploaded_after')::timestamp IS NULL OR upload_time >= sqlc.narg('uploaded_after')::timestamp)
+  AND (sqlc.narg('uploaded_before')::timestamp IS NULL OR upload_time < sqlc.narg('uploaded_before')::timestamp)
+  AND (sqlc.narg('min_size')::bigint IS NULL OR size >= sqlc.narg('min_size')::bigint)
+  AND (sqlc.narg('max_size')::bigint IS NULL OR size <= sqlc.narg('max_size')::bigint)
+  AND (sqlc.narg('min_pages')::int IS NULL OR numOfPages >= sqlc.narg('min_pages')::int)
+  AND (sqlc.narg('max_pages')::int IS NULL OR numOfPages <= sqlc.narg('max_pages')::int)
+  AND (sqlc.narg('title')::text IS NULL OR metadata.title ILIKE '%' || sqlc.narg('title')::text || '%')
+  AND (sqlc.narg('author')::text IS NULL OR metadata.author ILIKE '%' || sqlc.narg('author')::text || '%')
+  AND (sqlc.narg('keywords')::text IS NULL OR metadata.keywords ILIKE '%' || sqlc.narg('keywords')::text || '%')
+  AND (sqlc.narg('producer')::text IS NULL OR metadata.producer ILIKE '%' || sqlc.narg('producer')::text || '%')
+  AND (sqlc.narg('pdf_version')::text IS NULL OR metadata.pdfVersion = sqlc.narg('pdf_version')::text)
+  AND (sqlc.narg('encrypted')::bool IS NULL OR metadata.encrypted = sqlc.narg('encrypted')::bool)
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR metadata.created >= sqlc.narg('created_after')::timestamp)
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR metadata.created < sqlc.narg('created_before')::timestamp)
+  AND (sqlc.narg('extractor_version_below')::int IS NULL OR extractorVersion < sqlc.narg('extractor_version_below')::int)
+  AND (sqlc.narg('after_key')::text IS NULL OR (numOfPages, records.id) > (CAST(sqlc.narg('after_key')::text AS int), @after_id::int))
+ORDER BY numOfPages, records.id
+LIMIT @page_size::int;
+
+-- name: ListRecordsByPagesDesc :many
+SELECT records.* FROM records
+LEFT JOIN document_metadata AS metadata ON metadata.pdfId = records.id
+WHERE (sqlc.narg('name_prefix')::text IS NULL OR name LIKE sqlc.narg('name_prefix')::text || '%')
+  AND (sqlc.narg('uploaded_after')::timestamp IS NULL OR upload_time >= sqlc.narg('uploaded_after')::timestamp)
+  AND (sqlc.narg('uploaded_before')::timestamp IS NULL OR upload_time < sqlc.narg('uploaded_before')::timestamp)
+  AND (sqlc.narg('min_size')::bigint IS NULL OR size >= sqlc.narg('min_size')::bigint)
+  AND (sqlc.narg('max_size')::bigint IS NULL OR size <= sqlc.narg('max_size')::bigint)
+  AND (sqlc.narg('min_pages')::int IS NULL OR numOfPages >= sqlc.narg('min_pages')::int)
+  AND (sqlc.narg('max_pages')::int IS NULL OR numOfPages <= sqlc.narg('max_pages')::int)
+  AND (sqlc.narg('title')::text IS NULL OR metadata.title ILIKE '%' || sqlc.narg('title')::text || '%')
+  AND (sqlc.narg('author')::text IS NULL OR metadata.author ILIKE '%' || sqlc.narg('author')::text || '%')
+  AND (sqlc.narg('keywords')::text IS NULL OR metadata.keywords ILIKE '%' || sqlc.narg('keywords')::text || '%')
+  AND (sqlc.narg('producer')::text IS NULL OR metadata.producer ILIKE '%' || sqlc.narg('producer')::text || '%')
+  AND (sqlc.narg('pdf_version')::text IS NULL OR metadata.pdfVersion = sqlc.narg('pdf_version')::text)
+  AND (sqlc.narg('encrypted')::bool IS NULL OR metadata.encrypted = sqlc.narg('encrypted')::bool)
+  AND (sqlc.narg('created_after')::timestamp IS NULL OR metadata.created >= sqlc.narg('created_after')::timestamp)
+  AND (sqlc.narg('created_before')::timestamp IS NULL OR metadata.created < sqlc.narg('created_before')::timestamp)
+  AND (sqlc.narg('extractor_version_below')::int IS NULL OR extractorVersion < sqlc.narg('extractor_version_below')::int)
+  AND (sqlc.narg('after_key')::text IS NULL OR (numOfPages, records.id) < (CAST(sqlc.narg('after_key')::text AS int), @after_id::int))
+ORDER BY numOfPages DESC, records.id DESC
+LIMIT @page_size::int;
+
+-- name: ListRecordSentencesPage :many
+SELECT * FROM sentences
+WHERE pdfId = @pdfid AND id > @after_id
+ORDER BY id
+LIMIT @page_size::int;
+
+-- name: ListRecordSentencesInPages :many
+SELECT * FROM sentences
+WHERE pdfId = @pdfid
+  AND (sqlc.narg('page_from')::int IS NULL OR page >= sqlc.narg('page_from')::int)
+  AND (sqlc.narg('page_to')::int IS NULL OR page <= sqlc.narg('page_to')::int)
+ORDER BY id;
+
+-- name: IndexDocument :exec
+WITH stats AS (
+  INSERT INTO document_stats (pdfId, term_count)
+  VALUES (@pdfid, @term_count)
+)
+INSERT INTO document_terms (pdfId, term, count, surface)
+SELECT @pdfid, unnest(@terms::text[]), unnest(@counts::int[]), unnest(@surfaces::text[]);
+
+-- name: GetCorpusStats :one
+SELECT count(*)::int AS documents,
+  COALESCE(avg(term_count), 0)::float8 AS average_length,
+  COALESCE(sum(term_count), 0)::bigint AS terms,
+  (SELECT count(*) FROM sentences WHERE pdfId IN (SELECT pdfId FROM document_stats))::bigint AS sentences
+FROM document_stats;
+
+-- name: GetDocumentStats :one
+SELECT * FROM document_stats
+WHERE pdfId = $1;
+
+-- name: ListDocumentTermStats :many
+SELECT d.term, d.surface, d.count, COALESCE(t.document_count, 0)::int AS document_count
+FROM document_terms d
+LEFT JOIN term_stats t ON t.term = d.term
+WHERE d.pdfId = @pdfid;
+
+-- name: ListUnindexedRecords :many
+SELECT * FROM records
+WHERE NOT EXISTS (SELECT 1 FROM document_stats WHERE document_stats.pdfId = records.id)
+ORDER BY id;
+
+-- name: ListTermDocuments :many
+SELECT d.pdfId, d.term, d.count, s.term_count, COALESCE(t.document_count, 0)::int AS document_count, r.language
+FROM document_terms d
+JOIN document_stats s ON s.pdfId = d.pdfId
+JOIN records r ON r.id = d.pdfId
+LEFT JOIN term_stats t ON t.term = d.term
+WHERE d.term = ANY(@terms::text[]);
+
+-- name: SearchSentences :many
+SELECT * FROM sentences
+WHERE sentence ~* @pattern::text AND pdfId = ANY(@ids::int[])
+ORDER BY array_position(@ids::int[], pdfId), id
+LIMIT @max_sentences::int;
+
+-- name: ListRecordLanguages :many
+SELECT DISTINCT language FROM records
+ORDER BY language;
+
+-- name: ListRecordsByID :many
+SELECT * FROM records
+WHERE id = ANY(@ids::int[])
+ORDER BY id;
+
+-- name: CreateEntities :exec
+INSERT INTO entities (sentenceId, pdfId, text, label)
+SELECT unnest(@sentence_ids::int[]), @pdfid, unnest(@texts::text[]), unnest(@labels::text[]);
+
+-- name: ListDocumentEntities :many
+SELECT text, label, count(*) AS mentions
+FROM entities
+WHERE pdfId = @pdfid
+  AND (sqlc.narg('label')::text IS NULL OR label = sqlc.narg('label')::text)
+GROUP BY text, label
+ORDER BY mentions DESC, text, label
+LIMIT @page_size::int;
+
+-- name: ListEntityDocuments :many
+SELECT r.id, r.name, r.upload_time, r.numofpages, r.size, r.language, r.mimetype, r.objectkey, r.extractor, r.extractorversion, count(*) AS mentions
+FROM entities e
+JOIN records r ON r.id = e.pdfId
+WHERE lower(e.text) = lower(@text::text)
+  AND (sqlc.narg('label')::text IS NULL OR e.label = sqlc.narg('label')::text)
+GROUP BY r.id
+ORDER BY mentions DESC, r.id
+LIMIT @page_size::int;
+
+-- name: ListCoOccurringEntities :many
+SELECT o.text, o.label, count(DISTINCT o.sentenceId) AS mentions
+FROM entities e
+JOIN entities o ON o.sentenceId = e.sentenceId AND lower(o.text) <> lower(e.text)
+WHERE lower(e.text) = lower(@text::text)
+  AND (sqlc.narg('label')::text IS NULL OR e.label = sqlc.narg('label')::text)
+  AND (sqlc.narg('pdfid')::int IS NULL OR e.pdfId = sqlc.narg('pdfid')::int)
+GROUP BY o.text, o.label
+ORDER BY mentions DESC, o.text, o.label
+LIMIT @page_size::int;
+
+-- name: CreateSummaryScores :exec
+INSERT INTO summary_scores (sentenceId, pdfId, score)
+SELECT unnest(@sentence_ids::int[]), @pdfid, unnest(@scores::float8[])
+ON CONFLICT (sentenceId) DO NOTHING;
+
+-- name: CountSummaryScores :one
+SELECT count(*) FROM summary_scores
+WHERE pdfId = $1;
+
+-- name: ListSummarySentences :many
+SELECT s.id, s.sentence, s.pdfid, s.page, t.score
+FROM (
+  SELECT sentenceId, score FROM summary_scores
+  WHERE pdfId = @pdfid
+  ORDER BY score DESC, sentenceId
+  LIMIT @size::int
+) AS t
+JOIN sentences s ON s.id = t.sentenceId
+ORDER BY s.id;
+
+-- name: SearchRecordSentences :many
+SELECT * FROM sentences
+WHERE pdfId = @pdfid AND sentence ~* @pattern::text
+ORDER BY id;
+
+-- name: CreateSentenceBands :exec
+WITH marked AS (
+  INSERT INTO duplicate_index (pdfId)
+  VALUES (@pdfid)
+  ON CONFLICT (pdfId) DO NOTHING
+)
+INSERT INTO sentence_bands (band, bucket, sentenceId, pdfId)
+SELECT unnest(@bands::int[]), unnest(@buckets::bigint[]), unnest(@sentence_ids::int[]), @pdfid
+ON CONFLICT DO NOTHING;
+
+-- name: ListDuplicatePending :many
+SELECT id FROM records
+WHERE NOT EXISTS (SELECT 1 FROM duplicate_index WHERE duplicate_index.pdfId = records.id)
+ORDER BY id;
+
+-- name: ListCandidatePairs :many
+SELECT DISTINCT a.sentenceId AS sentence_id, b.sentenceId AS other_sentence_id
+FROM sentence_bands a
+JOIN sentence_bands b ON b.band = a.band AND b.bucket = a.bucket AND b.pdfId <> a.pdfId
+WHERE a.pdfId = @pdfid;
+
+-- name: ListSentencesByID :many
+SELECT * FROM sentences
+WHERE id = ANY(@ids::int[])
+ORDER BY id;
+
+-- name: CreatePages :exec
+INSERT INTO pages (pdfId, page, ocr, confidence, width, height)
+SELECT @pdfid, unnest(@pages::int[]), unnest(@ocr::bool[]), unnest(@confidences::float8[]),
+  unnest(@widths::float8[]), unnest(@heights::float8[]);
+
+-- name: ListRecordPages :many
+SELECT * FROM pages
+WHERE pdfId = @pdfid
+ORDER BY page;
+
+-- name: CreateSection :one
+INSERT INTO sections (pdfId, parentId, level, title, page)
+VALUES (@pdfid, sqlc.narg('parentid'), @level, @title, @page)
+RETURNING *;
+
+-- name: CreateParagraph :one
+INSERT INTO paragraphs (pdfId, sectionId, page, x0, y0, x1, y1)
+VALUES (@pdfid, @sectionid, @page, @x0, @y0, @x1, @y1)
+RETURNING *;
+
+-- name: ListRecordSections :many
+SELECT * FROM sections
+WHERE pdfId = @pdfid
+ORDER BY id;
+
+-- name: ListRecordParagraphs :many
+SELECT * FROM paragraphs
+WHERE pdfId = @pdfid
+ORDER BY id;
+
+-- name: CreateTable :one
+INSERT INTO document_tables (pdfId, page, x0, y0, x1, y1, numOfRows, numOfColumns)
+VALUES (@pdfid, @page, @x0, @y0, @x1, @y1, @numofrows, @numofcolumns)
+RETURNING *;
+
+-- name: CreateTableCells :exec
+INSERT INTO table_cells (tableId, rowIndex, columnIndex, text)
+SELECT @tableid, unnest(@rows::int[]), unnest(@columns::int[]), unnest(@texts::text[]);
+
+-- name: ListRecordTables :many
+SELECT * FROM document_tables
+WHERE pdfId = @pdfid
+ORDER BY id;
+
+-- name: GetTable :one
+SELECT * FROM document_tables
+WHERE id = @id AND pdfId = @pdfid;
+
+-- name: ListTableCells :many
+SELECT * FROM table_cells
+WHERE tableId = @tableid
+ORDER BY rowIndex, columnIndex;
+
+-- name: CreateMetadata :exec
+INSERT INTO document_metadata (
+  pdfId, title, author, subject, keywords, creator, producer, created, modified, pdfVersion, encrypted
+) VALUES (
+  @pdfid, @title, @author, @subject, @keywords, @creator, @producer,
+  sqlc.narg('created'), sqlc.narg('modified'), @pdfversion, @encrypted
+);
+
+-- name: GetMetadata :one
+SELECT * FROM document_metadata
+WHERE pdfId = @pdfid;
+
+-- name: CreateOutlineItem :one
+INSERT INTO outline_items (pdfId, parentId, title, page)
+VALUES (@pdfid, sqlc.narg('parentid'), @title, @page)
+RETURNING *;
+
+-- name: ListRecordOutline :many
+SELECT * FROM outline_items
+WHERE pdfId = @pdfid
+ORDER BY id;
+
+-- name: CreateUpload :one
+INSERT INTO uploads (id, name, contentType, length, multipartId, checksum, expiresAt)
+VALUES (@id, @name, @contenttype, @length, @multipartid, @checksum, @expiresat)
+RETURNING *;
+
+-- name: GetUpload :one
+SELECT * FROM uploads
+WHERE id = @id;
+
+-- name: AddUploadPart :execrows
+WITH advanced AS (
+  UPDATE uploads
+    set received = received + @size::bigint, parts = parts + 1, hashState = @hashstate
+  WHERE id = @id AND received = @received::bigint AND status = 'receiving'
+  RETURNING id, parts
+)
+INSERT INTO upload_parts (uploadId, number, etag, size)
+SELECT id, parts, @etag, @size::bigint FROM advanced;
+
+-- name: ListUploadParts :many
+SELECT * FROM upload_parts
+WHERE uploadId = @uploadid
+ORDER BY number;
+
+-- name: UpdateUploadStatus :exec
+UPDATE uploads
+  set status = @status, pdfId = sqlc.narg('pdfid'), errorCode = @errorcode, errorMessage = @errormessage
+WHERE id = @id;
+
+-- name: FailIngestingUploads :execrows
+UPDATE uploads
+  set status = 'failed', errorCode = @errorcode, errorMessage = @errormessage
+WHERE status = 'ingesting';
+
+-- name: ListExpiredUploads :many
+SELECT * FROM uploads
+WHERE expiresAt < NOW()
+ORDER BY expiresAt;
+
+-- name: DeleteUpload :exec
+DELETE FROM uploads
+WHERE id = @id;
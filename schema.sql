@@ -1,14 +1,229 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE TABLE records (
     id SERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
     upload_time TIMESTAMP DEFAULT NOW() NOT NULL,
     numOfPages INT NOT NULL,
-    size INT NOT NULL
+    size BIGINT NOT NULL,
+    language VARCHAR(8) NOT NULL DEFAULT '',
+    mimeType VARCHAR(128) NOT NULL DEFAULT 'application/pdf',
+    -- the key of the stored file, the name is only shown
+    objectKey VARCHAR(255) NOT NULL,
+    extractor VARCHAR(64) NOT NULL DEFAULT '',
+    extractorVersion INT NOT NULL DEFAULT 0
 );
 
 create table sentences(
   id SERIAL PRIMARY KEY,
   sentence TEXT not null,
   pdfId INT not null,
+  page INT not null DEFAULT 0,
+  paragraphId INT,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE
+  );
+
+CREATE INDEX sentences_pdfid_id_idx ON sentences (pdfId, id);
+CREATE INDEX sentences_sentence_trgm_idx ON sentences USING GIN (sentence gin_trgm_ops);
+CREATE INDEX records_name_id_idx ON records (name, id);
+CREATE INDEX records_upload_time_id_idx ON records (upload_time, id);
+CREATE INDEX records_size_id_idx ON records (size, id);
+CREATE INDEX records_numofpages_id_idx ON records (numOfPages, id);
+CREATE INDEX records_objectkey_idx ON records (objectKey);
+CREATE INDEX records_extractorversion_idx ON records (extractorVersion);
+
+create table document_stats(
+  pdfId INT PRIMARY KEY,
+  term_count INT not null,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE
+  );
+
+create table document_terms(
+  pdfId INT not null,
+  term TEXT not null,
+  count INT not null,
+  surface TEXT not null DEFAULT '',
+  PRIMARY KEY (pdfId, term),
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE
+  );
+
+CREATE INDEX document_terms_term_idx ON document_terms (term);
+
+-- kept up to date by triggers on document_terms, see migration/schema/4.up.sql
+create table term_stats(
+  term TEXT PRIMARY KEY,
+  document_count INT not null
+  );
+
+create table entities(
+  id SERIAL PRIMARY KEY,
+  sentenceId INT not null,
+  pdfId INT not null,
+  text TEXT not null,
+  label VARCHAR(32) not null,
+  FOREIGN KEY (sentenceId) references sentences(id) ON DELETE CASCADE,
   FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE
-  );
\ No newline at end of file
+  );
+
+CREATE INDEX entities_pdfid_idx ON entities (pdfId);
+CREATE INDEX entities_sentenceid_idx ON entities (sentenceId);
+CREATE INDEX entities_text_idx ON entities (lower(text));
+
+create table summary_scores(
+  sentenceId INT PRIMARY KEY,
+  pdfId INT not null,
+  score FLOAT8 not null,
+  FOREIGN KEY (sentenceId) references sentences(id) ON DELETE CASCADE,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE
+  );
+
+CREATE INDEX summary_scores_pdfid_score_idx ON summary_scores (pdfId, score DESC, sentenceId);
+
+create table duplicate_index(
+  pdfId INT PRIMARY KEY,
+  indexed_at TIMESTAMP DEFAULT NOW() not null,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE
+  );
+
+create table sentence_bands(
+  band INT not null,
+  bucket BIGINT not null,
+  sentenceId INT not null,
+  pdfId INT not null,
+  PRIMARY KEY (band, bucket, sentenceId),
+  FOREIGN KEY (sentenceId) references sentences(id) ON DELETE CASCADE,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE
+  );
+
+CREATE INDEX sentence_bands_pdfid_idx ON sentence_bands (pdfId);
+
+-- ocr is set for the pages without a text layer whose text was recognized from their image
+create table pages(
+  pdfId INT not null,
+  page INT not null,
+  ocr BOOLEAN not null DEFAULT FALSE,
+  confidence FLOAT8 not null DEFAULT 0,
+  width FLOAT8 not null DEFAULT 0,
+  height FLOAT8 not null DEFAULT 0,
+  PRIMARY KEY (pdfId, page),
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE
+  );
+
+-- the structure of the documents read with their layout, level 0 is the text before the first heading
+create table sections(
+  id SERIAL PRIMARY KEY,
+  pdfId INT not null,
+  parentId INT,
+  level INT not null,
+  title TEXT not null,
+  page INT not null,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE,
+  FOREIGN KEY (parentId) references sections(id) ON DELETE CASCADE
+  );
+
+CREATE INDEX sections_pdfid_id_idx ON sections (pdfId, id);
+
+create table paragraphs(
+  id SERIAL PRIMARY KEY,
+  pdfId INT not null,
+  sectionId INT not null,
+  page INT not null,
+  x0 FLOAT8 not null,
+  y0 FLOAT8 not null,
+  x1 FLOAT8 not null,
+  y1 FLOAT8 not null,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE,
+  FOREIGN KEY (sectionId) references sections(id) ON DELETE CASCADE
+  );
+
+CREATE INDEX paragraphs_pdfid_id_idx ON paragraphs (pdfId, id);
+
+-- paragraphs is created after sentences, the sentences of a deleted paragraph keep their text
+ALTER TABLE sentences ADD FOREIGN KEY (paragraphId) references paragraphs(id) ON DELETE SET NULL;
+
+-- the tables found in the documents read with their layout, cells are indexed from 0
+create table document_tables(
+  id SERIAL PRIMARY KEY,
+  pdfId INT not null,
+  page INT not null,
+  x0 FLOAT8 not null,
+  y0 FLOAT8 not null,
+  x1 FLOAT8 not null,
+  y1 FLOAT8 not null,
+  numOfRows INT not null,
+  numOfColumns INT not null,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE
+  );
+
+CREATE INDEX document_tables_pdfid_id_idx ON document_tables (pdfId, id);
+
+create table table_cells(
+  tableId INT not null,
+  rowIndex INT not null,
+  columnIndex INT not null,
+  text TEXT not null,
+  PRIMARY KEY (tableId, rowIndex, columnIndex),
+  FOREIGN KEY (tableId) references document_tables(id) ON DELETE CASCADE
+  );
+
+-- the info dictionary of the documents completed by their XMP metadata
+create table document_metadata(
+  pdfId INT PRIMARY KEY,
+  title TEXT not null DEFAULT '',
+  author TEXT not null DEFAULT '',
+  subject TEXT not null DEFAULT '',
+  keywords TEXT not null DEFAULT '',
+  creator TEXT not null DEFAULT '',
+  producer TEXT not null DEFAULT '',
+  created TIMESTAMP,
+  modified TIMESTAMP,
+  pdfVersion VARCHAR(8) not null DEFAULT '',
+  encrypted BOOLEAN not null DEFAULT FALSE,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE
+  );
+
+-- the bookmarks of the documents, pages start at 1
+create table outline_items(
+  id SERIAL PRIMARY KEY,
+  pdfId INT not null,
+  parentId INT,
+  title TEXT not null,
+  page INT not null,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE CASCADE,
+  FOREIGN KEY (parentId) references outline_items(id) ON DELETE CASCADE
+  );
+
+CREATE INDEX outline_items_pdfid_id_idx ON outline_items (pdfId, id);
+
+-- the resumable uploads, stored as the parts of a multipart upload until their last byte is
+-- received, then ingested
+create table uploads(
+  id VARCHAR(36) PRIMARY KEY,
+  name VARCHAR(255) not null,
+  contentType VARCHAR(255) not null DEFAULT '',
+  length BIGINT not null,
+  received BIGINT not null DEFAULT 0,
+  parts INT not null DEFAULT 0,
+  multipartId TEXT not null,
+  checksum VARCHAR(255) not null DEFAULT '',
+  status VARCHAR(16) not null DEFAULT 'receiving',
+  pdfId INT,
+  errorCode VARCHAR(64) not null DEFAULT '',
+  errorMessage TEXT not null DEFAULT '',
+  createdAt TIMESTAMP not null DEFAULT NOW(),
+  expiresAt TIMESTAMP not null,
+  -- the hash of the chunks received so far, when the file has a checksum
+  hashState BYTEA,
+  FOREIGN KEY (pdfId) references records(id) ON DELETE SET NULL
+  );
+
+CREATE INDEX uploads_expiresat_idx ON uploads (expiresAt);
+
+create table upload_parts(
+  uploadId VARCHAR(36) not null,
+  number INT not null,
+  etag VARCHAR(255) not null,
+  size BIGINT not null,
+  PRIMARY KEY (uploadId, number),
+  FOREIGN KEY (uploadId) references uploads(id) ON DELETE CASCADE
+  );
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// BlobStore is the object storage used to keep the uploaded files
+type BlobStore interface {
+	Put(ctx context.Context, bucket, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	Remove(ctx context.Context, bucket, key string) error
+
+	// A multipart upload stores an object sent in parts, numbered from 1. The object exists
+	// once the upload is completed with the parts in order.
+	NewMultipart(ctx context.Context, bucket, key, contentType string) (string, error)
+	PutPart(ctx context.Context, bucket, key, uploadID string, number int, r io.Reader, size int64) (string, error)
+	CompleteMultipart(ctx context.Context, bucket, key, uploadID string, parts []Part) error
+	AbortMultipart(ctx context.Context, bucket, key, uploadID string) error
+}
+
+// Part is a stored part of a multipart upload with the ETag the storage returned for it
+type Part struct {
+	Number int
+	ETag   string
+}
+
+// MinPartSize is the size under which a part of a multipart upload can only be its last one
+const MinPartSize = 5 << 20
+
+type minioStore struct {
+	client *minio.Client
+	core   minio.Core
+}
+
+// NewMinio creates a BlobStore backed by a MinIO server
+func NewMinio(endpoint, accessKey, secretKey string, useSSL bool) (BlobStore, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &minioStore{client: client, core: minio.Core{Client: client}}, nil
+}
+
+func (m *minioStore) Put(ctx context.Context, bucket, key string, r io.Reader, size int64, contentType string) error {
+	_, err := m.client.PutObject(ctx, bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (m *minioStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	return m.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+}
+
+func (m *minioStore) Remove(ctx context.Context, bucket, key string) error {
+	return m.client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (m *minioStore) NewMultipart(ctx context.Context, bucket, key, contentType string) (string, error) {
+	return m.core.NewMultipartUpload(ctx, bucket, key, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+}
+
+func (m *minioStore) PutPart(ctx context.Context, bucket, key, uploadID string, number int, r io.Reader, size int64) (string, error) {
+	part, err := m.core.PutObjectPart(ctx, bucket, key, uploadID, number, r, size, minio.PutObjectPartOptions{})
+	return part.ETag, err
+}
+
+func (m *minioStore) CompleteMultipart(ctx context.Context, bucket, key, uploadID string, parts []Part) error {
+	completed := make([]minio.CompletePart, len(parts))
+	for i, part := range parts {
+		completed[i] = minio.CompletePart{PartNumber: part.Number, ETag: part.ETag}
+	}
+	_, err := m.core.CompleteMultipartUpload(ctx, bucket, key, uploadID, completed, minio.PutObjectOptions{})
+	return err
+}
+
+func (m *minioStore) AbortMultipart(ctx context.Context, bucket, key, uploadID string) error {
+	return m.core.AbortMultipartUpload(ctx, bucket, key, uploadID)
+}
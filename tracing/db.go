@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"main/database"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracedDB struct {
+	next database.DBTX
+}
+
+// InstrumentDB opens a span for every query sent through the DBTX
+func InstrumentDB(next database.DBTX) database.DBTX {
+	return &tracedDB{next: next}
+}
+
+// queryName extracts the sqlc query name from the "-- name: X :kind" header
+func queryName(sql string) string {
+	header := strings.TrimSpace(sql)
+	if !strings.HasPrefix(header, "-- name:") {
+		return "query"
+	}
+	fields := strings.Fields(strings.TrimPrefix(header, "-- name:"))
+	if len(fields) == 0 {
+		return "query"
+	}
+	return fields[0]
+}
+
+func (t *tracedDB) start(ctx context.Context, sql string) (context.Context, trace.Span) {
+	name := queryName(sql)
+	return tracer.Start(ctx, "db "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperation(name), semconv.DBStatement(sql)),
+	)
+}
+
+func (t *tracedDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, span := t.start(ctx, sql)
+	tag, err := t.next.Exec(ctx, sql, args...)
+	End(span, err)
+	return tag, err
+}
+
+func (t *tracedDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx, span := t.start(ctx, sql)
+	rows, err := t.next.Query(ctx, sql, args...)
+	if err != nil {
+		End(span, err)
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (t *tracedDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	ctx, span := t.start(ctx, sql)
+	return &tracedRow{row: t.next.QueryRow(ctx, sql, args...), span: span}
+}
+
+// Beginner is a connection that starts transactions, such as a pool or the DBTX of InstrumentDB
+type Beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Begin starts a transaction on the connection, its queries traced as those of the connection
+func (t *tracedDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	conn, ok := t.next.(Beginner)
+	if !ok {
+		return nil, errors.New("the connection does not start transactions")
+	}
+	ctx, span := tracer.Start(ctx, "db begin", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBSystemPostgreSQL))
+	tx, err := conn.Begin(ctx)
+	End(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{Tx: tx, traced: &tracedDB{next: tx}}, nil
+}
+
+// tracedTx opens a span for every query sent through the transaction
+type tracedTx struct {
+	pgx.Tx
+	traced *tracedDB
+}
+
+func (t *tracedTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return t.traced.Exec(ctx, sql, args...)
+}
+
+func (t *tracedTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return t.traced.Query(ctx, sql, args...)
+}
+
+func (t *tracedTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return t.traced.QueryRow(ctx, sql, args...)
+}
+
+// tracedRows ends the query span once the rows are released
+type tracedRows struct {
+	pgx.Rows
+	span trace.Span
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	err := r.Rows.Err()
+	End(r.span, err)
+}
+
+// tracedRow ends the query span once the row is scanned
+type tracedRow struct {
+	row  pgx.Row
+	span trace.Span
+}
+
+func (r *tracedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	if err == pgx.ErrNoRows {
+		End(r.span, nil)
+	} else {
+		End(r.span, err)
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"main/storage"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracedStore struct {
+	next storage.BlobStore
+}
+
+// InstrumentStore opens a span for every BlobStore call
+func InstrumentStore(next storage.BlobStore) storage.BlobStore {
+	return &tracedStore{next: next}
+}
+
+func (t *tracedStore) start(ctx context.Context, op, bucket, key string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "storage "+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("storage.bucket", bucket), attribute.String("storage.key", key)),
+	)
+}
+
+func (t *tracedStore) Put(ctx context.Context, bucket, key string, r io.Reader, size int64, contentType string) error {
+	ctx, span := t.start(ctx, "put", bucket, key)
+	err := t.next.Put(ctx, bucket, key, r, size, contentType)
+	End(span, err)
+	return err
+}
+
+func (t *tracedStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	ctx, span := t.start(ctx, "get", bucket, key)
+	obj, err := t.next.Get(ctx, bucket, key)
+	End(span, err)
+	return obj, err
+}
+
+func (t *tracedStore) Remove(ctx context.Context, bucket, key string) error {
+	ctx, span := t.start(ctx, "remove", bucket, key)
+	err := t.next.Remove(ctx, bucket, key)
+	End(span, err)
+	return err
+}
+
+func (t *tracedStore) NewMultipart(ctx context.Context, bucket, key, contentType string) (string, error) {
+	ctx, span := t.start(ctx, "new_multipart", bucket, key)
+	uploadID, err := t.next.NewMultipart(ctx, bucket, key, contentType)
+	End(span, err)
+	return uploadID, err
+}
+
+func (t *tracedStore) PutPart(ctx context.Context, bucket, key, uploadID string, number int, r io.Reader, size int64) (string, error) {
+	ctx, span := t.start(ctx, "put_part", bucket, key)
+	span.SetAttributes(attribute.Int("storage.part", number), attribute.Int64("storage.size", size))
+	etag, err := t.next.PutPart(ctx, bucket, key, uploadID, number, r, size)
+	End(span, err)
+	return etag, err
+}
+
+func (t *tracedStore) CompleteMultipart(ctx context.Context, bucket, key, uploadID string, parts []storage.Part) error {
+	ctx, span := t.start(ctx, "complete_multipart", bucket, key)
+	span.SetAttributes(attribute.Int("storage.parts", len(parts)))
+	err := t.next.CompleteMultipart(ctx, bucket, key, uploadID, parts)
+	End(span, err)
+	return err
+}
+
+func (t *tracedStore) AbortMultipart(ctx context.Context, bucket, key, uploadID string) error {
+	ctx, span := t.start(ctx, "abort_multipart", bucket, key)
+	err := t.next.AbortMultipart(ctx, bucket, key, uploadID)
+	End(span, err)
+	return err
+}
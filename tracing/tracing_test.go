@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"-- name: GetRecord :one\nSELECT * FROM records WHERE id = $1", "GetRecord"},
+		{"\n  -- name: ListRecords :many\nSELECT 1", "ListRecords"},
+		{"-- name:", "query"},
+		{"SELECT 1", "query"},
+	}
+	for _, tt := range tests {
+		if got := queryName(tt.sql); got != tt.want {
+			t.Errorf("queryName(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestMiddlewareSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	app := fiber.New()
+	app.Use(Middleware())
+	app.Get("/documents/:id", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Get("/fail", func(c *fiber.Ctx) error { return fiber.NewError(fiber.StatusServiceUnavailable) })
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	tests := []struct {
+		path        string
+		traceparent string
+		name        string
+		status      int
+		code        codes.Code
+	}{
+		{"/documents/1", "", "GET /documents/:id", fiber.StatusOK, codes.Unset},
+		{"/documents/2", "00-" + traceID + "-00f067aa0ba902b7-01", "GET /documents/:id", fiber.StatusOK, codes.Unset},
+		{"/fail", "", "GET /fail", fiber.StatusServiceUnavailable, codes.Error},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.traceparent != "" {
+			req.Header.Set("traceparent", tt.traceparent)
+		}
+		if _, err := app.Test(req); err != nil {
+			t.Fatal(err)
+		}
+
+		spans := recorder.Ended()
+		span := spans[len(spans)-1]
+		if span.Name() != tt.name {
+			t.Errorf("%s: got span %q, want %q", tt.path, span.Name(), tt.name)
+		}
+		if span.Status().Code != tt.code {
+			t.Errorf("%s: got status %v, want %v", tt.path, span.Status().Code, tt.code)
+		}
+		want := semconv.HTTPStatusCode(tt.status)
+		if !hasAttribute(span.Attributes(), want) {
+			t.Errorf("%s: got attributes %v, want %v", tt.path, span.Attributes(), want)
+		}
+		// a request carrying a traceparent continues the trace of the caller
+		continued := span.SpanContext().TraceID().String() == traceID
+		if continued != (tt.traceparent != "") {
+			t.Errorf("%s: got trace %s with traceparent %q", tt.path, span.SpanContext().TraceID(), tt.traceparent)
+		}
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if attr == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"main/extract"
+	"os"
+	"path"
+	"strings"
+)
+
+// Formats of the archives a batch of files may be sent in, a gzip archive being a compressed tar
+const (
+	MIMEZip  = "application/zip"
+	MIMETar  = "application/x-tar"
+	MIMEGzip = "application/gzip"
+)
+
+// Reasons a batch is rejected as a whole, matched with errors.Is
+var (
+	ErrBatchTooManyFiles = errors.New("too many files in the batch")
+	ErrBatchTooLarge     = errors.New("batch too large")
+)
+
+// BatchBudget is what is left of the files and bytes a batch may hold
+type BatchBudget struct {
+	files int
+	size  int64
+}
+
+// NewBatchBudget returns the budget of a batch under the upload policy
+func NewBatchBudget() *BatchBudget {
+	return &BatchBudget{files: uploadPolicy.MaxBatchFiles, size: uploadPolicy.MaxBatchSize}
+}
+
+// Take counts a file of a batch, an error matching ErrBatchTooManyFiles or ErrBatchTooLarge
+// once the batch holds more than the policy allows
+func (b *BatchBudget) Take(size int64) error {
+	b.files--
+	b.size -= size
+	if b.files < 0 {
+		return fmt.Errorf("%w: at most %d are accepted", ErrBatchTooManyFiles, uploadPolicy.MaxBatchFiles)
+	}
+	if b.size < 0 {
+		return fmt.Errorf("%w: at most %d bytes are accepted", ErrBatchTooLarge, uploadPolicy.MaxBatchSize)
+	}
+	return nil
+}
+
+// tarMagic is the magic of the POSIX and GNU tar headers, at tarMagicOffset of the first header
+const (
+	tarMagic       = "ustar"
+	tarMagicOffset = 257
+)
+
+// ArchiveFormat returns the format of the archives of files, empty for the other files. The zip
+// based formats that are read, such as DOCX and EPUB, and the gzip files not holding a tar
+// archive are documents rather than archives.
+func ArchiveFormat(path string) (string, error) {
+	mimeType, err := extract.Sniff(path)
+	if err != nil {
+		return "", err
+	}
+	if mimeType == MIMEZip {
+		return MIMEZip, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	format := MIMETar
+	if mimeType == "application/x-gzip" {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return "", nil
+		}
+		defer gz.Close()
+		r, format = gz, MIMEGzip
+	}
+	head := make([]byte, tarMagicOffset+len(tarMagic))
+	if _, err := io.ReadFull(r, head); err != nil {
+		return "", nil
+	}
+	if !bytes.Equal(head[tarMagicOffset:], []byte(tarMagic)) {
+		return "", nil
+	}
+	return format, nil
+}
+
+// ArchiveEntry is a file of an archive, written to a local file the caller must remove
+type ArchiveEntry struct {
+	// Name is the path of the file in the archive
+	Name string
+	Path string
+	Size int64
+	// Err is why the file was not written, such as ErrUploadTooLarge
+	Err error
+}
+
+// ExpandArchive writes the files of an archive to local files, counting them against the budget
+// of the batch. Directories, links and the hidden files, such as the metadata macOS adds, are
+// skipped. The files are removed and an error returned when the archive cannot be read or the
+// batch holds too many files.
+func ExpandArchive(ctx context.Context, path string, format string, budget *BatchBudget) ([]ArchiveEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []ArchiveEntry
+	add := func(name string, size int64, r func() (io.ReadCloser, error)) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := budget.Take(size); err != nil {
+			return err
+		}
+		entry := ArchiveEntry{Name: name, Size: size}
+		if entry.Err = CheckUploadSize(size); entry.Err == nil {
+			entry.Path, entry.Err = writeEntry(r)
+		}
+		entries = append(entries, entry)
+		return nil
+	}
+
+	switch format {
+	case MIMEZip:
+		err = expandZip(file, add)
+	case MIMETar:
+		err = expandTar(file, add)
+	case MIMEGzip:
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(file)
+		if err == nil {
+			err = expandTar(gz, add)
+			gz.Close()
+		}
+	default:
+		err = fmt.Errorf("%w: %s", extract.ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		RemoveEntries(entries)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RemoveEntries removes the local files of the entries of an archive
+func RemoveEntries(entries []ArchiveEntry) {
+	for _, entry := range entries {
+		if entry.Path != "" {
+			os.Remove(entry.Path)
+		}
+	}
+}
+
+type addEntry func(name string, size int64, r func() (io.ReadCloser, error)) error
+
+func expandZip(file *os.File, add addEntry) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return err
+	}
+	for _, entry := range archive.File {
+		if !entry.Mode().IsRegular() || hiddenEntry(entry.Name) {
+			continue
+		}
+		// the reader fails when the content is larger than the size in the header
+		if err := add(entry.Name, int64(entry.UncompressedSize64), entry.Open); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func expandTar(r io.Reader, add addEntry) error {
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !header.FileInfo().Mode().IsRegular() || hiddenEntry(header.Name) {
+			continue
+		}
+		open := func() (io.ReadCloser, error) { return io.NopCloser(archive), nil }
+		if err := add(header.Name, header.Size, open); err != nil {
+			return err
+		}
+	}
+}
+
+// hiddenEntry reports whether an entry is a hidden file or in a hidden directory, such as __MACOSX
+func hiddenEntry(name string) bool {
+	for _, part := range strings.Split(path.Clean(strings.ReplaceAll(name, "\\", "/")), "/") {
+		if (strings.HasPrefix(part, ".") && part != "." && part != "..") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeEntry copies an entry of an archive to a local file
+func writeEntry(open func() (io.ReadCloser, error)) (string, error) {
+	r, err := open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	local, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(local, r)
+	if closeErr := local.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(local.Name())
+		return "", err
+	}
+	return local.Name(), nil
+}
@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// archiveFile is an entry of a test archive, a directory when its name ends with a slash.
+// declared, when set, is the size written in the header of a zip entry instead of the real one.
+type archiveFile struct {
+	name     string
+	content  string
+	declared uint64
+}
+
+func writeZip(t *testing.T, dir string, files []archiveFile) string {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for _, file := range files {
+		if file.declared == 0 {
+			f, err := w.Create(file.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Write([]byte(file.content))
+			continue
+		}
+		// a stored entry whose header understates its size, as a zip bomb would
+		header := &zip.FileHeader{
+			Name:               file.name,
+			Method:             zip.Store,
+			CRC32:              crc32.ChecksumIEEE([]byte(file.content)),
+			CompressedSize64:   uint64(len(file.content)),
+			UncompressedSize64: file.declared,
+		}
+		f, err := w.CreateRaw(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(file.content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return writeArchive(t, dir, "batch.zip", b.Bytes())
+}
+
+func writeTarGz(t *testing.T, dir string, files []archiveFile) string {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	w := tar.NewWriter(gz)
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0o644, Size: int64(len(file.content)), Typeflag: tar.TypeReg}
+		if file.name[len(file.name)-1] == '/' {
+			header.Typeflag, header.Mode = tar.TypeDir, 0o755
+		}
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(file.content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+	return writeArchive(t, dir, "batch.tar.gz", b.Bytes())
+}
+
+func writeArchive(t *testing.T, dir, name string, content []byte) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// setUploadPolicy replaces the upload policy for the duration of a test
+func setUploadPolicy(t *testing.T, policy UploadPolicy) {
+	previous := uploadPolicy
+	if err := SetUploadPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { uploadPolicy = previous })
+}
+
+// localFiles lists the files written to the temporary directory by the expansion
+func localFiles(t *testing.T, dir string) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, "upload-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+// TestExpandArchive writes the regular files of zip and gzipped tar archives, skipping the
+// directories and the hidden files
+func TestExpandArchive(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	setUploadPolicy(t, DefaultUploadPolicy)
+
+	files := []archiveFile{
+		{name: "reports/"},
+		{name: "reports/q1.txt", content: "First quarter"},
+		{name: "__MACOSX/reports/._q1.txt", content: "resource fork"},
+		{name: ".DS_Store", content: "finder"},
+		{name: "reports/.hidden/notes.txt", content: "hidden"},
+		{name: "summary.md", content: "# Summary"},
+	}
+	archives := map[string]string{
+		MIMEZip:  writeZip(t, t.TempDir(), files),
+		MIMEGzip: writeTarGz(t, t.TempDir(), files),
+	}
+	for format, path := range archives {
+		entries, err := ExpandArchive(context.Background(), path, format, NewBatchBudget())
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		got := map[string]string{}
+		for _, entry := range entries {
+			if entry.Err != nil {
+				t.Errorf("%s: %s: %v", format, entry.Name, entry.Err)
+				continue
+			}
+			content, err := os.ReadFile(entry.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if entry.Size != int64(len(content)) {
+				t.Errorf("%s: %s: got size %d for %d bytes", format, entry.Name, entry.Size, len(content))
+			}
+			got[entry.Name] = string(content)
+		}
+		want := map[string]string{"reports/q1.txt": "First quarter", "summary.md": "# Summary"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", format, got, want)
+		}
+
+		RemoveEntries(entries)
+		if left := localFiles(t, tmp); len(left) != 0 {
+			t.Errorf("%s: files left after RemoveEntries: %v", format, left)
+		}
+	}
+}
+
+// TestExpandArchiveUnderstatedSize fails the zip entries larger than their header tells, without
+// failing the others
+func TestExpandArchiveUnderstatedSize(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	setUploadPolicy(t, DefaultUploadPolicy)
+
+	path := writeZip(t, t.TempDir(), []archiveFile{
+		{name: "bomb.txt", content: "far more content than the header tells", declared: 4},
+		{name: "fine.txt", content: "fine"},
+	})
+	entries, err := ExpandArchive(context.Background(), path, MIMEZip, NewBatchBudget())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveEntries(entries)
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Err == nil || entries[0].Path != "" {
+		t.Errorf("understated entry: got path %q and error %v, want no file and an error", entries[0].Path, entries[0].Err)
+	}
+	if entries[1].Err != nil || entries[1].Path == "" {
+		t.Errorf("regular entry: got path %q and error %v", entries[1].Path, entries[1].Err)
+	}
+	if left := localFiles(t, tmp); len(left) != 1 {
+		t.Errorf("got local files %v, want the regular entry only", left)
+	}
+}
+
+// TestExpandArchiveBudget rejects the archives holding more files or bytes than the batch may,
+// removing the files already written, and fails the entries larger than a file may be
+func TestExpandArchiveBudget(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	files := []archiveFile{
+		{name: "a.txt", content: "first file"},
+		{name: "b.txt", content: "second file"},
+		{name: "c.txt", content: "third file"},
+	}
+	path := writeZip(t, t.TempDir(), files)
+
+	tests := []struct {
+		name   string
+		policy UploadPolicy
+		want   error
+	}{
+		{"too many files", UploadPolicy{MaxBatchFiles: 2}, ErrBatchTooManyFiles},
+		{"too many bytes", UploadPolicy{MaxBatchSize: 25}, ErrBatchTooLarge},
+	}
+	for _, tt := range tests {
+		tt.policy.Encrypted, tt.policy.JavaScript = PolicyAllow, PolicyAllow
+		setUploadPolicy(t, tt.policy)
+		entries, err := ExpandArchive(context.Background(), path, MIMEZip, NewBatchBudget())
+		if !errors.Is(err, tt.want) || entries != nil {
+			t.Errorf("%s: got %v and %d entries, want %v", tt.name, err, len(entries), tt.want)
+		}
+		if left := localFiles(t, tmp); len(left) != 0 {
+			t.Errorf("%s: files left: %v", tt.name, left)
+		}
+	}
+
+	setUploadPolicy(t, UploadPolicy{MaxSize: 10, Encrypted: PolicyAllow, JavaScript: PolicyAllow})
+	entries, err := ExpandArchive(context.Background(), path, MIMEZip, NewBatchBudget())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer RemoveEntries(entries)
+	for i, want := range []error{nil, ErrUploadTooLarge, nil} {
+		if !errors.Is(entries[i].Err, want) || (want != nil) != (entries[i].Path == "") {
+			t.Errorf("%s: got path %q and error %v, want error %v", entries[i].Name, entries[i].Path, entries[i].Err, want)
+		}
+	}
+}
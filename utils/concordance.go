@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"main/analytics"
+	"main/database"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ConcordanceLine is an occurrence of a term with its context and the sentence it was found in
+type ConcordanceLine struct {
+	analytics.Line
+	SentenceID int32
+	Page       int32
+}
+
+// concordanceCursor is the occurrence ending the previous page of a concordance
+type concordanceCursor struct {
+	Sort       string `json:"s"`
+	SentenceID int32  `json:"i"`
+	Start      int    `json:"o"`
+}
+
+// Concordance returns one page of the occurrences of query, as a phrase, in the sentences of a
+// record with window words of context on each side, sorted by position or by left or right
+// context. It also returns the number of occurrences and the cursor of the next page, empty on
+// the last page.
+func Concordance(ctx context.Context, id int32, query string, window int, order string, cursor string, limit int) ([]ConcordanceLine, int, string, error) {
+	words := analytics.Tokenize(query)
+	if len(words) == 0 {
+		return []ConcordanceLine{}, 0, "", nil
+	}
+
+	sentences, err := getQueries(ctx).SearchRecordSentences(ctx, database.SearchRecordSentencesParams{
+		Pdfid:   id,
+		Pattern: searchPattern(words[:1]),
+	})
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	lines := []ConcordanceLine{}
+	for _, sentence := range sentences {
+		for _, line := range analytics.Concordance(sentence.Sentence, words, window) {
+			lines = append(lines, ConcordanceLine{Line: line, SentenceID: sentence.ID, Page: sentence.Page})
+		}
+	}
+
+	if order == analytics.SortLeft || order == analytics.SortRight {
+		sort.SliceStable(lines, func(i, j int) bool { return analytics.LessContext(lines[i].Line, lines[j].Line, order) })
+	}
+
+	page, next, err := pageLines(lines, order, cursor, limit)
+	return page, len(lines), next, err
+}
+
+// pageLines returns the lines following the occurrence of the cursor, the sort being stable
+func pageLines(lines []ConcordanceLine, order string, cursor string, limit int) ([]ConcordanceLine, string, error) {
+	size := pageSize(limit)
+
+	from := 0
+	if cursor != "" {
+		var after concordanceCursor
+		if err := decodeCursor(cursor, &after); err != nil {
+			return nil, "", err
+		}
+		if after.Sort != order {
+			return nil, "", ErrInvalidCursor
+		}
+		from = -1
+		for i, line := range lines {
+			if line.SentenceID == after.SentenceID && line.Start == after.Start {
+				from = i + 1
+				break
+			}
+		}
+		// the occurrence is gone with a new extraction of the document
+		if from < 0 {
+			return nil, "", ErrInvalidCursor
+		}
+	}
+	lines = lines[from:]
+
+	next := ""
+	if len(lines) > size {
+		lines = lines[:size]
+		last := lines[size-1]
+		next = encodeCursor(concordanceCursor{Sort: order, SentenceID: last.SentenceID, Start: last.Start})
+	}
+	return lines, next, nil
+}
+
+// FormatConcordance aligns the lines on their match, one per line, as concordancers print them
+func FormatConcordance(lines []ConcordanceLine) string {
+	width := 0
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line.Left); n > width {
+			width = n
+		}
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		location := fmt.Sprintf("p%d s%d", line.Page, line.SentenceID)
+		padding := strings.Repeat(" ", width-utf8.RuneCountInString(line.Left))
+		fmt.Fprintf(&b, "%-16s %s%s  %s  %s\n", location, padding, line.Left, line.Match, line.Right)
+	}
+	return b.String()
+}
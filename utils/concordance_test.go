@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"errors"
+	"main/analytics"
+	"reflect"
+	"testing"
+)
+
+// TestPageLines walks the lines page by page and rejects the cursors of another order or of a
+// line that is gone
+func TestPageLines(t *testing.T) {
+	lines := []ConcordanceLine{
+		{SentenceID: 1, Line: analytics.Line{Start: 0}},
+		{SentenceID: 1, Line: analytics.Line{Start: 12}},
+		{SentenceID: 4, Line: analytics.Line{Start: 3}},
+		{SentenceID: 2, Line: analytics.Line{Start: 7}},
+		{SentenceID: 9, Line: analytics.Line{Start: 1}},
+	}
+
+	var got [][2]int
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > len(lines) {
+			t.Fatal("the pages do not end")
+		}
+		page, next, err := pageLines(lines, analytics.SortLeft, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range page {
+			got = append(got, [2]int{int(line.SentenceID), line.Start})
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	want := [][2]int{{1, 0}, {1, 12}, {4, 3}, {2, 7}, {9, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	_, next, err := pageLines(lines, analytics.SortLeft, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pageLines(lines, analytics.SortRight, next, 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor of another order: got %v, want ErrInvalidCursor", err)
+	}
+	if _, _, err := pageLines(lines[2:], analytics.SortLeft, next, 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor of a removed line: got %v, want ErrInvalidCursor", err)
+	}
+	if _, _, err := pageLines(lines, analytics.SortLeft, "not a cursor", 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("malformed cursor: got %v, want ErrInvalidCursor", err)
+	}
+}
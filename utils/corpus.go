@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"main/analytics"
+	"main/database"
+	"main/logging"
+	"sort"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// ErrNotIndexed is returned for records whose terms have not been indexed yet
+var ErrNotIndexed = errors.New("record not indexed")
+
+// RecordLanguage is the detected language of a record, DefaultLanguage for the records
+// stored before the languages were detected and not indexed since
+func RecordLanguage(record database.Record) string {
+	return orDefaultLanguage(record.Language)
+}
+
+func orDefaultLanguage(language string) string {
+	if language == "" {
+		return analytics.DefaultLanguage
+	}
+	return language
+}
+
+// ResolveLanguage returns language, or the language of the record when it is empty
+func ResolveLanguage(ctx context.Context, id int32, language string) (string, error) {
+	if language != "" {
+		return language, nil
+	}
+	record, err := getQueries(ctx).GetRecord(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return RecordLanguage(record), nil
+}
+
+// IndexRecord adds the terms of a record, stemmed for its language, to the corpus statistics.
+// The document frequencies are updated by the database in the same statement.
+func IndexRecord(ctx context.Context, id int32, language string, texts []string) error {
+	counts, surfaces, total := analytics.CountWords(texts, language)
+
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	termCounts := make([]int32, len(terms))
+	termSurfaces := make([]string, len(terms))
+	for i, term := range terms {
+		termCounts[i] = int32(counts[term])
+		termSurfaces[i] = surfaces[term]
+	}
+
+	return getQueries(ctx).IndexDocument(ctx, database.IndexDocumentParams{
+		Pdfid:     id,
+		TermCount: int32(total),
+		Terms:     terms,
+		Counts:    termCounts,
+		Surfaces:  termSurfaces,
+	})
+}
+
+// IndexPending indexes the records stored before the corpus statistics existed, or before the
+// analyzer changed, or whose indexing failed during the upload. Records stored before the
+// languages were detected get theirs first.
+func IndexPending(ctx context.Context) error {
+	records, err := getQueries(ctx).ListUnindexedRecords(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		sentences, err := ListRecordSentences(ctx, record.ID)
+		if err != nil {
+			return err
+		}
+
+		texts := make([]string, 0, len(sentences))
+		for _, sentence := range sentences {
+			texts = append(texts, sentence.Sentence)
+		}
+
+		if record.Language == "" {
+			record.Language = analytics.DetectLanguage(texts)
+			err := getQueries(ctx).UpdateRecordLanguage(ctx, database.UpdateRecordLanguageParams{ID: record.ID, Language: record.Language})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := IndexRecord(ctx, record.ID, record.Language, texts); err != nil {
+			return err
+		}
+		logging.FromContext(ctx).WithField("record_id", record.ID).Info("record indexed")
+	}
+
+	return nil
+}
+
+// CorpusStats returns the number of indexed records and their average length, and that of their sentences, in words
+func CorpusStats(ctx context.Context) (analytics.CorpusStats, error) {
+	stats, err := getQueries(ctx).GetCorpusStats(ctx)
+	if err != nil {
+		return analytics.CorpusStats{}, err
+	}
+	corpus := analytics.CorpusStats{Documents: int(stats.Documents), AverageLength: stats.AverageLength}
+	if stats.Sentences > 0 {
+		corpus.AverageSentenceLength = float64(stats.Terms) / float64(stats.Sentences)
+	}
+	return corpus, nil
+}
+
+// DocumentKeywords returns the most distinctive terms of a record against the whole corpus
+func DocumentKeywords(ctx context.Context, id int32, options analytics.KeywordOptions) ([]analytics.Keyword, analytics.CorpusStats, error) {
+	queries := getQueries(ctx)
+	document, err := queries.GetDocumentStats(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, analytics.CorpusStats{}, ErrNotIndexed
+		}
+		return nil, analytics.CorpusStats{}, err
+	}
+
+	corpus, err := CorpusStats(ctx)
+	if err != nil {
+		return nil, analytics.CorpusStats{}, err
+	}
+
+	rows, err := queries.ListDocumentTermStats(ctx, id)
+	if err != nil {
+		return nil, analytics.CorpusStats{}, err
+	}
+
+	terms := make([]analytics.TermStats, 0, len(rows))
+	for _, row := range rows {
+		terms = append(terms, analytics.TermStats{
+			Term:          row.Term,
+			Surface:       row.Surface,
+			Count:         int(row.Count),
+			DocumentCount: int(row.DocumentCount),
+		})
+	}
+
+	return analytics.Keywords(terms, int(document.TermCount), corpus, options), corpus, nil
+}
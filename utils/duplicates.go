@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"context"
+	"main/analytics"
+	"main/database"
+	"main/logging"
+	"sort"
+	"time"
+)
+
+// duplicateQueue holds the records waiting for the duplicate indexer
+var duplicateQueue = make(chan int32, 256)
+
+// DuplicatePair is a sentence of a record and a sentence of another record sharing most of their words
+type DuplicatePair struct {
+	Sentence   database.Sentence
+	Other      database.Sentence
+	Similarity float64
+}
+
+// DuplicateDocument is a record sharing passages with the one looked up. Overlap is the
+// percentage of the comparable sentences of the looked up record found in this one.
+type DuplicateDocument struct {
+	Record  database.Record
+	Overlap float64
+	Pairs   []DuplicatePair
+}
+
+// IndexDuplicates adds the MinHash bands of the sentences of a record to the LSH index
+func IndexDuplicates(ctx context.Context, id int32) error {
+	queries := getQueries(ctx)
+	sentences, err := queries.ListRecordSentences(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	arg := database.CreateSentenceBandsParams{Pdfid: id}
+	for _, sentence := range sentences {
+		shingles := analytics.Shingles(sentence.Sentence)
+		if shingles == nil {
+			continue
+		}
+		for band, bucket := range analytics.Buckets(analytics.Signature(shingles)) {
+			arg.Bands = append(arg.Bands, int32(band))
+			arg.Buckets = append(arg.Buckets, bucket)
+			arg.SentenceIds = append(arg.SentenceIds, sentence.ID)
+		}
+	}
+
+	return queries.CreateSentenceBands(ctx, arg)
+}
+
+// QueueDuplicateIndex asks the duplicate indexer to index a record. When the queue is full
+// the record is left to the next sweep of the indexer.
+func QueueDuplicateIndex(id int32) {
+	select {
+	case duplicateQueue <- id:
+	default:
+	}
+}
+
+// RunDuplicateIndexer keeps the LSH index up to date until ctx is done. It indexes the
+// queued records as they arrive and sweeps for the records it missed every interval.
+func RunDuplicateIndexer(ctx context.Context, interval time.Duration) {
+	log := logging.FromContext(ctx).WithField("job", "duplicate_indexer")
+	index := func(id int32) {
+		if err := IndexDuplicates(ctx, id); err != nil {
+			log.WithError(err).WithField("record_id", id).Error("failed to index the record for duplicates")
+			return
+		}
+		log.WithField("record_id", id).Debug("record indexed for duplicates")
+	}
+	sweep := func() {
+		ids, err := getQueries(ctx).ListDuplicatePending(ctx)
+		if err != nil {
+			log.WithError(err).Error("failed to list the records to index for duplicates")
+			return
+		}
+		for _, id := range ids {
+			index(id)
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	sweep()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-duplicateQueue:
+			index(id)
+		case <-ticker.C:
+			sweep()
+		}
+	}
+}
+
+// Duplicates returns the records sharing passages with a record, the most overlapping first.
+// Candidate pairs found by LSH are kept when the Jaccard similarity of their shingles reaches threshold.
+func Duplicates(ctx context.Context, id int32, threshold float64) ([]DuplicateDocument, error) {
+	queries := getQueries(ctx)
+	if _, err := queries.GetRecord(ctx, id); err != nil {
+		return nil, err
+	}
+
+	candidates, err := queries.ListCandidatePairs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return []DuplicateDocument{}, nil
+	}
+
+	sentences, err := queries.ListRecordSentences(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	own := map[int32]database.Sentence{}
+	comparable := 0
+	for _, sentence := range sentences {
+		own[sentence.ID] = sentence
+		if analytics.Shingles(sentence.Sentence) != nil {
+			comparable++
+		}
+	}
+
+	otherIDs := make([]int32, 0, len(candidates))
+	for _, candidate := range candidates {
+		otherIDs = append(otherIDs, candidate.OtherSentenceID)
+	}
+	others, err := queries.ListSentencesByID(ctx, otherIDs)
+	if err != nil {
+		return nil, err
+	}
+	other := make(map[int32]database.Sentence, len(others))
+	for _, sentence := range others {
+		other[sentence.ID] = sentence
+	}
+
+	shingles := map[int32][]uint64{}
+	shinglesOf := func(sentence database.Sentence) []uint64 {
+		if _, ok := shingles[sentence.ID]; !ok {
+			shingles[sentence.ID] = analytics.Shingles(sentence.Sentence)
+		}
+		return shingles[sentence.ID]
+	}
+
+	pairs := map[int32][]DuplicatePair{}
+	matched := map[int32]map[int32]bool{}
+	for _, candidate := range candidates {
+		a, okA := own[candidate.SentenceID]
+		b, okB := other[candidate.OtherSentenceID]
+		if !okA || !okB {
+			continue
+		}
+		similarity := analytics.Jaccard(shinglesOf(a), shinglesOf(b))
+		if similarity < threshold {
+			continue
+		}
+		pairs[b.Pdfid] = append(pairs[b.Pdfid], DuplicatePair{Sentence: a, Other: b, Similarity: similarity})
+		if matched[b.Pdfid] == nil {
+			matched[b.Pdfid] = map[int32]bool{}
+		}
+		matched[b.Pdfid][a.ID] = true
+	}
+
+	recordIDs := make([]int32, 0, len(pairs))
+	for recordID := range pairs {
+		recordIDs = append(recordIDs, recordID)
+	}
+	records, err := queries.ListRecordsByID(ctx, recordIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]DuplicateDocument, 0, len(records))
+	for _, record := range records {
+		documentPairs := pairs[record.ID]
+		sort.Slice(documentPairs, func(i, j int) bool {
+			if documentPairs[i].Similarity != documentPairs[j].Similarity {
+				return documentPairs[i].Similarity > documentPairs[j].Similarity
+			}
+			return documentPairs[i].Sentence.ID < documentPairs[j].Sentence.ID
+		})
+		overlap := 0.0
+		if comparable > 0 {
+			overlap = 100 * float64(len(matched[record.ID])) / float64(comparable)
+		}
+		result = append(result, DuplicateDocument{Record: record, Overlap: overlap, Pairs: documentPairs})
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Overlap > result[j].Overlap })
+	return result, nil
+}
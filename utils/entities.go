@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"context"
+	"main/analytics"
+	"main/database"
+	"main/metrics"
+	"main/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// EntityLanguage is the language the entity model is trained on, the entities of the records
+// in other languages are not extracted
+const EntityLanguage = "en"
+
+// EntityCount is a named entity and the number of sentences mentioning it
+type EntityCount struct {
+	analytics.Entity
+	Mentions int
+}
+
+// EntityDocument is a record and the number of times it mentions an entity
+type EntityDocument struct {
+	Record   database.Record
+	Mentions int
+}
+
+// EntityQuery selects an entity by its text, case insensitively, and optionally its label
+type EntityQuery struct {
+	Text  string
+	Label string
+	// DocumentID restricts the co-occurrences to one record when set
+	DocumentID *int32
+	Limit      int
+}
+
+// StoreEntities extracts the named entities of the sentences of a record and stores them
+func StoreEntities(ctx context.Context, id int32, sentences []database.Sentence) error {
+	defer metrics.ObserveStage(metrics.StageEntities)()
+	_, span := tracing.Start(ctx, "prose.entities", attribute.Int("sentences", len(sentences)))
+
+	arg := database.CreateEntitiesParams{Pdfid: id}
+	for _, sentence := range sentences {
+		entities, err := analytics.Entities(sentence.Sentence)
+		if err != nil {
+			tracing.End(span, err)
+			return err
+		}
+		for _, entity := range entities {
+			arg.SentenceIds = append(arg.SentenceIds, sentence.ID)
+			arg.Texts = append(arg.Texts, entity.Text)
+			arg.Labels = append(arg.Labels, entity.Label)
+		}
+	}
+	span.SetAttributes(attribute.Int("entities", len(arg.Texts)))
+	tracing.End(span, nil)
+
+	if len(arg.Texts) == 0 {
+		return nil
+	}
+	return getQueries(ctx).CreateEntities(ctx, arg)
+}
+
+// DocumentEntities returns the entities of a record, most mentioned first
+func DocumentEntities(ctx context.Context, id int32, label string, limit int) ([]EntityCount, error) {
+	rows, err := getQueries(ctx).ListDocumentEntities(ctx, database.ListDocumentEntitiesParams{
+		Pdfid:    id,
+		Label:    nullString(label),
+		PageSize: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]EntityCount, 0, len(rows))
+	for _, row := range rows {
+		entities = append(entities, EntityCount{Entity: analytics.Entity{Text: row.Text, Label: row.Label}, Mentions: int(row.Mentions)})
+	}
+	return entities, nil
+}
+
+// EntityDocuments returns the records mentioning an entity, those mentioning it the most first
+func EntityDocuments(ctx context.Context, query EntityQuery) ([]EntityDocument, error) {
+	rows, err := getQueries(ctx).ListEntityDocuments(ctx, database.ListEntityDocumentsParams{
+		Text:     query.Text,
+		Label:    nullString(query.Label),
+		PageSize: int32(query.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]EntityDocument, 0, len(rows))
+	for _, row := range rows {
+		documents = append(documents, EntityDocument{
+			Record: database.Record{
+				ID:               row.ID,
+				Name:             row.Name,
+				UploadTime:       row.UploadTime,
+				Numofpages:       row.Numofpages,
+				Size:             row.Size,
+				Language:         row.Language,
+				Mimetype:         row.Mimetype,
+				Objectkey:        row.Objectkey,
+				Extractor:        row.Extractor,
+				Extractorversion: row.Extractorversion,
+			},
+			Mentions: int(row.Mentions),
+		})
+	}
+	return documents, nil
+}
+
+// CoOccurringEntities returns the entities mentioned in the same sentences as an entity,
+// counted by the number of shared sentences
+func CoOccurringEntities(ctx context.Context, query EntityQuery) ([]EntityCount, error) {
+	rows, err := getQueries(ctx).ListCoOccurringEntities(ctx, database.ListCoOccurringEntitiesParams{
+		Text:     query.Text,
+		Label:    nullString(query.Label),
+		Pdfid:    nullInt32(query.DocumentID),
+		PageSize: int32(query.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]EntityCount, 0, len(rows))
+	for _, row := range rows {
+		entities = append(entities, EntityCount{Entity: analytics.Entity{Text: row.Text, Label: row.Label}, Mentions: int(row.Mentions)})
+	}
+	return entities, nil
+}
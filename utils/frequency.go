@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"container/list"
+	"context"
+	"main/analytics"
+	"main/database"
+	"sync"
+)
+
+// maxCachedDocuments bounds the number of documents whose frequencies are kept in memory
+const maxCachedDocuments = 256
+
+// FrequencyQuery selects the terms counted by DocumentFrequencies
+type FrequencyQuery struct {
+	analytics.FrequencyOptions
+	PageFrom *int32
+	PageTo   *int32
+}
+
+type frequencyKey struct {
+	options  analytics.FrequencyOptions
+	pageFrom int32
+	pageTo   int32
+}
+
+// frequencyCache keeps the computed frequencies of the most recently used documents
+type frequencyCache struct {
+	mu        sync.Mutex
+	order     *list.List
+	documents map[int32]*list.Element
+	// generations changes whenever a record is invalidated so results computed before are not stored
+	generations map[int32]uint64
+}
+
+type cachedDocument struct {
+	id      int32
+	results map[frequencyKey][]analytics.TermFrequency
+}
+
+var frequencies = &frequencyCache{order: list.New(), documents: map[int32]*list.Element{}, generations: map[int32]uint64{}}
+
+func (f *frequencyCache) get(id int32, key frequencyKey) ([]analytics.TermFrequency, uint64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	element, ok := f.documents[id]
+	if !ok {
+		return nil, f.generations[id], false
+	}
+	f.order.MoveToFront(element)
+	result, ok := element.Value.(*cachedDocument).results[key]
+	return result, f.generations[id], ok
+}
+
+func (f *frequencyCache) put(id int32, generation uint64, key frequencyKey, result []analytics.TermFrequency) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.generations[id] != generation {
+		return
+	}
+
+	element, ok := f.documents[id]
+	if !ok {
+		element = f.order.PushFront(&cachedDocument{id: id, results: map[frequencyKey][]analytics.TermFrequency{}})
+		f.documents[id] = element
+		if f.order.Len() > maxCachedDocuments {
+			oldest := f.order.Back()
+			f.order.Remove(oldest)
+			delete(f.documents, oldest.Value.(*cachedDocument).id)
+		}
+	}
+	f.order.MoveToFront(element)
+	element.Value.(*cachedDocument).results[key] = result
+}
+
+func (f *frequencyCache) invalidate(id int32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.generations[id]++
+	if element, ok := f.documents[id]; ok {
+		f.order.Remove(element)
+		delete(f.documents, id)
+	}
+}
+
+// InvalidateFrequencies drops the cached frequencies of a record, to be called whenever its sentences change
+func InvalidateFrequencies(id int32) {
+	frequencies.invalidate(id)
+}
+
+func optionalPage(page *int32) int32 {
+	if page == nil {
+		return -1
+	}
+	return *page
+}
+
+// DocumentFrequencies returns the most frequent terms of a record, optionally restricted to a page range.
+// Without a language the stopwords of the language of the record are skipped.
+func DocumentFrequencies(ctx context.Context, id int32, query FrequencyQuery) ([]analytics.TermFrequency, error) {
+	language, err := ResolveLanguage(ctx, id, query.Language)
+	if err != nil {
+		return nil, err
+	}
+	query.Language = language
+
+	key := frequencyKey{options: query.FrequencyOptions, pageFrom: optionalPage(query.PageFrom), pageTo: optionalPage(query.PageTo)}
+	result, generation, ok := frequencies.get(id, key)
+	if ok {
+		return result, nil
+	}
+
+	sentences, err := getQueries(ctx).ListRecordSentencesInPages(ctx, database.ListRecordSentencesInPagesParams{
+		Pdfid:    id,
+		PageFrom: nullInt32(query.PageFrom),
+		PageTo:   nullInt32(query.PageTo),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make([]string, 0, len(sentences))
+	for _, sentence := range sentences {
+		texts = append(texts, sentence.Sentence)
+	}
+
+	result = analytics.TopTerms(texts, query.FrequencyOptions)
+	frequencies.put(id, generation, key, result)
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"main/analytics"
+	"main/database"
+	"main/extract"
+	"main/logging"
+)
+
+// Extraction is what is read from a file besides its text: how every page was obtained, the
+// metadata of pdfs and the language of the text
+type Extraction struct {
+	Document *extract.Document
+	Pages    []PageText
+	// Metadata is nil for the other formats than pdf and when it cannot be read
+	Metadata *extract.Metadata
+	Language string
+}
+
+// ExtractPages completes the document read from a file. The scanned pages of pdfs are
+// recognized and their metadata read, a failure of either is logged and the text layer kept.
+func ExtractPages(ctx context.Context, path string, mimeType string, document *extract.Document) Extraction {
+	x := Extraction{Document: document, Pages: PageTexts(document.Pages), Language: analytics.DetectLanguage(document.Pages)}
+	// only pdfs have scanned pages and metadata of their own
+	if mimeType != extract.MIMEPDF {
+		return x
+	}
+
+	var err error
+	x.Pages, err = RecognizeScannedPages(ctx, path, document.Pages)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Error("failed to recognize the scanned pages of the file")
+	}
+
+	x.Metadata, err = ReadMetadata(ctx, path)
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).Warn("failed to read the metadata of the file")
+	} else {
+		SetPageSizes(x.Pages, x.Metadata.PageSizes)
+	}
+	return x
+}
+
+// StoreDocument stores an ingested file under a generated key and records it with its extraction
+// in a single transaction: a file whose extraction cannot be stored leaves neither a record nor
+// an object behind. The error matches ErrStorage when the file cannot be stored and ErrDatabase
+// when it cannot be recorded.
+func StoreDocument(ctx context.Context, name string, file io.Reader, size int64, mimeType string, x Extraction) (database.Record, error) {
+	key := newObjectKey()
+	if err := UploadDocument(ctx, "pdf", key, file, mimeType); err != nil {
+		return database.Record{}, fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+
+	var record database.Record
+	var sentences []database.Sentence
+	err := inTx(ctx, func(ctx context.Context) error {
+		created, err := CreateRecord(ctx, name, key, int32(len(x.Document.Pages)), size, x.Language, mimeType, x.Document.Extractor)
+		if err != nil {
+			return err
+		}
+		sentences, err = storeExtraction(ctx, created, x)
+		if err != nil {
+			return err
+		}
+		record = created
+		return nil
+	})
+	if err != nil {
+		// the object is removed even when the failure is the cancellation of ctx
+		if removeErr := store.Remove(logging.Detach(ctx), "pdf", key); removeErr != nil {
+			logging.FromContext(ctx).WithError(removeErr).WithField("object", key).Error("failed to remove the object of the unrecorded file")
+		}
+		return record, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	// the caches follow the committed sentences only
+	InvalidateFrequencies(record.ID)
+	AddSimilarities(record.ID, record.Language, sentences)
+	QueueDuplicateIndex(record.ID)
+
+	if len(sentences) == 0 {
+		logging.FromContext(logging.WithField(ctx, "record_id", record.ID)).Warn("no text could be extracted from the file")
+	}
+	return record, nil
+}
+
+// storeExtraction stores everything read from the file of a record. Any failure fails the whole
+// extraction: the transaction it runs in cannot go on after a failed query.
+func storeExtraction(ctx context.Context, record database.Record, x Extraction) ([]database.Sentence, error) {
+	var sentences []database.Sentence
+	var err error
+	if x.Document.Blocks != nil {
+		blocks := StructureBlocks(x.Document.Blocks, x.Document.Pages, x.Pages)
+		sentences, err = StoreStructure(ctx, record, blocks)
+	} else {
+		sentences, err = SplitAndStore(ctx, x.Document.Pages, record)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sentences: %w", err)
+	}
+
+	if err := StorePages(ctx, record.ID, x.Pages); err != nil {
+		return nil, fmt.Errorf("pages: %w", err)
+	}
+	if err := StoreTables(ctx, record.ID, x.Document.Tables); err != nil {
+		return nil, fmt.Errorf("tables: %w", err)
+	}
+	if x.Metadata != nil {
+		if err := StoreMetadata(ctx, record.ID, x.Metadata); err != nil {
+			return nil, fmt.Errorf("metadata: %w", err)
+		}
+	}
+	if err := IndexRecord(ctx, record.ID, record.Language, x.Document.Pages); err != nil {
+		return nil, fmt.Errorf("terms: %w", err)
+	}
+	if record.Language == EntityLanguage {
+		if err := StoreEntities(ctx, record.ID, sentences); err != nil {
+			return nil, fmt.Errorf("entities: %w", err)
+		}
+	}
+	if err := StoreSummary(ctx, record.ID, record.Language, sentences); err != nil {
+		return nil, fmt.Errorf("summary: %w", err)
+	}
+	return sentences, nil
+}
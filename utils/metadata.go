@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"main/database"
+	"main/extract"
+	"main/metrics"
+	"main/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ReadMetadata reads the info dictionary, XMP metadata, page sizes and outline of a pdf
+func ReadMetadata(ctx context.Context, path string) (*extract.Metadata, error) {
+	defer metrics.ObserveStage(metrics.StageMetadata)()
+
+	ctx, span := tracing.Start(ctx, "metadata")
+	metadata, err := extract.ReadMetadata(ctx, path)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("pdf.version", metadata.Version), attribute.Int("outline", len(metadata.Outline)))
+	tracing.End(span, nil)
+	return metadata, nil
+}
+
+// SetPageSizes copies the size of every page read from the metadata of a pdf
+func SetPageSizes(pages []PageText, sizes []extract.PageSize) {
+	for i := range pages {
+		if i < len(sizes) {
+			pages[i].Width = sizes[i].Width
+			pages[i].Height = sizes[i].Height
+		}
+	}
+}
+
+// StoreMetadata stores the metadata and the outline of a record
+func StoreMetadata(ctx context.Context, id int32, metadata *extract.Metadata) error {
+	queries := getQueries(ctx)
+	err := queries.CreateMetadata(ctx, database.CreateMetadataParams{
+		Pdfid:      id,
+		Title:      metadata.Title,
+		Author:     metadata.Author,
+		Subject:    metadata.Subject,
+		Keywords:   metadata.Keywords,
+		Creator:    metadata.Creator,
+		Producer:   metadata.Producer,
+		Created:    nullTime(metadata.Created),
+		Modified:   nullTime(metadata.Modified),
+		Pdfversion: metadata.Version,
+		Encrypted:  metadata.Encrypted,
+	})
+	if err != nil {
+		return err
+	}
+	return storeOutline(ctx, queries, id, sql.NullInt32{}, metadata.Outline)
+}
+
+func storeOutline(ctx context.Context, queries *database.Queries, id int32, parent sql.NullInt32, items []extract.OutlineItem) error {
+	for _, item := range items {
+		created, err := queries.CreateOutlineItem(ctx, database.CreateOutlineItemParams{
+			Pdfid:    id,
+			Parentid: parent,
+			Title:    item.Title,
+			Page:     int32(item.Page),
+		})
+		if err != nil {
+			return err
+		}
+		err = storeOutline(ctx, queries, id, sql.NullInt32{Int32: created.ID, Valid: true}, item.Children)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetMetadata returns the metadata of a record, pgx.ErrNoRows for the records ingested before it was read
+func GetMetadata(ctx context.Context, id int32) (database.DocumentMetadatum, error) {
+	return getQueries(ctx).GetMetadata(ctx, id)
+}
+
+// GetOutline returns the bookmarks of a record as a tree
+func GetOutline(ctx context.Context, id int32) ([]extract.OutlineItem, error) {
+	rows, err := getQueries(ctx).ListRecordOutline(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// items are listed in creation order, parents before their children
+	children := map[int32][]database.OutlineItem{}
+	roots := []database.OutlineItem{}
+	for _, row := range rows {
+		if row.Parentid.Valid {
+			children[row.Parentid.Int32] = append(children[row.Parentid.Int32], row)
+		} else {
+			roots = append(roots, row)
+		}
+	}
+
+	var build func([]database.OutlineItem) []extract.OutlineItem
+	build = func(rows []database.OutlineItem) []extract.OutlineItem {
+		items := make([]extract.OutlineItem, 0, len(rows))
+		for _, row := range rows {
+			items = append(items, extract.OutlineItem{Title: row.Title, Page: int(row.Page), Children: build(children[row.ID])})
+		}
+		return items
+	}
+	return build(roots), nil
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"context"
+	"main/database"
+	"main/logging"
+	"main/metrics"
+	"main/ocr"
+	"main/tracing"
+	"strings"
+	"unicode"
+
+	"github.com/gen2brain/go-fitz"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultMinPageLetters is the number of letters under which a page is considered scanned
+const DefaultMinPageLetters = 20
+
+var (
+	ocrEngine      ocr.Engine
+	minPageLetters = DefaultMinPageLetters
+)
+
+// SetOCR sets the engine recognizing the pages with little or no text layer, nil disables it
+func SetOCR(engine ocr.Engine, minLetters int) {
+	ocrEngine = engine
+	if minLetters > 0 {
+		minPageLetters = minLetters
+	}
+}
+
+// PageText tells how the text of a page was obtained. Confidence, from 0 to 1, is only set for OCR'd pages.
+// Width and Height are the size of the page in points, 0 when unknown.
+type PageText struct {
+	Page       int
+	OCR        bool
+	Confidence float64
+	Width      float64
+	Height     float64
+}
+
+func countLetters(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// PageTexts returns the pages of a document as read from its text, none recognized with OCR
+func PageTexts(pages []string) []PageText {
+	result := make([]PageText, len(pages))
+	for i := range pages {
+		result[i] = PageText{Page: i + 1}
+	}
+	return result
+}
+
+// RecognizeScannedPages runs the pages of a pdf with fewer than minPageLetters letters through
+// the OCR engine and replaces their text when more was recognized. Failing pages keep their text.
+func RecognizeScannedPages(ctx context.Context, path string, pages []string) ([]PageText, error) {
+	result := PageTexts(pages)
+	scanned := []int{}
+	for i, text := range pages {
+		if countLetters(text) < minPageLetters {
+			scanned = append(scanned, i)
+		}
+	}
+	if len(scanned) == 0 {
+		return result, nil
+	}
+
+	log := logging.FromContext(ctx).WithField("scanned_pages", len(scanned))
+	if ocrEngine == nil {
+		log.Warn("pages without a text layer were not recognized, no OCR engine is configured")
+		return result, nil
+	}
+
+	defer metrics.ObserveStage(metrics.StageOCR)()
+	doc, err := fitz.New(path)
+	if err != nil {
+		return result, err
+	}
+	defer doc.Close()
+
+	for _, i := range scanned {
+		_, span := tracing.Start(ctx, "ocr.recognize", attribute.Int("page", i+1))
+		img, err := doc.Image(i)
+		if err != nil {
+			tracing.End(span, err)
+			log.WithError(err).WithField("page", i+1).Warn("failed to render a scanned page")
+			continue
+		}
+		recognized, err := ocrEngine.Recognize(ctx, img)
+		tracing.End(span, err)
+		if err != nil {
+			log.WithError(err).WithField("page", i+1).Warn("failed to recognize a scanned page")
+			continue
+		}
+		metrics.PagesRecognized.Inc()
+
+		if countLetters(recognized.Text) > countLetters(pages[i]) {
+			pages[i] = strings.Replace(recognized.Text, "\n", " ", -1)
+			result[i].OCR = true
+			result[i].Confidence = recognized.Confidence
+		}
+	}
+	return result, nil
+}
+
+// StorePages records which pages of a record were OCR'd, with what confidence, and their size
+func StorePages(ctx context.Context, id int32, pages []PageText) error {
+	if len(pages) == 0 {
+		return nil
+	}
+
+	params := database.CreatePagesParams{
+		Pdfid:       id,
+		Pages:       make([]int32, len(pages)),
+		Ocr:         make([]bool, len(pages)),
+		Confidences: make([]float64, len(pages)),
+		Widths:      make([]float64, len(pages)),
+		Heights:     make([]float64, len(pages)),
+	}
+	for i, page := range pages {
+		params.Pages[i] = int32(page.Page)
+		params.Ocr[i] = page.OCR
+		params.Confidences[i] = page.Confidence
+		params.Widths[i] = page.Width
+		params.Heights[i] = page.Height
+	}
+	return getQueries(ctx).CreatePages(ctx, params)
+}
+
+// ListRecordPages returns how the text of every page of a record was obtained. Pages stored
+// before the OCR existed are reported as read from their text layer.
+func ListRecordPages(ctx context.Context, record database.Record) ([]PageText, error) {
+	rows, err := getQueries(ctx).ListRecordPages(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]PageText, record.Numofpages)
+	for i := range pages {
+		pages[i] = PageText{Page: i + 1}
+	}
+	for _, row := range rows {
+		if row.Page >= 1 && int(row.Page) <= len(pages) {
+			pages[row.Page-1] = PageText{
+				Page:       int(row.Page),
+				OCR:        row.Ocr,
+				Confidence: row.Confidence,
+				Width:      row.Width,
+				Height:     row.Height,
+			}
+		}
+	}
+	return pages, nil
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"main/ocr"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// blankPDF writes a pdf of blank pages, as a scanner without a text layer would
+func blankPDF(t *testing.T, pages int) string {
+	var b bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	b.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := ""
+	for i := 0; i < pages; i++ {
+		kids += fmt.Sprintf("%d 0 R ", i+3)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, pages))
+	for i := 0; i < pages; i++ {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 200 200] >>")
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	path := filepath.Join(t.TempDir(), "scan.pdf")
+	if err := os.WriteFile(path, b.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestRecognizeScannedPages recognizes the pages with too few letters only and keeps the text
+// layer when the engine fails or recognizes less
+func TestRecognizeScannedPages(t *testing.T) {
+	defer SetOCR(nil, DefaultMinPageLetters)
+	path := blankPDF(t, 3)
+	layer := "A page with a text layer long enough to be kept as it is."
+
+	tests := []struct {
+		name   string
+		engine *ocr.Fake
+		pages  []string
+		want   []string
+		ocr    []bool
+		calls  int
+	}{
+		{
+			name:   "scanned pages",
+			engine: &ocr.Fake{Result: ocr.Result{Text: "Recognized text\nof the scanned page", Confidence: 0.9}},
+			pages:  []string{layer, "", "12"},
+			want:   []string{layer, "Recognized text of the scanned page", "Recognized text of the scanned page"},
+			ocr:    []bool{false, true, true},
+			calls:  2,
+		},
+		{
+			name:   "less recognized",
+			engine: &ocr.Fake{Result: ocr.Result{Text: "Noise", Confidence: 0.2}},
+			pages:  []string{layer, "A footer only", ""},
+			want:   []string{layer, "A footer only", "Noise"},
+			ocr:    []bool{false, false, true},
+			calls:  2,
+		},
+		{
+			name:   "failing engine",
+			engine: &ocr.Fake{Err: errors.New("engine crashed")},
+			pages:  []string{"", layer, ""},
+			want:   []string{"", layer, ""},
+			ocr:    []bool{false, false, false},
+			calls:  2,
+		},
+	}
+	for _, tt := range tests {
+		SetOCR(tt.engine, DefaultMinPageLetters)
+		result, err := RecognizeScannedPages(context.Background(), path, tt.pages)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(tt.pages, tt.want) {
+			t.Errorf("%s: got pages %q, want %q", tt.name, tt.pages, tt.want)
+		}
+		for i, page := range result {
+			if page.Page != i+1 || page.OCR != tt.ocr[i] {
+				t.Errorf("%s: got page %d OCR %v, want page %d OCR %v", tt.name, page.Page, page.OCR, i+1, tt.ocr[i])
+			}
+			if page.OCR && page.Confidence != tt.engine.Result.Confidence {
+				t.Errorf("%s: got confidence %v on page %d, want %v", tt.name, page.Confidence, i+1, tt.engine.Result.Confidence)
+			}
+		}
+		if calls := tt.engine.Calls(); calls != tt.calls {
+			t.Errorf("%s: got %d recognized images, want %d", tt.name, calls, tt.calls)
+		}
+	}
+
+	// without an engine the pages are kept as they were read
+	SetOCR(nil, DefaultMinPageLetters)
+	pages := []string{"", layer, ""}
+	result, err := RecognizeScannedPages(context.Background(), path, pages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result, PageTexts(pages)) || pages[0] != "" {
+		t.Errorf("without an engine: got %v, %q", result, pages)
+	}
+}
@@ -0,0 +1,296 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"main/database"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record sort keys accepted by ListRecordsPage
+const (
+	SortName       = "name"
+	SortUploadTime = "upload_time"
+	SortSize       = "size"
+	SortPages      = "pages"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// RecordFilter selects, sorts and pages the listed records
+type RecordFilter struct {
+	Sort           string
+	Descending     bool
+	NamePrefix     string
+	UploadedAfter  *time.Time
+	UploadedBefore *time.Time
+	MinSize        *int64
+	MaxSize        *int64
+	MinPages       *int32
+	MaxPages       *int32
+	// Title, Author, Keywords and Producer match the metadata of the records containing them, whatever their case
+	Title         string
+	Author        string
+	Keywords      string
+	Producer      string
+	PDFVersion    string
+	Encrypted     *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Outdated keeps the records extracted with an older version than ExtractionVersion
+	Outdated bool
+	Cursor   string
+	Limit    int
+}
+
+// Empty reports whether the filter keeps every record, whatever their order and page
+func (f RecordFilter) Empty() bool {
+	f.Sort, f.Descending, f.Cursor, f.Limit = "", false, "", 0
+	return f == RecordFilter{}
+}
+
+// recordCursor is the position after the last record of a page
+type recordCursor struct {
+	Sort       string `json:"s"`
+	Descending bool   `json:"d"`
+	Key        string `json:"k"`
+	ID         int32  `json:"i"`
+}
+
+func encodeCursor(cursor interface{}) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(encoded string, cursor interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, cursor); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+func pageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return limit
+}
+
+func nullInt32(value *int32) sql.NullInt32 {
+	if value == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *value, Valid: true}
+}
+
+func nullInt64(value *int64) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *value, Valid: true}
+}
+
+func nullBool(value *bool) sql.NullBool {
+	if value == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *value, Valid: true}
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *value, Valid: true}
+}
+
+// escapeLike escapes the LIKE wildcards of a pattern
+func escapeLike(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix)
+}
+
+// recordsPage runs the query of the sort key and order, each walks the (key, id) index of records
+func recordsPage(ctx context.Context, sort string, descending bool, params database.ListRecordsByNameParams) ([]database.Record, error) {
+	queries := getQueries(ctx)
+	switch {
+	case sort == SortUploadTime && descending:
+		return queries.ListRecordsByUploadTimeDesc(ctx, database.ListRecordsByUploadTimeDescParams(params))
+	case sort == SortUploadTime:
+		return queries.ListRecordsByUploadTime(ctx, database.ListRecordsByUploadTimeParams(params))
+	case sort == SortSize && descending:
+		return queries.ListRecordsBySizeDesc(ctx, database.ListRecordsBySizeDescParams(params))
+	case sort == SortSize:
+		return queries.ListRecordsBySize(ctx, database.ListRecordsBySizeParams(params))
+	case sort == SortPages && descending:
+		return queries.ListRecordsByPagesDesc(ctx, database.ListRecordsByPagesDescParams(params))
+	case sort == SortPages:
+		return queries.ListRecordsByPages(ctx, database.ListRecordsByPagesParams(params))
+	case descending:
+		return queries.ListRecordsByNameDesc(ctx, database.ListRecordsByNameDescParams(params))
+	}
+	return queries.ListRecordsByName(ctx, params)
+}
+
+// sortKey is the value of the sort key of a record, as the queries cast it back
+func sortKey(sort string, record database.Record) string {
+	switch sort {
+	case SortUploadTime:
+		return record.UploadTime.Format("2006-01-02 15:04:05.999999")
+	case SortSize:
+		return strconv.FormatInt(record.Size, 10)
+	case SortPages:
+		return strconv.FormatInt(int64(record.Numofpages), 10)
+	}
+	return record.Name
+}
+
+// validSortKey tells whether the key of a cursor can be cast to the type of its sort key
+func validSortKey(sort string, key string) bool {
+	switch sort {
+	case SortUploadTime:
+		_, err := time.Parse("2006-01-02 15:04:05.999999", key)
+		return err == nil
+	case SortSize:
+		_, err := strconv.ParseInt(key, 10, 64)
+		return err == nil
+	case SortPages:
+		_, err := strconv.ParseInt(key, 10, 32)
+		return err == nil
+	}
+	return true
+}
+
+// ListRecordsPage returns one page of records and the cursor of the next page, empty on the last page
+func ListRecordsPage(ctx context.Context, filter RecordFilter) ([]database.Record, string, error) {
+	if filter.Sort == "" {
+		filter.Sort = SortName
+	}
+	size := pageSize(filter.Limit)
+
+	params := database.ListRecordsByNameParams{
+		UploadedAfter:  nullTime(filter.UploadedAfter),
+		UploadedBefore: nullTime(filter.UploadedBefore),
+		MinSize:        nullInt64(filter.MinSize),
+		MaxSize:        nullInt64(filter.MaxSize),
+		MinPages:       nullInt32(filter.MinPages),
+		MaxPages:       nullInt32(filter.MaxPages),
+		Title:          nullString(escapeLike(filter.Title)),
+		Author:         nullString(escapeLike(filter.Author)),
+		Keywords:       nullString(escapeLike(filter.Keywords)),
+		Producer:       nullString(escapeLike(filter.Producer)),
+		PdfVersion:     nullString(filter.PDFVersion),
+		Encrypted:      nullBool(filter.Encrypted),
+		CreatedAfter:   nullTime(filter.CreatedAfter),
+		CreatedBefore:  nullTime(filter.CreatedBefore),
+		PageSize:       int32(size + 1),
+	}
+	if filter.Outdated {
+		params.ExtractorVersionBelow = sql.NullInt32{Int32: ExtractionVersion, Valid: true}
+	}
+	if filter.NamePrefix != "" {
+		params.NamePrefix = sql.NullString{String: escapeLike(filter.NamePrefix), Valid: true}
+	}
+	if filter.Cursor != "" {
+		var cursor recordCursor
+		if err := decodeCursor(filter.Cursor, &cursor); err != nil {
+			return nil, "", err
+		}
+		if cursor.Sort != filter.Sort || cursor.Descending != filter.Descending || !validSortKey(cursor.Sort, cursor.Key) {
+			return nil, "", ErrInvalidCursor
+		}
+		params.AfterKey = sql.NullString{String: cursor.Key, Valid: true}
+		params.AfterID = cursor.ID
+	}
+
+	records, err := recordsPage(ctx, filter.Sort, filter.Descending, params)
+	if err != nil {
+		return nil, "", err
+	}
+	// an empty listing is still a list
+	if records == nil {
+		records = []database.Record{}
+	}
+
+	next := ""
+	if len(records) > size {
+		records = records[:size]
+		last := records[size-1]
+		next = encodeCursor(recordCursor{Sort: filter.Sort, Descending: filter.Descending, Key: sortKey(filter.Sort, last), ID: last.ID})
+	}
+
+	return records, next, nil
+}
+
+// ListAllRecords returns every record matching the filter, walking its pages from the first
+func ListAllRecords(ctx context.Context, filter RecordFilter) ([]database.Record, error) {
+	filter.Limit = MaxPageSize
+	filter.Cursor = ""
+	records := []database.Record{}
+	for {
+		page, next, err := ListRecordsPage(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page...)
+		if next == "" {
+			return records, nil
+		}
+		filter.Cursor = next
+	}
+}
+
+// sentenceCursor is the id of the last sentence of a page
+type sentenceCursor struct {
+	ID int32 `json:"i"`
+}
+
+// ListRecordSentencesPage returns one page of the sentences of a record in reading order
+// and the cursor of the next page, empty on the last page
+func ListRecordSentencesPage(ctx context.Context, id int32, cursor string, limit int) ([]database.Sentence, string, error) {
+	size := pageSize(limit)
+
+	var after sentenceCursor
+	if cursor != "" {
+		if err := decodeCursor(cursor, &after); err != nil {
+			return nil, "", err
+		}
+	}
+
+	sentences, err := getQueries(ctx).ListRecordSentencesPage(ctx, database.ListRecordSentencesPageParams{
+		Pdfid:    id,
+		AfterID:  after.ID,
+		PageSize: int32(size + 1),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(sentences) > size {
+		sentences = sentences[:size]
+		next = encodeCursor(sentenceCursor{ID: sentences[size-1].ID})
+	}
+
+	return sentences, next, nil
+}
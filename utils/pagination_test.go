@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"errors"
+	"main/database"
+	"testing"
+	"time"
+)
+
+// TestCursor decodes the encoded cursors back to the same position
+func TestCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor recordCursor
+	}{
+		{"name", recordCursor{Sort: SortName, Key: "report.pdf", ID: 7}},
+		{"descending", recordCursor{Sort: SortSize, Descending: true, Key: "1024", ID: 3}},
+		{"empty key", recordCursor{Sort: SortName, ID: 1}},
+		{"unicode key", recordCursor{Sort: SortName, Key: "تقرير.pdf", ID: 12}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var decoded recordCursor
+			if err := decodeCursor(encodeCursor(test.cursor), &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if decoded != test.cursor {
+				t.Errorf("got %+v, want %+v", decoded, test.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"not base64", "!!!"},
+		{"not json", "bm90IGpzb24"},
+		{"wrong type", encodeCursor(map[string]string{"i": "seven"})},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var cursor recordCursor
+			if err := decodeCursor(test.encoded, &cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("got %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+// TestSortKey checks that the key of the next cursor can be cast back by the query of its sort
+func TestSortKey(t *testing.T) {
+	record := database.Record{
+		Name:       "report.pdf",
+		UploadTime: time.Date(2024, 3, 9, 14, 5, 6, 123456000, time.UTC),
+		Size:       2048,
+		Numofpages: 12,
+	}
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{SortName, "report.pdf"},
+		{SortUploadTime, "2024-03-09 14:05:06.123456"},
+		{SortSize, "2048"},
+		{SortPages, "12"},
+	}
+	for _, test := range tests {
+		t.Run(test.sort, func(t *testing.T) {
+			key := sortKey(test.sort, record)
+			if key != test.want {
+				t.Errorf("got %q, want %q", key, test.want)
+			}
+			if !validSortKey(test.sort, key) {
+				t.Errorf("the key %q of %s is not valid", key, test.sort)
+			}
+		})
+	}
+}
+
+func TestValidSortKey(t *testing.T) {
+	tests := []struct {
+		sort string
+		key  string
+		want bool
+	}{
+		{SortName, "anything", true},
+		{SortUploadTime, "2024-03-09 14:05:06", true},
+		{SortUploadTime, "yesterday", false},
+		{SortSize, "12", true},
+		{SortSize, "12.5", false},
+		{SortPages, "99999999999", false},
+	}
+	for _, test := range tests {
+		t.Run(test.sort+" "+test.key, func(t *testing.T) {
+			if got := validSortKey(test.sort, test.key); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// TestRecordFilterEmpty ignores the order and the page of the listing
+func TestRecordFilterEmpty(t *testing.T) {
+	size := int64(10)
+	tests := []struct {
+		name   string
+		filter RecordFilter
+		want   bool
+	}{
+		{"no filter", RecordFilter{}, true},
+		{"order and page", RecordFilter{Sort: SortSize, Descending: true, Cursor: "abc", Limit: 10}, true},
+		{"name", RecordFilter{NamePrefix: "report"}, false},
+		{"size", RecordFilter{MinSize: &size}, false},
+		{"outdated", RecordFilter{Sort: SortName, Outdated: true}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.filter.Empty(); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
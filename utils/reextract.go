@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/database"
+	"main/extract"
+	"main/logging"
+	"os"
+	"sync"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// ExtractionVersion is recorded on every record with the name of its extractor. It is raised
+// whenever a change to the extractors, the OCR or the splitting of sentences changes what is
+// stored of a document, the records of an older version are then listed as outdated.
+const ExtractionVersion = 2
+
+// Reextract extracts the stored file of a record again and replaces its sentences, structure,
+// pages, tables, metadata, terms, entities and summary in a single transaction: the record keeps
+// what it had until the new extraction is stored. The file is checked against the upload policy
+// as when it was uploaded, the error then matches ErrUploadTooManyPages, ErrUploadEncrypted or
+// ErrUploadJavaScript. The error matches pgx.ErrNoRows for a missing record, deleted during the
+// extraction included, ErrStorage when the file cannot be copied and ErrDatabase when nothing
+// was replaced.
+func Reextract(ctx context.Context, id int32) (database.Record, error) {
+	record, err := GetRecord(ctx, id)
+	if err != nil {
+		return record, err
+	}
+	ctx = logging.WithField(ctx, "format", record.Mimetype)
+
+	localFile, err := copyRecord(ctx, record)
+	if err != nil {
+		return record, fmt.Errorf("%w: %s: %v", ErrStorage, localFile, err)
+	}
+	defer os.Remove(localFile)
+
+	if record.Mimetype == extract.MIMEPDF {
+		if _, err := InspectPDF(ctx, localFile); err != nil {
+			return record, err
+		}
+	}
+	document, err := ReadDocument(ctx, localFile, record.Mimetype)
+	if err != nil {
+		return record, err
+	}
+	if err := CheckPageCount(len(document.Pages)); err != nil {
+		return record, err
+	}
+	x := ExtractPages(ctx, localFile, record.Mimetype, document)
+
+	var sentences []database.Sentence
+	err = inTx(ctx, func(ctx context.Context) error {
+		queries := getQueries(ctx)
+		if err := queries.DeleteRecordExtraction(ctx, id); err != nil {
+			return err
+		}
+		updated, err := queries.UpdateRecordExtraction(ctx, database.UpdateRecordExtractionParams{
+			ID:               id,
+			Numofpages:       int32(len(document.Pages)),
+			Language:         x.Language,
+			Extractor:        document.Extractor,
+			Extractorversion: ExtractionVersion,
+		})
+		if err != nil {
+			return err
+		}
+
+		sentences, err = storeExtraction(ctx, updated, x)
+		if err != nil {
+			return err
+		}
+		record = updated
+		return nil
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return record, err
+	}
+	if err != nil {
+		return record, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	// the caches follow the committed sentences only
+	InvalidateFrequencies(id)
+	AddSimilarities(id, record.Language, sentences)
+	QueueDuplicateIndex(id)
+
+	if len(sentences) == 0 {
+		logging.FromContext(ctx).Warn("no text could be extracted from the file")
+	}
+	return record, nil
+}
+
+// ReextractRecords extracts again the records matching the filter, the whole corpus when it is
+// empty, workers of them at once. The records are all listed before the first is extracted, so
+// that those leaving the filter once extracted are not missed. report is called with the
+// outcome of every record, from the workers.
+func ReextractRecords(ctx context.Context, filter RecordFilter, workers int, report func(database.Record, error)) error {
+	records, err := ListAllRecords(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan database.Record)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range jobs {
+				recordCtx := logging.WithField(ctx, "record_id", record.ID)
+				updated, err := Reextract(recordCtx, record.ID)
+				if err != nil {
+					updated = record
+				}
+				report(updated, err)
+			}
+		}()
+	}
+	for _, record := range records {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- record
+	}
+	close(jobs)
+	wg.Wait()
+	return ctx.Err()
+}
@@ -0,0 +1,406 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"main/database"
+	"main/logging"
+	"main/metrics"
+	"main/storage"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of a resumable upload
+const (
+	UploadReceiving = "receiving"
+	UploadIngesting = "ingesting"
+	UploadIngested  = "ingested"
+	UploadFailed    = "failed"
+)
+
+// UploadTTL is how long a resumable upload is kept after its creation, received or not
+const UploadTTL = 24 * time.Hour
+
+// the parts of the resumable uploads are stored next to the documents, under another prefix than
+// the keys of the documents
+const uploadBucket = "pdf"
+
+func uploadKey(id string) string {
+	return "uploads/" + id
+}
+
+// Reasons a chunk of a resumable upload is rejected, matched with errors.Is
+var (
+	ErrUploadOffset    = errors.New("offset does not match the bytes received")
+	ErrUploadLocked    = errors.New("another chunk of the upload is being received")
+	ErrUploadLength    = errors.New("chunk past the length of the upload")
+	ErrUploadChunkSize = errors.New("chunk too small")
+	ErrUploadChecksum  = errors.New("checksum mismatch")
+	ErrUploadAlgorithm = errors.New("unsupported checksum algorithm")
+	ErrChecksumInvalid = errors.New("invalid checksum")
+)
+
+// ChecksumAlgorithms are the algorithms of the checksums of the chunks and files, as named by tus
+var ChecksumAlgorithms = []string{"md5", "sha1", "sha256"}
+
+// Checksum is the expected digest of a chunk or file, written as the algorithm and the base64
+// encoded digest separated by a space
+type Checksum struct {
+	Algorithm string
+	Digest    []byte
+}
+
+// ParseChecksum reads a checksum, an error matching ErrUploadAlgorithm for the algorithms not
+// supported and ErrChecksumInvalid for the values that are not checksums
+func ParseChecksum(value string) (Checksum, error) {
+	algorithm, encoded, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok {
+		return Checksum{}, fmt.Errorf("%w: %q is not an algorithm and a digest", ErrChecksumInvalid, value)
+	}
+	checksum := Checksum{Algorithm: strings.ToLower(algorithm)}
+	if checksum.hash() == nil {
+		return Checksum{}, fmt.Errorf("%w: %s", ErrUploadAlgorithm, algorithm)
+	}
+	digest, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return Checksum{}, fmt.Errorf("%w: %v", ErrChecksumInvalid, err)
+	}
+	checksum.Digest = digest
+	return checksum, nil
+}
+
+func (c Checksum) hash() hash.Hash {
+	switch c.Algorithm {
+	case "md5":
+		return md5.New()
+	case "sha1":
+		return sha1.New()
+	case "sha256":
+		return sha256.New()
+	}
+	return nil
+}
+
+// fileHash returns the checksum of the file of an upload and the hash of the chunks received so
+// far, resumed from the state saved with the last part. The hash is nil without a checksum.
+func fileHash(upload database.Upload) (Checksum, hash.Hash, error) {
+	if upload.Checksum == "" {
+		return Checksum{}, nil, nil
+	}
+	expected, err := ParseChecksum(upload.Checksum)
+	if err != nil {
+		return expected, nil, err
+	}
+	h := expected.hash()
+	if len(upload.Hashstate) > 0 {
+		if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(upload.Hashstate); err != nil {
+			return expected, nil, err
+		}
+	}
+	return expected, h, nil
+}
+
+func (c Checksum) verify(h hash.Hash) error {
+	if !bytes.Equal(h.Sum(nil), c.Digest) {
+		return fmt.Errorf("%w: the %s digest is %s", ErrUploadChecksum, c.Algorithm, base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	}
+	return nil
+}
+
+// uploadLocks holds a mutex per resumable upload, a single chunk of an upload is received at a time
+var uploadLocks sync.Map
+
+func lockUpload(id string) (func(), error) {
+	value, _ := uploadLocks.LoadOrStore(id, &sync.Mutex{})
+	mu := value.(*sync.Mutex)
+	if !mu.TryLock() {
+		return nil, ErrUploadLocked
+	}
+	return mu.Unlock, nil
+}
+
+// CreateUpload starts a resumable upload of a file of length bytes. The checksum of the whole
+// file, when given, is verified once it is received.
+func CreateUpload(ctx context.Context, name string, contentType string, length int64, checksum string) (database.Upload, error) {
+	if err := CheckUploadSize(length); err != nil {
+		return database.Upload{}, err
+	}
+	if checksum != "" {
+		if _, err := ParseChecksum(checksum); err != nil {
+			return database.Upload{}, err
+		}
+	}
+
+	id := uuid.NewString()
+	multipartID, err := store.NewMultipart(ctx, uploadBucket, uploadKey(id), contentType)
+	if err != nil {
+		return database.Upload{}, fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+	upload, err := getQueries(ctx).CreateUpload(ctx, database.CreateUploadParams{
+		ID:          id,
+		Name:        name,
+		Contenttype: contentType,
+		Length:      length,
+		Multipartid: multipartID,
+		Checksum:    checksum,
+		Expiresat:   time.Now().Add(UploadTTL),
+	})
+	if err != nil {
+		if abortErr := store.AbortMultipart(ctx, uploadBucket, uploadKey(id), multipartID); abortErr != nil {
+			logging.FromContext(ctx).WithError(abortErr).Error("failed to abort the multipart upload")
+		}
+		return database.Upload{}, err
+	}
+	return upload, nil
+}
+
+// GetUpload returns a resumable upload, pgx.ErrNoRows once it expired
+func GetUpload(ctx context.Context, id string) (database.Upload, error) {
+	return getQueries(ctx).GetUpload(ctx, id)
+}
+
+// WriteUploadChunk stores the chunk of a resumable upload starting at offset as a part of its
+// multipart upload. The chunks but the last one must be at least storage.MinPartSize bytes long.
+// The upload is returned with the bytes received so far, see CompleteUpload once all are.
+func WriteUploadChunk(ctx context.Context, id string, offset int64, chunk []byte, checksum string) (database.Upload, error) {
+	unlock, err := lockUpload(id)
+	if err != nil {
+		return database.Upload{}, err
+	}
+	defer unlock()
+
+	queries := getQueries(ctx)
+	upload, err := queries.GetUpload(ctx, id)
+	if err != nil {
+		return upload, err
+	}
+	if upload.Status != UploadReceiving {
+		return upload, fmt.Errorf("%w: the upload was received", ErrUploadOffset)
+	}
+	if offset != upload.Received {
+		return upload, fmt.Errorf("%w: %d bytes were received, not %d", ErrUploadOffset, upload.Received, offset)
+	}
+	size := int64(len(chunk))
+	if offset+size > upload.Length {
+		return upload, fmt.Errorf("%w: %d bytes at %d, the upload has %d", ErrUploadLength, size, offset, upload.Length)
+	}
+	if size == 0 {
+		return upload, nil
+	}
+	if offset+size < upload.Length && size < storage.MinPartSize {
+		return upload, fmt.Errorf("%w: %d bytes, the chunks but the last one have at least %d", ErrUploadChunkSize, size, storage.MinPartSize)
+	}
+	if checksum != "" {
+		expected, err := ParseChecksum(checksum)
+		if err != nil {
+			return upload, err
+		}
+		h := expected.hash()
+		h.Write(chunk)
+		if err := expected.verify(h); err != nil {
+			return upload, err
+		}
+	}
+	var state []byte
+	_, h, err := fileHash(upload)
+	if err != nil {
+		return upload, err
+	}
+	if h != nil {
+		h.Write(chunk)
+		if state, err = h.(encoding.BinaryMarshaler).MarshalBinary(); err != nil {
+			return upload, err
+		}
+	}
+
+	etag, err := store.PutPart(ctx, uploadBucket, uploadKey(id), upload.Multipartid, int(upload.Parts)+1, bytes.NewReader(chunk), size)
+	if err != nil {
+		return upload, fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+	added, err := queries.AddUploadPart(ctx, database.AddUploadPartParams{Size: size, Hashstate: state, ID: id, Received: offset, Etag: etag})
+	if err != nil {
+		return upload, err
+	}
+	if added == 0 {
+		return upload, fmt.Errorf("%w: the upload changed while the chunk was stored", ErrUploadOffset)
+	}
+	upload.Received += size
+	upload.Parts++
+	upload.Hashstate = state
+	return upload, nil
+}
+
+// CompleteUpload verifies the checksum of a received upload, assembles its parts and copies the
+// file to a local file the caller must remove. An upload whose checksum does not match is left
+// receiving with its parts, until the client deletes it or it expires. The stored file is removed
+// once copied and the upload is left ingesting, the caller records its outcome with FinishUpload.
+func CompleteUpload(ctx context.Context, id string) (string, error) {
+	unlock, err := lockUpload(id)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	queries := getQueries(ctx)
+	upload, err := queries.GetUpload(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if upload.Status != UploadReceiving || upload.Received < upload.Length {
+		return "", fmt.Errorf("%w: %d of %d bytes were received", ErrUploadOffset, upload.Received, upload.Length)
+	}
+	expected, h, err := fileHash(upload)
+	if err != nil {
+		return "", err
+	}
+	if h != nil {
+		if err := expected.verify(h); err != nil {
+			return "", err
+		}
+	}
+
+	parts, err := queries.ListUploadParts(ctx, upload.ID)
+	if err != nil {
+		return "", err
+	}
+	completed := make([]storage.Part, len(parts))
+	for i, part := range parts {
+		completed[i] = storage.Part{Number: int(part.Number), ETag: part.Etag}
+	}
+	key := uploadKey(upload.ID)
+	if err := store.CompleteMultipart(ctx, uploadBucket, key, upload.Multipartid, completed); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+	defer func() {
+		if err := store.Remove(ctx, uploadBucket, key); err != nil {
+			logging.FromContext(ctx).WithError(err).Error("failed to remove the received upload")
+		}
+	}()
+
+	localFile, err := copyUpload(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	err = queries.UpdateUploadStatus(ctx, database.UpdateUploadStatusParams{ID: upload.ID, Status: UploadIngesting})
+	if err != nil {
+		os.Remove(localFile)
+		return "", err
+	}
+	return localFile, nil
+}
+
+// copyUpload copies a stored file to a local file
+func copyUpload(ctx context.Context, key string) (string, error) {
+	defer metrics.ObserveStage(metrics.StageSave)()
+
+	object, err := store.Get(ctx, uploadBucket, key)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrStorage, err)
+	}
+	defer object.Close()
+
+	local, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(local, object)
+	if closeErr := local.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(local.Name())
+		return "", err
+	}
+	return local.Name(), nil
+}
+
+// FinishUpload records the outcome of the ingestion of an upload, the document created or why it failed
+func FinishUpload(ctx context.Context, id string, pdfID int32, code string, message string) {
+	params := database.UpdateUploadStatusParams{ID: id, Status: UploadIngested, Pdfid: sql.NullInt32{Int32: pdfID, Valid: pdfID > 0}}
+	if pdfID == 0 {
+		params.Status, params.Errorcode, params.Errormessage = UploadFailed, code, message
+	}
+	if err := getQueries(ctx).UpdateUploadStatus(ctx, params); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("upload_id", id).Error("failed to record the outcome of the upload")
+	}
+}
+
+// FailIngestingUploads fails the uploads left ingesting, their files were lost with the process
+// that ingested them. It is run on start, before any upload is received.
+func FailIngestingUploads(ctx context.Context, code string, message string) (int64, error) {
+	return getQueries(ctx).FailIngestingUploads(ctx, database.FailIngestingUploadsParams{Errorcode: code, Errormessage: message})
+}
+
+// DeleteUpload aborts a resumable upload, removing its parts
+func DeleteUpload(ctx context.Context, upload database.Upload) error {
+	unlock, err := lockUpload(upload.ID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return deleteUpload(ctx, upload)
+}
+
+func deleteUpload(ctx context.Context, upload database.Upload) error {
+	if upload.Status == UploadReceiving {
+		err := store.AbortMultipart(ctx, uploadBucket, uploadKey(upload.ID), upload.Multipartid)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrStorage, err)
+		}
+	}
+	if err := getQueries(ctx).DeleteUpload(ctx, upload.ID); err != nil {
+		return err
+	}
+	uploadLocks.Delete(upload.ID)
+	return nil
+}
+
+// RunUploadSweeper deletes the expired resumable uploads every interval until ctx is done
+func RunUploadSweeper(ctx context.Context, interval time.Duration) {
+	log := logging.FromContext(ctx).WithField("job", "upload_sweeper")
+	sweep := func() {
+		uploads, err := getQueries(ctx).ListExpiredUploads(ctx)
+		if err != nil {
+			log.WithError(err).Error("failed to list the expired uploads")
+			return
+		}
+		for _, upload := range uploads {
+			unlock, err := lockUpload(upload.ID)
+			if err != nil {
+				continue
+			}
+			err = deleteUpload(ctx, upload)
+			unlock()
+			if err != nil {
+				log.WithError(err).WithField("upload_id", upload.ID).Error("failed to delete the expired upload")
+			}
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	sweep()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sweep()
+		}
+	}
+}
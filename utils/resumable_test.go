@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"main/database"
+	"testing"
+)
+
+// TestFileHash checks that the hash resumed chunk after chunk verifies the checksum of the whole file
+func TestFileHash(t *testing.T) {
+	file := []byte("the chunks of a resumable upload, received one request at a time")
+	md5Sum, sha1Sum, sha256Sum := md5.Sum(file), sha1.Sum(file), sha256.Sum256(file)
+	tests := []struct {
+		algorithm string
+		digest    []byte
+		chunks    []int
+	}{
+		{"md5", md5Sum[:], []int{len(file)}},
+		{"sha1", sha1Sum[:], []int{10, 20, len(file) - 30}},
+		{"sha256", sha256Sum[:], []int{1, len(file) - 2, 1}},
+	}
+	for _, test := range tests {
+		t.Run(test.algorithm, func(t *testing.T) {
+			upload := database.Upload{Checksum: test.algorithm + " " + base64.StdEncoding.EncodeToString(test.digest)}
+			offset := 0
+			for _, size := range test.chunks {
+				_, h, err := fileHash(upload)
+				if err != nil {
+					t.Fatal(err)
+				}
+				h.Write(file[offset : offset+size])
+				offset += size
+				if upload.Hashstate, err = h.(encoding.BinaryMarshaler).MarshalBinary(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected, h, err := fileHash(upload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := expected.verify(h); err != nil {
+				t.Error(err)
+			}
+
+			// a chunk received twice no longer matches
+			h.Write(file[:1])
+			if err := expected.verify(h); !errors.Is(err, ErrUploadChecksum) {
+				t.Errorf("got %v, want %v", err, ErrUploadChecksum)
+			}
+		})
+	}
+
+	if _, h, err := fileHash(database.Upload{}); h != nil || err != nil {
+		t.Errorf("an upload without checksum has the hash %v and error %v", h, err)
+	}
+}
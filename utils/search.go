@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"context"
+	"main/analytics"
+	"main/database"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SearchOptions selects how Search ranks and groups the matching sentences
+type SearchOptions struct {
+	// GroupByDocument returns one hit per document instead of one per sentence
+	GroupByDocument bool
+	// K is the number of sentences kept per document, 0 keeps them all
+	K int
+	// Limit is the number of hits returned, 0 returns them all
+	Limit int
+}
+
+// ScoredSentence is a matching sentence with its BM25 score
+type ScoredSentence struct {
+	database.Sentence
+	Score float64
+}
+
+// SearchHit is a document, with its BM25 score, and its best matching sentences.
+// When the hits are not grouped by document the score is that of the single sentence.
+type SearchHit struct {
+	Record    database.Record
+	Score     float64
+	Sentences []ScoredSentence
+}
+
+// maxSearchCandidates bounds the sentences matching the pattern that are loaded and scored
+const maxSearchCandidates = 5000
+
+// searchPattern matches the words starting with any of the prefixes, case insensitively. The
+// sentences it finds are checked against the exact terms, it only narrows the scan.
+func searchPattern(prefixes []string) string {
+	patterns := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		patterns[i] = analytics.Pattern(prefix)
+	}
+	return `(?:` + strings.Join(patterns, "|") + `)`
+}
+
+// stemPrefix is the part of a word its stem starts with. Stemmers may rewrite the end of a word,
+// "happy" becomes "happi", so the stem itself is not always found in the sentences.
+func stemPrefix(word, stem string) string {
+	if strings.Contains(word, stem) {
+		return stem
+	}
+	i := 0
+	for i < len(word) && i < len(stem) && word[i] == stem[i] {
+		i++
+	}
+	for i > 0 && !utf8.RuneStart(word[i]) {
+		i--
+	}
+	if i == 0 {
+		return word
+	}
+	return word[:i]
+}
+
+// Search ranks the sentences containing any word of query, and their documents, with BM25.
+// The query is stemmed for the language of every document. It returns the hits, best first,
+// and the number of matching sentences.
+func Search(ctx context.Context, query string, options SearchOptions) ([]SearchHit, int, error) {
+	words := uniqueTerms(analytics.Tokenize(query))
+	if len(words) == 0 {
+		return []SearchHit{}, 0, nil
+	}
+
+	corpus, err := CorpusStats(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	queries := getQueries(ctx)
+	languages, err := queries.ListRecordLanguages(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	termsByLanguage := map[string][]string{}
+	allTerms, prefixes := []string{}, []string{}
+	for _, language := range languages {
+		language = orDefaultLanguage(language)
+		if _, ok := termsByLanguage[language]; ok {
+			continue
+		}
+		terms := make([]string, len(words))
+		for i, word := range words {
+			terms[i] = analytics.Stem(word, language)
+			allTerms = append(allTerms, terms[i])
+			prefixes = append(prefixes, stemPrefix(word, terms[i]))
+		}
+		termsByLanguage[language] = uniqueTerms(terms)
+	}
+	if len(allTerms) == 0 {
+		return []SearchHit{}, 0, nil
+	}
+
+	postings, err := queries.ListTermDocuments(ctx, uniqueTerms(allTerms))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	documentCounts := map[string]int{}
+	documentScores := map[int32]float64{}
+	for _, posting := range postings {
+		documentCounts[posting.Term] = int(posting.DocumentCount)
+		language := orDefaultLanguage(posting.Language)
+		if contains(termsByLanguage[language], posting.Term) {
+			documentScores[posting.Pdfid] += analytics.BM25(int(posting.Count), int(posting.TermCount), corpus, int(posting.DocumentCount))
+		}
+	}
+
+	// the candidates are taken from the best documents first, a sentence can only match the
+	// terms indexed for its document
+	sentences, err := queries.SearchSentences(ctx, database.SearchSentencesParams{
+		Pattern:      searchPattern(uniqueTerms(prefixes)),
+		Ids:          rankDocuments(documentScores),
+		MaxSentences: maxSearchCandidates,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := []int32{}
+	for _, sentence := range sentences {
+		if len(ids) == 0 || ids[len(ids)-1] != sentence.Pdfid {
+			ids = append(ids, sentence.Pdfid)
+		}
+	}
+	records, err := queries.ListRecordsByID(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	recordsByID := make(map[int32]database.Record, len(records))
+	for _, record := range records {
+		recordsByID[record.ID] = record
+	}
+
+	sentenceCorpus := analytics.CorpusStats{Documents: corpus.Documents, AverageLength: corpus.AverageSentenceLength}
+	bySentence := []ScoredSentence{}
+	byDocument := map[int32][]ScoredSentence{}
+	for _, sentence := range sentences {
+		record, ok := recordsByID[sentence.Pdfid]
+		if !ok {
+			continue
+		}
+		words := analytics.Terms(sentence.Sentence, RecordLanguage(record))
+		counts := map[string]int{}
+		for _, word := range words {
+			counts[word]++
+		}
+
+		score := 0.0
+		for _, term := range termsByLanguage[RecordLanguage(record)] {
+			if counts[term] > 0 {
+				score += analytics.BM25(counts[term], len(words), sentenceCorpus, documentCounts[term])
+			}
+		}
+		if score == 0 {
+			continue
+		}
+
+		scored := ScoredSentence{Sentence: sentence, Score: score}
+		bySentence = append(bySentence, scored)
+		byDocument[sentence.Pdfid] = append(byDocument[sentence.Pdfid], scored)
+	}
+	sortSentences(bySentence)
+
+	hits := []SearchHit{}
+	if options.GroupByDocument {
+		for _, record := range records {
+			matches := byDocument[record.ID]
+			if len(matches) == 0 {
+				continue
+			}
+			sortSentences(matches)
+			if options.K > 0 && len(matches) > options.K {
+				matches = matches[:options.K]
+			}
+			hits = append(hits, SearchHit{Record: record, Score: documentScores[record.ID], Sentences: matches})
+		}
+		sort.SliceStable(hits, func(i, j int) bool {
+			if hits[i].Score != hits[j].Score {
+				return hits[i].Score > hits[j].Score
+			}
+			return hits[i].Sentences[0].Score > hits[j].Sentences[0].Score
+		})
+	} else {
+		for _, sentence := range bySentence {
+			hits = append(hits, SearchHit{Record: recordsByID[sentence.Pdfid], Score: sentence.Score, Sentences: []ScoredSentence{sentence}})
+		}
+	}
+
+	if options.Limit > 0 && len(hits) > options.Limit {
+		hits = hits[:options.Limit]
+	}
+	return hits, len(bySentence), nil
+}
+
+// rankDocuments orders the ids of the documents by decreasing score, then by id
+func rankDocuments(scores map[int32]float64) []int32 {
+	ids := make([]int32, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueTerms(words []string) []string {
+	seen := map[string]bool{}
+	terms := []string{}
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// sortSentences orders sentences by decreasing score, then by position
+func sortSentences(sentences []ScoredSentence) {
+	sort.Slice(sentences, func(i, j int) bool {
+		if sentences[i].Score != sentences[j].Score {
+			return sentences[i].Score > sentences[j].Score
+		}
+		if sentences[i].Pdfid != sentences[j].Pdfid {
+			return sentences[i].Pdfid < sentences[j].Pdfid
+		}
+		return sentences[i].ID < sentences[j].ID
+	})
+}
+
+// FindOccurrences counts keyword, as a phrase of normalized words, in the sentences and returns
+// the indexes of the sentences containing it. Hyphenated keywords match the words either side of the hyphen.
+func FindOccurrences(sentences []database.Sentence, keyword string) (int, []int) {
+	query := analytics.Tokenize(keyword)
+	count := 0
+	foundIn := []int{}
+	if len(query) == 0 {
+		return count, foundIn
+	}
+
+	for index, sentence := range sentences {
+		words := analytics.Tokenize(sentence.Sentence)
+		found := false
+		for i := 0; i+len(query) <= len(words); i++ {
+			matched := true
+			for j, word := range query {
+				if words[i+j] != word {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				count++
+				found = true
+			}
+		}
+		if found {
+			foundIn = append(foundIn, index)
+		}
+	}
+
+	return count, foundIn
+}
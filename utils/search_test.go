@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"main/database"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestSearchPattern matches the pattern, as Postgres would, against the starts of words only and
+// quotes the characters of the prefixes
+func TestSearchPattern(t *testing.T) {
+	// Postgres marks the start of words with \m, Go with \b
+	pattern := regexp.MustCompile(`(?i)` + strings.ReplaceAll(searchPattern([]string{"net", "e.g"}), `\m`, `\b`))
+	tests := []struct {
+		sentence string
+		want     bool
+	}{
+		{"The net was cast.", true},
+		{"NET income rose.", true},
+		{"The network was down.", true},
+		{"A planet.", false},
+		{"Written e.g. in Go.", true},
+		{"An egg was fresh.", false},
+	}
+	for _, test := range tests {
+		if got := pattern.MatchString(test.sentence); got != test.want {
+			t.Errorf("%q: got %v, want %v", test.sentence, got, test.want)
+		}
+	}
+}
+
+// TestStemPrefix finds the sentences of the words whose stem is not a prefix of the word
+func TestStemPrefix(t *testing.T) {
+	tests := []struct {
+		word, stem string
+		want       string
+	}{
+		{"running", "run", "run"},
+		{"happy", "happi", "happ"},
+		{"studies", "studi", "studi"},
+		{"went", "go", "went"},
+		{"café", "cafè", "caf"},
+	}
+	for _, test := range tests {
+		if got := stemPrefix(test.word, test.stem); got != test.want {
+			t.Errorf("stemPrefix(%q, %q) = %q, want %q", test.word, test.stem, got, test.want)
+		}
+	}
+}
+
+// TestSortSentences orders by decreasing score, then by document and sentence
+func TestSortSentences(t *testing.T) {
+	sentences := []ScoredSentence{
+		{Sentence: database.Sentence{ID: 4, Pdfid: 2}, Score: 1},
+		{Sentence: database.Sentence{ID: 3, Pdfid: 2}, Score: 1},
+		{Sentence: database.Sentence{ID: 9, Pdfid: 1}, Score: 1},
+		{Sentence: database.Sentence{ID: 1, Pdfid: 3}, Score: 2.5},
+	}
+	sortSentences(sentences)
+
+	got := make([]int32, len(sentences))
+	for i, sentence := range sentences {
+		got[i] = sentence.ID
+	}
+	if want := []int32{1, 9, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestRankDocuments orders the candidate documents by decreasing score, then by id
+func TestRankDocuments(t *testing.T) {
+	got := rankDocuments(map[int32]float64{7: 0.5, 3: 2, 5: 0.5, 1: 1.25})
+	if want := []int32{3, 1, 5, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := rankDocuments(nil); got == nil || len(got) != 0 {
+		t.Errorf("got %v, want an empty list", got)
+	}
+}
+
+// TestUniqueTerms keeps the first occurrence of every term, in order
+func TestUniqueTerms(t *testing.T) {
+	got := uniqueTerms([]string{"pdf", "search", "pdf", "rank", "search"})
+	if want := []string{"pdf", "search", "rank"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := uniqueTerms(nil); got == nil || len(got) != 0 {
+		t.Errorf("got %v, want an empty list", got)
+	}
+}
@@ -0,0 +1,245 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"main/analytics"
+	"main/database"
+	"sort"
+	"sync"
+)
+
+// ErrSentenceNotFound is returned when looking up the neighbors of an unknown sentence
+var ErrSentenceNotFound = errors.New("sentence not found")
+
+// Neighbor is a record or sentence similar to the one looked up
+type Neighbor struct {
+	ID    int32
+	Score float64
+}
+
+type sentenceEmbedding struct {
+	sentence  database.Sentence
+	embedding analytics.Embedding
+}
+
+type documentEmbedding struct {
+	embedding analytics.Embedding
+	sentences []sentenceEmbedding
+}
+
+// similarityIndex keeps the embeddings of every record and sentence in memory. It is built from the
+// stored sentences on first use, then follows the uploads and deletions.
+type similarityIndex struct {
+	mu        sync.RWMutex
+	loaded    bool
+	documents map[int32]*documentEmbedding
+	sentences map[int32]*sentenceEmbedding
+}
+
+var similarities = &similarityIndex{documents: map[int32]*documentEmbedding{}, sentences: map[int32]*sentenceEmbedding{}}
+
+func embedDocument(language string, sentences []database.Sentence) *documentEmbedding {
+	document := &documentEmbedding{sentences: make([]sentenceEmbedding, 0, len(sentences))}
+	texts := make([]string, 0, len(sentences))
+	for _, sentence := range sentences {
+		texts = append(texts, sentence.Sentence)
+		document.sentences = append(document.sentences, sentenceEmbedding{
+			sentence:  sentence,
+			embedding: analytics.Embed([]string{sentence.Sentence}, language),
+		})
+	}
+	document.embedding = analytics.Embed(texts, language)
+	return document
+}
+
+// put replaces the embeddings of a record, the caller holds the lock
+func (s *similarityIndex) put(id int32, document *documentEmbedding) {
+	s.remove(id)
+	s.documents[id] = document
+	for i := range document.sentences {
+		s.sentences[document.sentences[i].sentence.ID] = &document.sentences[i]
+	}
+}
+
+// remove drops the embeddings of a record, the caller holds the lock
+func (s *similarityIndex) remove(id int32) {
+	document, ok := s.documents[id]
+	if !ok {
+		return
+	}
+	for _, sentence := range document.sentences {
+		delete(s.sentences, sentence.sentence.ID)
+	}
+	delete(s.documents, id)
+}
+
+// load builds the index from the stored sentences unless it is already built
+func (s *similarityIndex) load(ctx context.Context) error {
+	s.mu.RLock()
+	loaded := s.loaded
+	s.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	// the lock is held while loading so uploads and deletions wait for the index instead of racing it
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded {
+		return nil
+	}
+
+	queries := getQueries(ctx)
+	records, err := queries.ListRecords(ctx)
+	if err != nil {
+		return err
+	}
+	languages := make(map[int32]string, len(records))
+	for _, record := range records {
+		languages[record.ID] = RecordLanguage(record)
+	}
+
+	sentences, err := queries.ListSentences(ctx)
+	if err != nil {
+		return err
+	}
+
+	byRecord := map[int32][]database.Sentence{}
+	for _, sentence := range sentences {
+		byRecord[sentence.Pdfid] = append(byRecord[sentence.Pdfid], sentence)
+	}
+	for id, recordSentences := range byRecord {
+		sort.Slice(recordSentences, func(i, j int) bool { return recordSentences[i].ID < recordSentences[j].ID })
+		language, ok := languages[id]
+		if !ok {
+			language = analytics.DefaultLanguage
+		}
+		s.put(id, embedDocument(language, recordSentences))
+	}
+	s.loaded = true
+	return nil
+}
+
+// AddSimilarities adds the sentences of a newly stored record to the similarity index
+func AddSimilarities(id int32, language string, sentences []database.Sentence) {
+	document := embedDocument(language, sentences)
+
+	similarities.mu.Lock()
+	defer similarities.mu.Unlock()
+	if similarities.loaded {
+		similarities.put(id, document)
+	}
+}
+
+// RemoveSimilarities drops a deleted record from the similarity index
+func RemoveSimilarities(id int32) {
+	similarities.mu.Lock()
+	defer similarities.mu.Unlock()
+	similarities.remove(id)
+}
+
+// rank keeps the k best scoring neighbors, best first
+func rank(neighbors []Neighbor, k int) []Neighbor {
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].Score != neighbors[j].Score {
+			return neighbors[i].Score > neighbors[j].Score
+		}
+		return neighbors[i].ID < neighbors[j].ID
+	})
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	return neighbors
+}
+
+// SimilarDocument is a record and its similarity to the one looked up
+type SimilarDocument struct {
+	Record database.Record
+	Score  float64
+}
+
+// SimilarDocuments returns the k records most similar to a record, by the cosine similarity of their embeddings
+func SimilarDocuments(ctx context.Context, id int32, k int) ([]SimilarDocument, error) {
+	if err := similarities.load(ctx); err != nil {
+		return nil, err
+	}
+
+	similarities.mu.RLock()
+	neighbors := []Neighbor{}
+	target, found := similarities.documents[id]
+	if found {
+		for other, document := range similarities.documents {
+			if other == id {
+				continue
+			}
+			if score := target.embedding.Similarity(document.embedding); score > 0 {
+				neighbors = append(neighbors, Neighbor{ID: other, Score: score})
+			}
+		}
+	}
+	similarities.mu.RUnlock()
+
+	queries := getQueries(ctx)
+	if !found {
+		// records without sentences are not indexed, unknown ones are reported
+		if _, err := queries.GetRecord(ctx, id); err != nil {
+			return nil, err
+		}
+		return []SimilarDocument{}, nil
+	}
+
+	neighbors = rank(neighbors, k)
+	ids := make([]int32, len(neighbors))
+	for i, neighbor := range neighbors {
+		ids[i] = neighbor.ID
+	}
+	records, err := queries.ListRecordsByID(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int32]database.Record, len(records))
+	for _, record := range records {
+		byID[record.ID] = record
+	}
+	result := make([]SimilarDocument, 0, len(neighbors))
+	for _, neighbor := range neighbors {
+		if record, ok := byID[neighbor.ID]; ok {
+			result = append(result, SimilarDocument{Record: record, Score: neighbor.Score})
+		}
+	}
+	return result, nil
+}
+
+// SimilarSentences returns the k sentences most similar to a sentence, optionally only from other records
+func SimilarSentences(ctx context.Context, id int32, k int, otherDocuments bool) ([]ScoredSentence, error) {
+	if err := similarities.load(ctx); err != nil {
+		return nil, err
+	}
+
+	similarities.mu.RLock()
+	defer similarities.mu.RUnlock()
+
+	target, ok := similarities.sentences[id]
+	if !ok {
+		return nil, ErrSentenceNotFound
+	}
+
+	neighbors := []Neighbor{}
+	for other, sentence := range similarities.sentences {
+		if other == id || (otherDocuments && sentence.sentence.Pdfid == target.sentence.Pdfid) {
+			continue
+		}
+		if score := target.embedding.Similarity(sentence.embedding); score > 0 {
+			neighbors = append(neighbors, Neighbor{ID: other, Score: score})
+		}
+	}
+
+	neighbors = rank(neighbors, k)
+	result := make([]ScoredSentence, len(neighbors))
+	for i, neighbor := range neighbors {
+		result[i] = ScoredSentence{Sentence: similarities.sentences[neighbor.ID].sentence, Score: neighbor.Score}
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"main/database"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func indexedSentences(s *similarityIndex) []int32 {
+	ids := []int32{}
+	for id := range s.sentences {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// TestSimilarityIndexPutRemove replaces the sentences of a record put again and drops them with
+// the record, leaving the other records alone
+func TestSimilarityIndexPutRemove(t *testing.T) {
+	s := &similarityIndex{documents: map[int32]*documentEmbedding{}, sentences: map[int32]*sentenceEmbedding{}}
+	s.put(1, embedDocument("en", []database.Sentence{
+		{ID: 10, Pdfid: 1, Sentence: "The contract was signed in Paris."},
+		{ID: 11, Pdfid: 1, Sentence: "Both parties agreed to the terms."},
+	}))
+	s.put(2, embedDocument("en", []database.Sentence{
+		{ID: 20, Pdfid: 2, Sentence: "The invoice is due in thirty days."},
+	}))
+	if got, want := indexedSentences(s), []int32{10, 11, 20}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after put: got sentences %v, want %v", got, want)
+	}
+
+	// a record extracted again replaces its sentences
+	s.put(1, embedDocument("en", []database.Sentence{
+		{ID: 12, Pdfid: 1, Sentence: "The contract was signed in Paris by both parties."},
+	}))
+	if got, want := indexedSentences(s), []int32{12, 20}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after put again: got sentences %v, want %v", got, want)
+	}
+	if got := s.sentences[12].sentence.Sentence; got != "The contract was signed in Paris by both parties." {
+		t.Errorf("got sentence %q for id 12", got)
+	}
+
+	s.remove(1)
+	if got, want := indexedSentences(s), []int32{20}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after remove: got sentences %v, want %v", got, want)
+	}
+	if _, ok := s.documents[1]; ok {
+		t.Error("after remove: record 1 is still indexed")
+	}
+
+	// removing an unknown record does nothing
+	s.remove(3)
+	if len(s.documents) != 1 || len(s.sentences) != 1 {
+		t.Errorf("after removing an unknown record: got %d records and %d sentences, want 1 and 1", len(s.documents), len(s.sentences))
+	}
+}
+
+// TestRank keeps the k best neighbors, the lower id first on equal scores
+func TestRank(t *testing.T) {
+	neighbors := []Neighbor{{ID: 4, Score: 0.2}, {ID: 2, Score: 0.9}, {ID: 3, Score: 0.5}, {ID: 1, Score: 0.5}}
+	got := rank(neighbors, 3)
+	want := []Neighbor{{ID: 2, Score: 0.9}, {ID: 1, Score: 0.5}, {ID: 3, Score: 0.5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
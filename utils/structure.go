@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"main/database"
+	"main/extract"
+	"main/metrics"
+	"main/tracing"
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Section is a heading of a document with its paragraphs and subsections. The text before the
+// first heading goes in an untitled section of level 0.
+type Section struct {
+	Title      string
+	Level      int32
+	Page       int32
+	Paragraphs []Paragraph
+	Sections   []*Section
+}
+
+// Paragraph is a block of body text with its bounding box in points and its sentences
+type Paragraph struct {
+	ID        int32
+	Page      int32
+	Box       extract.Box
+	Sentences []database.Sentence
+}
+
+// StructureBlocks returns the blocks of a document, the pages recognized with OCR replaced by a
+// single paragraph of their recognized text
+func StructureBlocks(blocks []extract.Block, pages []string, recognized []PageText) []extract.Block {
+	byPage := make([][]extract.Block, len(pages))
+	for _, block := range blocks {
+		if block.Page >= 1 && block.Page <= len(pages) {
+			byPage[block.Page-1] = append(byPage[block.Page-1], block)
+		}
+	}
+	for _, page := range recognized {
+		if page.OCR && page.Page >= 1 && page.Page <= len(pages) {
+			byPage[page.Page-1] = []extract.Block{{Page: page.Page, Text: pages[page.Page-1]}}
+		}
+	}
+
+	result := make([]extract.Block, 0, len(blocks))
+	for _, page := range byPage {
+		result = append(result, page...)
+	}
+	return result
+}
+
+// StoreStructure stores the sections and paragraphs of a record from the blocks of its layout
+// and the sentences of every heading and paragraph, in reading order
+func StoreStructure(ctx context.Context, insertedRecord database.Record, blocks []extract.Block) ([]database.Sentence, error) {
+	observeSplit := metrics.ObserveStage(metrics.StageSplit)
+	_, span := tracing.Start(ctx, "prose.split", attribute.Int("blocks", len(blocks)))
+	blockSentences := make([][]prose.Sentence, len(blocks))
+	count := 0
+	for i, block := range blocks {
+		doc, err := prose.NewDocument(block.Text, prose.WithTagging(false), prose.WithExtraction(false))
+		if err != nil {
+			tracing.End(span, err)
+			return nil, err
+		}
+		blockSentences[i] = doc.Sentences()
+		count += len(blockSentences[i])
+	}
+	span.SetAttributes(attribute.Int("sentences", count))
+	tracing.End(span, nil)
+	observeSplit()
+
+	defer metrics.ObserveStage(metrics.StageStore)()
+	queries := getQueries(ctx)
+
+	// open sections, outermost first
+	open := []database.Section{}
+	stored := make([]database.Sentence, 0, count)
+	for i, block := range blocks {
+		if block.Heading > 0 {
+			for len(open) > 0 && open[len(open)-1].Level >= int32(block.Heading) {
+				open = open[:len(open)-1]
+			}
+			section, err := createSection(ctx, queries, insertedRecord.ID, open, int32(block.Heading), block.Text, int32(block.Page))
+			if err != nil {
+				return nil, err
+			}
+			open = append(open, section)
+			// the headings are searched like the text, outside of any paragraph
+			stored, err = storeSentences(ctx, queries, stored, insertedRecord.ID, int32(block.Page), sql.NullInt32{}, blockSentences[i])
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if strings.TrimSpace(block.Text) == "" {
+			continue
+		}
+
+		if len(open) == 0 {
+			section, err := createSection(ctx, queries, insertedRecord.ID, open, 0, "", int32(block.Page))
+			if err != nil {
+				return nil, err
+			}
+			open = append(open, section)
+		}
+		paragraph, err := queries.CreateParagraph(ctx, database.CreateParagraphParams{
+			Pdfid:     insertedRecord.ID,
+			Sectionid: open[len(open)-1].ID,
+			Page:      int32(block.Page),
+			X0:        block.Box.X0,
+			Y0:        block.Box.Y0,
+			X1:        block.Box.X1,
+			Y1:        block.Box.Y1,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		stored, err = storeSentences(ctx, queries, stored, insertedRecord.ID, int32(block.Page), sql.NullInt32{Int32: paragraph.ID, Valid: true}, blockSentences[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stored, nil
+}
+
+// storeSentences stores the sentences of a block and appends them to stored
+func storeSentences(ctx context.Context, queries *database.Queries, stored []database.Sentence, id int32, page int32, paragraph sql.NullInt32, sentences []prose.Sentence) ([]database.Sentence, error) {
+	for _, sentence := range sentences {
+		created, err := queries.CreateSentence(ctx, database.CreateSentenceParams{
+			Sentence:    sentence.Text,
+			Pdfid:       id,
+			Page:        page,
+			Paragraphid: paragraph,
+		})
+		if err != nil {
+			return nil, err
+		}
+		stored = append(stored, created)
+		metrics.SentencesProcessed.Inc()
+	}
+	return stored, nil
+}
+
+func createSection(ctx context.Context, queries *database.Queries, id int32, open []database.Section, level int32, title string, page int32) (database.Section, error) {
+	params := database.CreateSectionParams{Pdfid: id, Level: level, Title: title, Page: page}
+	if len(open) > 0 {
+		params.Parentid = sql.NullInt32{Int32: open[len(open)-1].ID, Valid: true}
+	}
+	return queries.CreateSection(ctx, params)
+}
+
+// GetStructure returns the top level sections of a record, nil when it was extracted without its layout
+func GetStructure(ctx context.Context, id int32) ([]*Section, error) {
+	queries := getQueries(ctx)
+	sections, err := queries.ListRecordSections(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(sections) == 0 {
+		return nil, nil
+	}
+	paragraphs, err := queries.ListRecordParagraphs(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sentences, err := queries.ListRecordSentences(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	byParagraph := map[int32][]database.Sentence{}
+	for _, sentence := range sentences {
+		if sentence.Paragraphid.Valid {
+			byParagraph[sentence.Paragraphid.Int32] = append(byParagraph[sentence.Paragraphid.Int32], sentence)
+		}
+	}
+
+	// sections are listed in creation order, parents before their children
+	byID := make(map[int32]*Section, len(sections))
+	roots := []*Section{}
+	for _, row := range sections {
+		section := &Section{Title: row.Title, Level: row.Level, Page: row.Page, Paragraphs: []Paragraph{}, Sections: []*Section{}}
+		byID[row.ID] = section
+		if parent, ok := byID[row.Parentid.Int32]; row.Parentid.Valid && ok {
+			parent.Sections = append(parent.Sections, section)
+		} else {
+			roots = append(roots, section)
+		}
+	}
+	for _, row := range paragraphs {
+		section, ok := byID[row.Sectionid]
+		if !ok {
+			continue
+		}
+		section.Paragraphs = append(section.Paragraphs, Paragraph{
+			ID:        row.ID,
+			Page:      row.Page,
+			Box:       extract.Box{X0: row.X0, Y0: row.Y0, X1: row.X1, Y1: row.Y1},
+			Sentences: byParagraph[row.ID],
+		})
+	}
+	return roots, nil
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+	"main/analytics"
+	"main/database"
+	"main/metrics"
+	"main/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SummaryLength is the length of a summary, either a number of sentences or a percentage of the document
+type SummaryLength struct {
+	Sentences int
+	Percent   int
+}
+
+// size returns the number of sentences of the summary of a document of total sentences
+func (l SummaryLength) size(total int) int {
+	if l.Percent > 0 {
+		size := (total*l.Percent + 99) / 100
+		if size < 1 {
+			size = 1
+		}
+		return size
+	}
+	return l.Sentences
+}
+
+// StoreSummary scores how central every sentence of a record is, the summaries are read from these scores
+func StoreSummary(ctx context.Context, id int32, language string, sentences []database.Sentence) error {
+	defer metrics.ObserveStage(metrics.StageSummary)()
+	_, span := tracing.Start(ctx, "summary.score", attribute.Int("sentences", len(sentences)))
+
+	texts := make([]string, len(sentences))
+	ids := make([]int32, len(sentences))
+	for i, sentence := range sentences {
+		texts[i] = sentence.Sentence
+		ids[i] = sentence.ID
+	}
+	scores := analytics.SentenceScores(texts, language)
+	tracing.End(span, nil)
+
+	if len(ids) == 0 {
+		return nil
+	}
+	return getQueries(ctx).CreateSummaryScores(ctx, database.CreateSummaryScoresParams{
+		SentenceIds: ids,
+		Pdfid:       id,
+		Scores:      scores,
+	})
+}
+
+// Summary returns the most central sentences of a record in their original order, and the
+// number of sentences of the record. Records stored before the summaries existed are scored on the first call.
+func Summary(ctx context.Context, id int32, length SummaryLength) ([]ScoredSentence, int, error) {
+	queries := getQueries(ctx)
+	total, err := queries.CountSummaryScores(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		record, err := queries.GetRecord(ctx, id)
+		if err != nil {
+			return nil, 0, err
+		}
+		sentences, err := queries.ListRecordSentences(ctx, id)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(sentences) == 0 {
+			return []ScoredSentence{}, 0, nil
+		}
+		if err := StoreSummary(ctx, id, RecordLanguage(record), sentences); err != nil {
+			return nil, 0, err
+		}
+		total = int64(len(sentences))
+	}
+
+	rows, err := queries.ListSummarySentences(ctx, database.ListSummarySentencesParams{
+		Pdfid: id,
+		Size:  int32(length.size(int(total))),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sentences := make([]ScoredSentence, 0, len(rows))
+	for _, row := range rows {
+		sentences = append(sentences, ScoredSentence{
+			Sentence: database.Sentence{ID: row.ID, Sentence: row.Sentence, Pdfid: row.Pdfid, Page: row.Page},
+			Score:    row.Score,
+		})
+	}
+	return sentences, int(total), nil
+}
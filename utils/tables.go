@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"main/database"
+	"main/extract"
+)
+
+// StoreTables stores the tables found in a record with the text of their cells
+func StoreTables(ctx context.Context, id int32, tables []extract.Table) error {
+	queries := getQueries(ctx)
+	for _, table := range tables {
+		created, err := queries.CreateTable(ctx, database.CreateTableParams{
+			Pdfid:        id,
+			Page:         int32(table.Page),
+			X0:           table.Box.X0,
+			Y0:           table.Box.Y0,
+			X1:           table.Box.X1,
+			Y1:           table.Box.Y1,
+			Numofrows:    int32(len(table.Rows)),
+			Numofcolumns: int32(len(table.Rows[0])),
+		})
+		if err != nil {
+			return err
+		}
+
+		params := database.CreateTableCellsParams{Tableid: created.ID}
+		for i, row := range table.Rows {
+			for j, text := range row {
+				params.Rows = append(params.Rows, int32(i))
+				params.Columns = append(params.Columns, int32(j))
+				params.Texts = append(params.Texts, text)
+			}
+		}
+		if err := queries.CreateTableCells(ctx, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListRecordTables returns the tables of a record in the order they were found
+func ListRecordTables(ctx context.Context, id int32) ([]database.DocumentTable, error) {
+	return getQueries(ctx).ListRecordTables(ctx, id)
+}
+
+// GetTable returns a table of a record and the text of its cells, row by row.
+// pgx.ErrNoRows is returned when the record has no such table.
+func GetTable(ctx context.Context, id int32, tableID int32) (database.DocumentTable, [][]string, error) {
+	queries := getQueries(ctx)
+	table, err := queries.GetTable(ctx, database.GetTableParams{ID: tableID, Pdfid: id})
+	if err != nil {
+		return table, nil, err
+	}
+	cells, err := queries.ListTableCells(ctx, table.ID)
+	if err != nil {
+		return table, nil, err
+	}
+
+	rows := make([][]string, table.Numofrows)
+	for i := range rows {
+		rows[i] = make([]string, table.Numofcolumns)
+	}
+	for _, cell := range cells {
+		if cell.Rowindex < table.Numofrows && cell.Columnindex < table.Numofcolumns {
+			rows[cell.Rowindex][cell.Columnindex] = cell.Text
+		}
+	}
+	return table, rows, nil
+}
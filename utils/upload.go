@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/extract"
+	"main/logging"
+	"main/metrics"
+	"main/tracing"
+	"mime"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Policies for the pdfs that are encrypted or carry JavaScript
+const (
+	PolicyAllow  = "allow"
+	PolicyWarn   = "warn"
+	PolicyReject = "reject"
+)
+
+// UploadPolicy bounds the files accepted for ingestion
+type UploadPolicy struct {
+	// MaxSize is the size in bytes over which a file is rejected
+	MaxSize int64
+	// MaxPages is the number of pages over which a document is rejected, 0 for no limit
+	MaxPages int
+	// Encrypted applies to the pdfs that open without a password, the others cannot be read anyway
+	Encrypted  string
+	JavaScript string
+	// MaxBatchSize bounds the bytes of the files of a batch, those of its archives once expanded
+	MaxBatchSize int64
+	// MaxBatchFiles bounds the files of a batch, the entries of its archives included
+	MaxBatchFiles int
+	// BatchWorkers is the number of files of a batch, and of resumable uploads received, ingested
+	// at once
+	BatchWorkers int
+}
+
+// DefaultUploadPolicy accepts files of up to 100 MiB with any number of pages and the encrypted
+// pdfs that open without a password, but not the pdfs carrying JavaScript. A batch holds up to
+// 500 files and 512 MiB, 4 of its files ingested at once.
+var DefaultUploadPolicy = UploadPolicy{
+	MaxSize:       100 << 20,
+	Encrypted:     PolicyAllow,
+	JavaScript:    PolicyReject,
+	MaxBatchSize:  512 << 20,
+	MaxBatchFiles: 500,
+	BatchWorkers:  4,
+}
+
+var uploadPolicy = DefaultUploadPolicy
+
+// Reasons an upload is rejected, matched with errors.Is
+var (
+	ErrUploadEmpty        = errors.New("empty file")
+	ErrUploadTooLarge     = errors.New("file too large")
+	ErrUploadTooManyPages = errors.New("too many pages")
+	ErrUploadMismatch     = errors.New("content does not match the declared type")
+	ErrUploadEncrypted    = errors.New("encrypted pdf")
+	ErrUploadJavaScript   = errors.New("pdf with javascript")
+)
+
+// SetUploadPolicy sets the limits of the uploads, the defaults for the sizes, numbers of files and
+// workers that are not positive
+func SetUploadPolicy(policy UploadPolicy) error {
+	for _, value := range []string{policy.Encrypted, policy.JavaScript} {
+		switch value {
+		case PolicyAllow, PolicyWarn, PolicyReject:
+		default:
+			return fmt.Errorf("unknown upload policy %q, expected allow, warn or reject", value)
+		}
+	}
+	if policy.MaxSize <= 0 {
+		policy.MaxSize = DefaultUploadPolicy.MaxSize
+	}
+	if policy.MaxPages < 0 {
+		policy.MaxPages = 0
+	}
+	if policy.MaxBatchSize <= 0 {
+		policy.MaxBatchSize = DefaultUploadPolicy.MaxBatchSize
+	}
+	if policy.MaxBatchFiles <= 0 {
+		policy.MaxBatchFiles = DefaultUploadPolicy.MaxBatchFiles
+	}
+	if policy.BatchWorkers <= 0 {
+		policy.BatchWorkers = DefaultUploadPolicy.BatchWorkers
+	}
+	uploadPolicy = policy
+	return nil
+}
+
+// GetUploadPolicy returns the limits of the uploads
+func GetUploadPolicy() UploadPolicy {
+	return uploadPolicy
+}
+
+// MaxBodySize is the size of the largest upload the policy accepts, a file or a batch
+func (p UploadPolicy) MaxBodySize() int64 {
+	if p.MaxBatchSize > p.MaxSize {
+		return p.MaxBatchSize
+	}
+	return p.MaxSize
+}
+
+// CheckUploadSize rejects the empty files and those larger than the policy allows
+func CheckUploadSize(size int64) error {
+	if size <= 0 {
+		return ErrUploadEmpty
+	}
+	if size > uploadPolicy.MaxSize {
+		return fmt.Errorf("%w: %d bytes, at most %d are accepted", ErrUploadTooLarge, size, uploadPolicy.MaxSize)
+	}
+	return nil
+}
+
+// CheckPageCount rejects the documents with more pages than the policy allows
+func CheckPageCount(pages int) error {
+	if uploadPolicy.MaxPages > 0 && pages > uploadPolicy.MaxPages {
+		return fmt.Errorf("%w: %d pages, at most %d are accepted", ErrUploadTooManyPages, pages, uploadPolicy.MaxPages)
+	}
+	return nil
+}
+
+// CheckDeclaredType rejects the files named or sent as pdfs whose content is not one. The other
+// formats are only known from their content.
+func CheckDeclaredType(filename string, contentType string, mimeType string) error {
+	declared, _, _ := mime.ParseMediaType(contentType)
+	if mimeType != extract.MIMEPDF && (declared == extract.MIMEPDF || strings.EqualFold(filepath.Ext(filename), ".pdf")) {
+		return fmt.Errorf("%w: sent as a pdf, the content is %s", ErrUploadMismatch, mimeType)
+	}
+	return nil
+}
+
+// InspectPDF validates the structure of an uploaded pdf and applies the policy to its pages,
+// encryption and scripts. The files pdfcpu cannot parse return an error matching extract.ErrUnreadable.
+func InspectPDF(ctx context.Context, path string) (inspection *extract.Inspection, err error) {
+	defer metrics.ObserveStage(metrics.StageValidate)()
+	ctx, span := tracing.Start(ctx, "pdfcpu.validate")
+	defer func() { tracing.End(span, err) }()
+
+	inspection, err = extract.Inspect(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.Int("pages", inspection.Pages),
+		attribute.Bool("encrypted", inspection.Encrypted),
+		attribute.Bool("javascript", inspection.JavaScript),
+	)
+
+	if err := CheckPageCount(inspection.Pages); err != nil {
+		return inspection, err
+	}
+	if inspection.Encrypted {
+		if err := applyPolicy(ctx, uploadPolicy.Encrypted, ErrUploadEncrypted); err != nil {
+			return inspection, err
+		}
+	}
+	if inspection.JavaScript {
+		if err := applyPolicy(ctx, uploadPolicy.JavaScript, ErrUploadJavaScript); err != nil {
+			return inspection, err
+		}
+	}
+	return inspection, nil
+}
+
+// applyPolicy returns reason when the policy rejects it, logging it when the policy warns about it
+func applyPolicy(ctx context.Context, policy string, reason error) error {
+	switch policy {
+	case PolicyReject:
+		return reason
+	case PolicyWarn:
+		logging.FromContext(ctx).WithField("reason", reason.Error()).Warn("accepted an upload the policy warns about")
+	}
+	return nil
+}
+
+// maxNameLength is the length of records.name
+const maxNameLength = 255
+
+// maxExtensionLength is the length over which the end of a name is not kept as its extension
+const maxExtensionLength = 16
+
+var unsafeName = regexp.MustCompile(`[^\pL\pN\pM ._()\[\]+-]+`)
+
+// SanitizeFilename returns a name safe as an object key and as a local file name: the base name of
+// the uploaded file, without its directories, control characters and other punctuation, at most
+// maxNameLength bytes long with its extension kept
+func SanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = unsafeName.ReplaceAllString(name, "_")
+	name = strings.Join(strings.Fields(name), " ")
+	// a leading dot hides a file and "." or ".." are directories
+	name = strings.Trim(name, " .")
+
+	if len(name) > maxNameLength {
+		extension := filepath.Ext(name)
+		if len(extension) > maxExtensionLength {
+			extension = ""
+		}
+		base := name[:maxNameLength-len(extension)]
+		for !utf8.ValidString(base) {
+			base = base[:len(base)-1]
+		}
+		name = strings.TrimRight(base, " .") + extension
+	}
+	if name == "" {
+		return "document"
+	}
+	return name
+}
@@ -1,132 +1,135 @@
 package utils
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"image/jpeg"
 	"io"
-	"log"
 	"main/database"
+	"main/extract"
+	"main/logging"
+	"main/metrics"
+	"main/storage"
+	"main/tracing"
 	"os"
-	"os/exec"
-	"regexp"
-	"strconv"
 	"strings"
 
-	"github.com/bbalet/stopwords"
-	"github.com/dslipak/pdf"
 	"github.com/gen2brain/go-fitz"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
 	"github.com/jdkato/prose/v2"
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func GetWelcome() fiber.Map {
-	return fiber.Map{
-		"To upload a PDF":                                        "POST /uploadPDF",
-		"To get a list of uploaded PDFs":                         "GET /listPDF",
-		"To get a list of sentences in a PDF":                    "GET /listSentences/:id",
-		"To search for the occurrences of a keyword in all PDFs": "GET /searchKeyword/:word",
-		"To download a PDF":                                      "GET /getPDF/:id",
-		"To get an image of a page in a PDF":                     "GET /getPDF/:id/:page",
-		"To check the number of occurrences of a word in a PDF":  "GET /getOccurrences/:id/:word",
-		"To get the top 5 occuring words in a PDF":               "GET /getMostOccurring/:id",
-	}
+var (
+	db      database.DBTX
+	store   storage.BlobStore
+	formats = extract.Formats(extract.Default)
+)
+
+var (
+	ErrStorage  = errors.New("minio")
+	ErrDatabase = errors.New("db")
+)
+
+// SetDB sets the connection every query helper goes through
+func SetDB(conn database.DBTX) {
+	db = conn
 }
 
-func getMinioClient() (*minio.Client, error) {
-	minioEndpoint := "minio:9000"
-	minioAccessKey := "minioadmin"
-	minioSecretKey := "minioadmin"
-	useSSL := false
+// SetExtractor sets how the text of the uploaded pdfs is read, the other formats have a single extractor
+func SetExtractor(textExtractor extract.Extractor) {
+	formats.Register(extract.MIMEPDF, textExtractor)
+}
 
-	// Initialize MinIO client
-	return minio.New(minioEndpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(minioAccessKey, minioSecretKey, ""),
-		Secure: useSSL,
-	})
+// SetStore sets the object storage where the uploaded files are kept
+func SetStore(blobStore storage.BlobStore) {
+	store = blobStore
 }
 
-func UploadPDF(ctx context.Context, bucketName string, objectName string, inFile io.Reader) error {
-	minioClient, err := getMinioClient()
-	if err != nil {
-		return err
+// UploadDocument stores an uploaded file with the content type of its format
+func UploadDocument(ctx context.Context, bucketName string, objectName string, inFile io.Reader, contentType string) error {
+	defer metrics.ObserveStage(metrics.StageUpload)()
+
+	return store.Put(ctx, bucketName, objectName, inFile, -1, contentType)
+}
+
+// txKey is the context key of the transaction the queries of a context run in
+type txKey struct{}
+
+// getQueries returns the queries of the transaction of the context, of the connection otherwise
+func getQueries(ctx context.Context) *database.Queries {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return database.New(tx)
 	}
+	return database.New(db)
+}
 
-	_, err = minioClient.PutObject(ctx, bucketName, objectName, inFile, -1, minio.PutObjectOptions{
-		ContentType: "application/pdf",
-	})
+// inTx runs fn in a transaction, the queries of its context going through it. The transaction
+// is committed when fn succeeds and rolled back otherwise.
+func inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	conn, ok := db.(tracing.Beginner)
+	if !ok {
+		return fmt.Errorf("%w: the connection does not start transactions", ErrDatabase)
+	}
+	tx, err := conn.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
 
-	return nil
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func getQueriesConnection(ctx context.Context) (*pgx.Conn, *database.Queries, error) {
-	urlExample := fmt.Sprintf("postgres://%s:%s@db:5432", os.Getenv("DB_USER"), os.Getenv("DB_NAME"))
-	conn, err := pgx.Connect(ctx, urlExample)
-	if err != nil {
-		return nil, nil, err
-	}
-	return conn, database.New(conn), nil
+// newObjectKey generates the key a file is stored under, the names of the documents are not unique
+func newObjectKey() string {
+	return "documents/" + uuid.NewString()
 }
 
-func CreateRecord(ctx context.Context, name string, pages int32, size int32) (database.Record, error) {
-	conn, queries, err := getQueriesConnection(ctx)
-	if err != nil {
-		return database.Record{}, err
-	}
-	defer conn.Close(ctx)
+// CreateRecord records a file stored under objectKey, extracted by extractor at the current ExtractionVersion
+func CreateRecord(ctx context.Context, name string, objectKey string, pages int32, size int64, language string, mimeType string, extractor string) (database.Record, error) {
+	queries := getQueries(ctx)
 
 	return queries.CreateRecord(ctx, database.CreateRecordParams{
-		Name:       name,
-		Numofpages: pages,
-		Size:       size,
+		Name:             name,
+		Objectkey:        objectKey,
+		Numofpages:       pages,
+		Size:             size,
+		Language:         language,
+		Mimetype:         mimeType,
+		Extractor:        extractor,
+		Extractorversion: ExtractionVersion,
 	})
 }
 
 func GetRecord(ctx context.Context, id int32) (database.Record, error) {
-	conn, queries, err := getQueriesConnection(ctx)
-	if err != nil {
-		return database.Record{}, err
-	}
-	defer conn.Close(ctx)
+	queries := getQueries(ctx)
 
 	return queries.GetRecord(ctx, id)
 }
 
 func ListRecords(ctx context.Context) ([]database.Record, error) {
-	conn, queries, err := getQueriesConnection(ctx)
-	if err != nil {
-		return []database.Record{}, err
-	}
-	defer conn.Close(ctx)
+	queries := getQueries(ctx)
 
 	return queries.ListRecords(ctx)
 }
 
 func ListRecordSentences(ctx context.Context, id int32) ([]database.Sentence, error) {
-	conn, queries, err := getQueriesConnection(ctx)
-	if err != nil {
-		return []database.Sentence{}, err
-	}
-	defer conn.Close(ctx)
+	queries := getQueries(ctx)
 
 	return queries.ListRecordSentences(ctx, id)
 }
 
-func deleteRecord(ctx context.Context, name string) error {
-	conn, queries, err := getQueriesConnection(ctx)
-	if err != nil {
-		return err
-	}
-	defer conn.Close(ctx)
+// deleteRecord deletes a record by its id, the names of the records are not unique
+func deleteRecord(ctx context.Context, id int32) error {
+	queries := getQueries(ctx)
 
-	err = queries.DeleteRecord(ctx, name)
+	err := queries.DeleteRecordByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -134,155 +137,155 @@ func deleteRecord(ctx context.Context, name string) error {
 	return nil
 }
 
-func SplitAndStore(ctx context.Context, text string, insertedRecord database.Record) error {
+// SplitAndStore splits every page into sentences and stores them with their page number
+func SplitAndStore(ctx context.Context, pages []string, insertedRecord database.Record) ([]database.Sentence, error) {
 	//Sentence package initialization.
-	doc, err := prose.NewDocument(text)
-	if err != nil {
-		return err
+	observeSplit := metrics.ObserveStage(metrics.StageSplit)
+	_, span := tracing.Start(ctx, "prose.split", attribute.Int("pages", len(pages)))
+	pageSentences := make([][]prose.Sentence, len(pages))
+	count := 0
+	for i, text := range pages {
+		doc, err := prose.NewDocument(text, prose.WithTagging(false), prose.WithExtraction(false))
+		if err != nil {
+			tracing.End(span, err)
+			return nil, err
+		}
+		pageSentences[i] = doc.Sentences()
+		count += len(pageSentences[i])
 	}
+	span.SetAttributes(attribute.Int("sentences", count))
+	tracing.End(span, nil)
+	observeSplit()
 
-	conn, queries, err := getQueriesConnection(ctx)
-	if err != nil {
-		return err
-	}
-	defer conn.Close(ctx)
+	defer metrics.ObserveStage(metrics.StageStore)()
+	queries := getQueries(ctx)
 
 	//Store sentences in database.
-	for _, sentence := range doc.Sentences() {
-		_, err := queries.CreateSentence(ctx, database.CreateSentenceParams{
-			Sentence: sentence.Text,
-			Pdfid:    insertedRecord.ID,
-		})
-		if err != nil {
-			return err
+	stored := make([]database.Sentence, 0, count)
+	for i, sentences := range pageSentences {
+		for _, sentence := range sentences {
+			created, err := queries.CreateSentence(ctx, database.CreateSentenceParams{
+				Sentence: sentence.Text,
+				Pdfid:    insertedRecord.ID,
+				Page:     int32(i + 1),
+			})
+			if err != nil {
+				return nil, err
+			}
+			stored = append(stored, created)
+			metrics.SentencesProcessed.Inc()
 		}
 	}
-	return nil
+	return stored, nil
 }
 
-func SendErrorStatus(c *fiber.Ctx, err string) error {
+// SendErrorStatus logs the internal error and answers with a stable error code
+func SendErrorStatus(c *fiber.Ctx, code string, message string, err error) error {
+	ctx := c.UserContext()
+	logging.FromContext(ctx).WithError(err).WithField("code", code).Error(message)
+
 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		"error": err,
+		"error":      message,
+		"code":       code,
+		"request_id": logging.RequestID(ctx),
 	})
 }
 
-func SendBadRequestStatus(c *fiber.Ctx, err string) error {
-	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-		"error": err,
-	})
+func SendBadRequestStatus(c *fiber.Ctx, code string, message string) error {
+	return SendClientErrorStatus(c, fiber.StatusBadRequest, code, message)
 }
 
-func ReadPdf(path string) (*pdf.Reader, string, error) {
-	reader, err := pdf.Open(path)
-	if err != nil {
-		return nil, "", err
-	}
-
-	cmd := exec.Command("pdftotext", path, "-")
+// SendClientErrorStatus answers a request that cannot be served as sent, with a 4xx status
+func SendClientErrorStatus(c *fiber.Ctx, status int, code string, message string) error {
+	ctx := c.UserContext()
+	logging.FromContext(ctx).WithField("code", code).Info(message)
 
-	var out bytes.Buffer
-	cmd.Stdout = &out
+	return c.Status(status).JSON(fiber.Map{
+		"error":      message,
+		"code":       code,
+		"request_id": logging.RequestID(ctx),
+	})
+}
 
-	err = cmd.Run()
+// DetectFormat returns the MIME type of a file sniffed from its content. The formats without an
+// extractor return an error matching extract.ErrUnsupportedFormat.
+func DetectFormat(path string) (string, error) {
+	mimeType, err := extract.Sniff(path)
 	if err != nil {
-		return nil, "", err
+		return "", err
 	}
-
-	textContent := out.String()
-	textContent = strings.Replace(textContent, "\n", " ", -1)
-	return reader, textContent, nil
-}
-
-func CountFilteredWords(text string) map[string]int {
-	reg := regexp.MustCompile("[^a-zA-Z.\\s-_/]+")
-	pointless := reg.ReplaceAllString(text, " ")
-	reg = regexp.MustCompile(`\b\w\b`)
-	pointless = reg.ReplaceAllString(pointless, "")
-	clean := stopwords.CleanString(pointless, "en", false)
-	words := strings.Split(clean, " ")
-	filteredWords := map[string]int{}
-
-	for _, word := range words {
-		if strings.Contains(word, " ") {
-			for key, value := range CountFilteredWords(word) {
-				val := filteredWords[key]
-				filteredWords[key] = value + val
-			}
-			continue
-		}
-		if word == "" {
-			continue
-		}
-
-		count := filteredWords[word]
-		count++
-		filteredWords[word] = count
+	if _, err := formats.Lookup(mimeType); err != nil {
+		return mimeType, err
 	}
-
-	return filteredWords
+	return mimeType, nil
 }
 
-func ExtractFrequency(entry string) int {
-	if entry == "" {
-		return 0
+// ReadDocument extracts the text of every page of a file with the extractor of its format
+func ReadDocument(ctx context.Context, path string, mimeType string) (*extract.Document, error) {
+	extractor, err := formats.Lookup(mimeType)
+	if err != nil {
+		return nil, err
 	}
+	defer metrics.ObserveStage(metrics.StageExtract)()
 
-	freq, err := strconv.Atoi(strings.Split(entry, " ")[1])
+	ctx, span := tracing.Start(ctx, "extract", attribute.String("extractor", extractor.Name()), attribute.String("format", mimeType))
+	document, err := extractor.Extract(ctx, path)
 	if err != nil {
-		log.Fatalln(err)
+		tracing.End(span, err)
+		return nil, err
 	}
-	return freq
-}
+	span.SetAttributes(attribute.String("extractor.used", document.Extractor), attribute.Int("pages", len(document.Pages)))
+	tracing.End(span, nil)
+
+	metrics.PagesProcessed.Add(float64(len(document.Pages)))
 
-func ShiftAndUpdateTopFive(index int, key string, value int, topFive *map[int]string) {
-	for i := 5; i > index; i-- {
-		(*topFive)[i] = (*topFive)[i-1]
+	for i, page := range document.Pages {
+		document.Pages[i] = strings.Replace(page, "\n", " ", -1)
 	}
-	(*topFive)[index] = fmt.Sprintf("%s: %d times", key, value)
+	return document, nil
 }
 
-func CopyPDF(id int) (string, error) {
-	ctx := context.Background()
+func CopyPDF(ctx context.Context, id int) (string, error) {
 	record, err := GetRecord(ctx, int32(id))
 	if err != nil {
 		return "A file with the id provided does not exist", err
 	}
+	return copyRecord(ctx, record)
+}
 
-	// Initialize MinIO client
-	minioClient, err := getMinioClient()
-	if err != nil {
-		return "Failed to initialize minio client", err
-	}
-
+// copyRecord downloads the stored file of a record to a local file the caller must remove, it
+// returns what failed with the error
+func copyRecord(ctx context.Context, record database.Record) (string, error) {
 	//download file from MinIO
 	bucketName := "pdf"
-	objectName := record.Name
+	objectName := record.Objectkey
 
-	file, err := minioClient.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
+	file, err := store.Get(ctx, bucketName, objectName)
 	if err != nil {
 		return "Failed to get the file from MinIO", err
 	}
 	defer file.Close()
 
-	localFile, err := os.Create(record.Name)
+	// the storage key says nothing of the format, MuPDF tells it from the extension
+	localFile, err := os.CreateTemp("", "document-*"+extract.Extension(record.Mimetype))
 	if err != nil {
 		return "Failed to create a temporary copy of the file", err
 	}
 	defer localFile.Close()
 
-	buffer := make([]byte, 1024)
-	for {
-		n, err := file.Read(buffer)
-		if err != nil {
-			break
-		}
-		localFile.Write(buffer[:n])
+	_, err = io.Copy(localFile, file)
+	if err != nil {
+		os.Remove(localFile.Name())
+		return "Failed to create a temporary copy of the file", err
 	}
 
 	return localFile.Name(), nil
 }
 
-func ConvertPDFPageToImage(pdfPath, imagePath string, pageNum int) error {
+func ConvertPDFPageToImage(ctx context.Context, pdfPath, imagePath string, pageNum int) (err error) {
+	defer metrics.ObserveStage(metrics.StageRender)()
+	_, span := tracing.Start(ctx, "fitz.render", attribute.Int("page", pageNum))
+	defer func() { tracing.End(span, err) }()
 
 	doc, err := fitz.New(pdfPath)
 	if err != nil {
@@ -310,27 +313,42 @@ func ConvertPDFPageToImage(pdfPath, imagePath string, pageNum int) error {
 	return nil
 }
 
-func DeletePDF(ctx context.Context, name string) error {
-	minioClient, err := getMinioClient()
+// DeletePDF removes the stored file of a record and the record. The records uploaded before the
+// keys were generated share the object of their name, it is kept until the last of them is deleted.
+func DeletePDF(ctx context.Context, record database.Record) error {
+	key := record.Objectkey
+	shared, err := getQueries(ctx).CountObjectRecords(ctx, key)
 	if err != nil {
-		return fmt.Errorf("minio")
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
 	}
 
-	file, err := minioClient.GetObject(ctx, "pdf", name, minio.GetObjectOptions{})
-	if err != nil {
-		return fmt.Errorf("minio")
-	}
-	defer file.Close()
+	removed := shared <= 1
+	var file io.ReadCloser
+	if removed {
+		file, err = store.Get(ctx, "pdf", key)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrStorage, err)
+		}
+		defer file.Close()
 
-	err = minioClient.RemoveObject(ctx, "pdf", name, minio.RemoveObjectOptions{})
-	if err != nil {
-		return fmt.Errorf("minio")
+		err = store.Remove(ctx, "pdf", key)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrStorage, err)
+		}
 	}
 
-	err = deleteRecord(ctx, name)
+	err = deleteRecord(ctx, record.ID)
+	InvalidateFrequencies(record.ID)
+	if err == nil {
+		RemoveSimilarities(record.ID)
+	}
 	if err != nil {
-		UploadPDF(ctx, "pdf", name, file)
-		return fmt.Errorf("db")
+		if removed {
+			if uploadErr := UploadDocument(ctx, "pdf", key, file, record.Mimetype); uploadErr != nil {
+				logging.FromContext(ctx).WithError(uploadErr).WithField("object", key).Error("failed to restore the object")
+			}
+		}
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
 	}
 
 	return nil